@@ -34,8 +34,8 @@ CLI (requires running server):
   pinchtab select <ref> <value>         Select dropdown option
   pinchtab focus <ref>                  Focus element
   pinchtab text [--raw]                 Extract readable text
-  pinchtab tabs [new <url>|close <id>]  Manage tabs
-  pinchtab ss [-o file] [-q 80]         Screenshot
+  pinchtab tabs [new|close|activate]    Manage tabs
+  pinchtab ss [-o file] [-q 80] [-a]    Screenshot (-a: label snapshot refs)
   pinchtab eval <expression>            Run JavaScript
   pinchtab pdf [-o file] [--landscape]  Export page as PDF
   pinchtab health                       Check server status
@@ -142,9 +142,12 @@ Commands:
   tabs                    List open tabs
   tabs new <url>          Open new tab
   tabs close <tabId>      Close tab
-  ss, screenshot          Take screenshot (-o file, -q quality)
+  tabs activate <tabId>   Focus tab and make it the default for later commands
+  ss, screenshot          Take screenshot (-o file, -q quality, --annotate)
   eval <expression>       Evaluate JavaScript
-  pdf                     Export page as PDF (-o file, --landscape, --scale N)
+  pdf                     Export page as PDF (-o file, --landscape, --scale N,
+                          --paper A4, --margin IN, --pages 1-3, --header/--footer HTML,
+                          --css-page-size, --tagged, --outline)
   health                  Server health check
   help                    Show this help
 
@@ -312,8 +315,13 @@ func cliTabs(client *http.Client, base, token string, args []string) {
 			"action": "close",
 			"tabId":  args[1],
 		})
+	case "activate":
+		if len(args) < 2 {
+			fatal("Usage: pinchtab tabs activate <tabId>")
+		}
+		doPost(client, base, token, "/tab/activate", map[string]any{"tabId": args[1]})
 	default:
-		fatal("Usage: pinchtab tabs [new <url>|close <tabId>]")
+		fatal("Usage: pinchtab tabs [new <url>|close <tabId>|activate <tabId>]")
 	}
 }
 
@@ -335,6 +343,8 @@ func cliScreenshot(client *http.Client, base, token string, args []string) {
 				i++
 				params.Set("quality", args[i])
 			}
+		case "--annotate", "-a":
+			params.Set("annotate", "true")
 		case "--tab":
 			if i+1 < len(args) {
 				i++
@@ -389,6 +399,47 @@ func cliPDF(client *http.Client, base, token string, args []string) {
 				i++
 				params.Set("scale", args[i])
 			}
+		case "--paper":
+			if i+1 < len(args) {
+				i++
+				params.Set("paperFormat", args[i])
+			}
+		case "--paper-width":
+			if i+1 < len(args) {
+				i++
+				params.Set("paperWidth", args[i])
+			}
+		case "--paper-height":
+			if i+1 < len(args) {
+				i++
+				params.Set("paperHeight", args[i])
+			}
+		case "--margin":
+			if i+1 < len(args) {
+				i++
+				params.Set("margin", args[i])
+			}
+		case "--pages":
+			if i+1 < len(args) {
+				i++
+				params.Set("pageRanges", args[i])
+			}
+		case "--header":
+			if i+1 < len(args) {
+				i++
+				params.Set("headerTemplate", args[i])
+			}
+		case "--footer":
+			if i+1 < len(args) {
+				i++
+				params.Set("footerTemplate", args[i])
+			}
+		case "--css-page-size":
+			params.Set("preferCSSPageSize", "true")
+		case "--tagged":
+			params.Set("generateTaggedPDF", "true")
+		case "--outline":
+			params.Set("generateDocumentOutline", "true")
 		case "--tab":
 			if i+1 < len(args) {
 				i++
@@ -353,6 +353,22 @@ func TestCLITabsNew(t *testing.T) {
 	}
 }
 
+func TestCLITabsActivate(t *testing.T) {
+	m := newMockServer()
+	defer m.close()
+	client := m.server.Client()
+
+	cliTabs(client, m.base(), "", []string{"activate", "ABC123"})
+	if m.lastPath != "/tab/activate" {
+		t.Errorf("expected /tab/activate, got %s", m.lastPath)
+	}
+	var body map[string]any
+	_ = json.Unmarshal([]byte(m.lastBody), &body)
+	if body["tabId"] != "ABC123" {
+		t.Errorf("expected tabId=ABC123, got %v", body["tabId"])
+	}
+}
+
 // --- evaluate tests ---
 
 func TestCLIEvaluate(t *testing.T) {
@@ -438,6 +454,26 @@ func TestCLIPDF(t *testing.T) {
 	}
 }
 
+func TestCLIPDF_PageOptions(t *testing.T) {
+	m := newMockServer()
+	m.response = "FAKEPDFDATA"
+	defer m.close()
+	client := m.server.Client()
+
+	outFile := t.TempDir() + "/test.pdf"
+	cliPDF(client, m.base(), "", []string{"-o", outFile,
+		"--paper", "A4", "--margin", "0.5", "--pages", "1-3",
+		"--footer", "<span class=pageNumber></span>", "--css-page-size", "--tagged", "--outline"})
+	for _, want := range []string{
+		"paperFormat=A4", "margin=0.5", "pageRanges=1-3", "footerTemplate=",
+		"preferCSSPageSize=true", "generateTaggedPDF=true", "generateDocumentOutline=true",
+	} {
+		if !strings.Contains(m.lastQuery, want) {
+			t.Errorf("expected %s in query, got %s", want, m.lastQuery)
+		}
+	}
+}
+
 // --- health tests ---
 
 func TestCLIHealth(t *testing.T) {
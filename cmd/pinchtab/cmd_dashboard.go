@@ -42,6 +42,8 @@ func runDashboard(cfg *config.RuntimeConfig) {
 	dash := dashboard.NewDashboard(nil)
 	orch := orchestrator.NewOrchestrator(profilesDir)
 	orch.SetProfileManager(profMgr)
+	orch.SetShutdownTimeout(cfg.ShutdownTimeout)
+	orch.SetAuthToken(cfg.Token)
 	dash.SetInstanceLister(orch)
 
 	mux := http.NewServeMux()
@@ -50,16 +52,18 @@ func runDashboard(cfg *config.RuntimeConfig) {
 	orch.RegisterHandlers(mux)
 	profMgr.RegisterHandlers(mux)
 
+	mux.HandleFunc("GET /metrics", handlers.HandleDashboardMetrics(orch))
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		web.JSON(w, 200, map[string]string{"status": "ok", "mode": "dashboard"})
 	})
 
 	proxyEndpoints := []string{
-		"/tabs", "/snapshot", "/screenshot", "/text",
-		"/navigate", "/action", "/actions", "/evaluate",
-		"/tab", "/tab/lock", "/tab/unlock",
+		"/tabs", "/snapshot", "/screenshot", "/text", "/perf",
+		"/navigate", "/action", "/actions", "/evaluate", "/evaluate/release",
+		"/tab", "/tab/activate", "/tab/lock", "/tab/lock/renew", "/tab/unlock",
 		"/cookies", "/download", "/upload", "/stealth/status", "/fingerprint/rotate",
-		"/screencast", "/screencast/tabs",
+		"/screencast", "/screencast/tabs", "/recordings", "/handoff", "/scripts", "/sessions",
+		"/trace/start", "/trace/stop",
 	}
 	for _, ep := range proxyEndpoints {
 		endpoint := ep
@@ -72,6 +76,16 @@ func runDashboard(cfg *config.RuntimeConfig) {
 			proxyRequest(w, r, target+endpoint)
 		})
 	}
+	for _, prefix := range []string{"/recordings/", "/handoff/", "/scripts/", "/sessions/"} {
+		mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+			target := orch.FirstRunningURL()
+			if target == "" {
+				web.Error(w, 503, fmt.Errorf("no running instances — launch one from the Profiles tab"))
+				return
+			}
+			proxyRequest(w, r, target+r.URL.Path)
+		})
+	}
 
 	profileObserver := func(evt dashboard.AgentEvent) {
 		if evt.Profile != "" {
@@ -87,9 +101,10 @@ func runDashboard(cfg *config.RuntimeConfig) {
 		}
 	}
 
+	drain := &handlers.Drainer{RetryAfter: cfg.ShutdownTimeout}
 	handler := dash.TrackingMiddleware(
 		[]dashboard.EventObserver{profileObserver},
-		handlers.LoggingMiddleware(handlers.CorsMiddleware(handlers.AuthMiddleware(cfg, mux))),
+		handlers.LoggingMiddleware(handlers.DrainMiddleware(drain, handlers.CorsMiddleware(handlers.AuthMiddleware(cfg, mux)))),
 	)
 
 	srv := &http.Server{
@@ -97,6 +112,7 @@ func runDashboard(cfg *config.RuntimeConfig) {
 		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
+	srv.RegisterOnShutdown(dash.Shutdown)
 
 	autoLaunch := strings.EqualFold(os.Getenv("PINCHTAB_AUTO_LAUNCH"), "1") ||
 		strings.EqualFold(os.Getenv("PINCHTAB_AUTO_LAUNCH"), "true") ||
@@ -130,16 +146,19 @@ func runDashboard(cfg *config.RuntimeConfig) {
 	}
 
 	shutdownOnce := &sync.Once{}
+	shutdownDone := make(chan struct{})
 	doShutdown := func() {
 		shutdownOnce.Do(func() {
-			slog.Info("shutting down dashboard...")
-			dash.Shutdown()
-			orch.Shutdown()
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			defer cancel()
+			defer close(shutdownDone)
+			slog.Info("shutting down dashboard, draining requests...", "timeout", cfg.ShutdownTimeout)
+			drain.Start()
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 			if err := srv.Shutdown(ctx); err != nil {
-				slog.Error("shutdown http", "err", err)
+				slog.Warn("drain timed out, closing remaining requests", "err", err)
+				_ = srv.Close()
 			}
+			cancel()
+			orch.Shutdown()
 		})
 	}
 
@@ -165,6 +184,7 @@ func runDashboard(cfg *config.RuntimeConfig) {
 		slog.Error("server", "err", err)
 		os.Exit(1)
 	}
+	<-shutdownDone
 }
 
 // proxyRequest forwards an HTTP request to a target URL.
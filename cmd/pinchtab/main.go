@@ -121,7 +121,39 @@ func main() {
 	dash := dashboard.NewDashboard(nil)
 	orch := orchestrator.NewOrchestrator(profilesDir)
 	orch.SetProfileManager(profMgr)
+	orch.SetShutdownTimeout(cfg.ShutdownTimeout)
+	orch.SetAuthToken(cfg.Token)
 	dash.SetInstanceLister(orch)
+	dash.SetDisconnectHandler(func(agentID string) { b.ReleaseAgent(agentID) })
+	b.SetEvictionHandler(func(e bridge.TabEviction) {
+		agent := e.Owner
+		if agent == "" {
+			agent = "pinchtab"
+		}
+		dash.BroadcastEvent(dashboard.AgentEvent{
+			AgentID:   agent,
+			Action:    "evict tab",
+			URL:       e.URL,
+			TabID:     e.TabID,
+			Detail:    e.Reason,
+			Timestamp: time.Now(),
+		})
+	})
+
+	b.SetCrashHandler(func(c bridge.TabCrash) {
+		agent := b.TabOwner(c.TabID)
+		if agent == "" {
+			agent = "pinchtab"
+		}
+		dash.BroadcastEvent(dashboard.AgentEvent{
+			AgentID:   agent,
+			Action:    "crash tab",
+			URL:       c.URL,
+			TabID:     c.TabID,
+			Detail:    c.Reason,
+			Timestamp: c.At,
+		})
+	})
 
 	// For CDP_URL mode, the initial target might not exist yet.
 	// Tabs will be registered when they're created or discovered.
@@ -133,6 +165,9 @@ func main() {
 		slog.Info("CDP_URL mode: skipping initial tab registration")
 	}
 
+	b.WatchPopups()
+	b.WatchCrashes()
+
 	if !cfg.Headless {
 		go func() {
 			time.Sleep(200 * time.Millisecond)
@@ -148,20 +183,55 @@ func main() {
 	defer cleanupCancel()
 	go b.CleanStaleTabs(cleanupCtx, 30*cfg.ActionTimeout)
 
+	sup := newChromeSupervisor(cfg, b, seededScript, allocCancel, browserCancel)
+	go sup.run(cleanupCtx)
+	if cfg.TabIdleTimeout > 0 {
+		go b.CloseIdleTabs(cleanupCtx, cfg.TabIdleTimeout)
+	}
+	if cfg.TabHangTimeout > 0 {
+		go b.WatchHangs(cleanupCtx, cfg.TabHangTimeout)
+	}
+	if cfg.SessionAutosave > 0 {
+		go b.AutosaveSessions(cleanupCtx, cfg.SessionAutosave)
+	}
+
 	mux := http.NewServeMux()
 	h := handlers.New(b, cfg, profMgr, dash, orch)
 
+	drain := &handlers.Drainer{RetryAfter: cfg.ShutdownTimeout}
+	srv := &http.Server{
+		Addr:              cfg.ListenAddr(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// The dashboard event stream never ends on its own; close it so it
+	// doesn't hold up the drain.
+	srv.RegisterOnShutdown(dash.Shutdown)
+
 	shutdownOnce := &sync.Once{}
+	shutdownDone := make(chan struct{})
 	doShutdown := func() {
 		shutdownOnce.Do(func() {
-			slog.Info("shutting down, saving state...")
+			defer close(shutdownDone)
+			slog.Info("shutting down, draining requests...", "timeout", cfg.ShutdownTimeout)
+			drain.Start()
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+			if err := srv.Shutdown(ctx); err != nil {
+				slog.Warn("drain timed out, closing remaining requests", "err", err)
+				_ = srv.Close()
+			}
+			cancel()
+
+			// Recordings are finalized while Chrome is still up, so their
+			// files get an index and metadata.
+			h.StopRecordings()
+
+			slog.Info("saving state...")
 			orch.Shutdown()
 			cleanupCancel()
 			b.SaveState()
 			bridge.MarkCleanExit(cfg.ProfileDir)
 
-			browserCancel()
-			allocCancel()
+			sup.stop()
 			slog.Info("chrome closed")
 		})
 	}
@@ -182,22 +252,15 @@ func main() {
 		}
 	}
 
-	handler := dash.TrackingMiddleware(
+	srv.Handler = dash.TrackingMiddleware(
 		[]dashboard.EventObserver{profileObserver},
-		handlers.LoggingMiddleware(handlers.CorsMiddleware(handlers.AuthMiddleware(cfg, mux))),
+		handlers.LoggingMiddleware(handlers.DrainMiddleware(drain, handlers.CorsMiddleware(handlers.AuthMiddleware(cfg, mux)))),
 	)
 
-	srv := &http.Server{
-		Addr:              cfg.ListenAddr(),
-		Handler:           handler,
-		ReadHeaderTimeout: 10 * time.Second,
-	}
-
 	setupSignalHandler(doShutdown, func() {
 		orch.ForceShutdown()
 		cleanupCancel()
-		browserCancel()
-		allocCancel()
+		sup.stop()
 	})
 
 	slog.Info("🦀 PINCH! PINCH!", "port", cfg.Port, "cdp", cfg.CdpURL, "stealth", cfg.StealthLevel)
@@ -213,6 +276,8 @@ func main() {
 		slog.Error("server", "err", err)
 		os.Exit(1)
 	}
+	// ListenAndServe returns as soon as the drain starts.
+	<-shutdownDone
 }
 
 func setupSignalHandler(shutdownFn func(), forceFn func()) {
@@ -231,7 +296,7 @@ func setupSignalHandler(shutdownFn func(), forceFn func()) {
 func runStartupHealthCheck(cfg *config.RuntimeConfig) {
 	time.Sleep(500 * time.Millisecond)
 	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(fmt.Sprintf("http://localhost:%s/health", cfg.Port))
+	resp, err := client.Get(fmt.Sprintf("http://localhost:%s/health/ready", cfg.Port))
 	if err != nil {
 		slog.Error("startup health check failed",
 			"err", err,
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+const (
+	// tabSnapshotInterval is how often open tabs are recorded for recovery.
+	tabSnapshotInterval = 5 * time.Second
+	maxRelaunchBackoff  = 30 * time.Second
+)
+
+// chromeSupervisor relaunches Chrome when the browser connection is lost and
+// moves the bridge, with its tabs, onto the new browser.
+type chromeSupervisor struct {
+	cfg          *config.RuntimeConfig
+	b            *bridge.Bridge
+	seededScript string
+
+	mu            sync.Mutex
+	allocCancel   context.CancelFunc
+	browserCancel context.CancelFunc
+	stopping      bool
+}
+
+func newChromeSupervisor(cfg *config.RuntimeConfig, b *bridge.Bridge, seededScript string, allocCancel, browserCancel context.CancelFunc) *chromeSupervisor {
+	return &chromeSupervisor{
+		cfg:           cfg,
+		b:             b,
+		seededScript:  seededScript,
+		allocCancel:   allocCancel,
+		browserCancel: browserCancel,
+	}
+}
+
+func (s *chromeSupervisor) run(ctx context.Context) {
+	ticker := time.NewTicker(tabSnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.b.RememberTabs()
+		case <-s.b.BrowserContext().Done():
+			if s.isStopping() {
+				return
+			}
+			s.recover(ctx)
+		}
+	}
+}
+
+// stop cancels the current browser without triggering a relaunch.
+func (s *chromeSupervisor) stop() {
+	s.mu.Lock()
+	s.stopping = true
+	browserCancel, allocCancel := s.browserCancel, s.allocCancel
+	s.mu.Unlock()
+	browserCancel()
+	allocCancel()
+}
+
+func (s *chromeSupervisor) isStopping() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopping
+}
+
+func (s *chromeSupervisor) recover(ctx context.Context) {
+	slog.Error("Chrome connection lost, relaunching", "profile", s.cfg.ProfileDir, "cdp", s.cfg.CdpURL)
+	s.b.BeginRecovery(errors.New("browser connection lost"))
+
+	backoff := time.Second
+	for {
+		err := s.relaunch()
+		if err == nil {
+			return
+		}
+		slog.Error("Chrome relaunch failed", "err", err, "retryIn", backoff)
+		s.b.RecoveryFailed(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if s.isStopping() {
+			return
+		}
+		backoff = min(backoff*2, maxRelaunchBackoff)
+	}
+}
+
+func (s *chromeSupervisor) relaunch() error {
+	s.mu.Lock()
+	s.browserCancel()
+	s.allocCancel()
+	s.mu.Unlock()
+
+	allocCtx, allocCancel, _ := setupAllocator(s.cfg)
+	browserCtx, browserCancel, err := startChrome(allocCtx, s.cfg, s.seededScript)
+	if err != nil {
+		allocCancel()
+		return err
+	}
+	if s.cfg.CdpURL != "" {
+		// startChrome doesn't connect to a remote browser; make sure it's back.
+		if err := chromedp.Run(browserCtx); err != nil {
+			browserCancel()
+			allocCancel()
+			return fmt.Errorf("connect %s: %w", s.cfg.CdpURL, err)
+		}
+	}
+
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		browserCancel()
+		allocCancel()
+		return nil
+	}
+	s.allocCancel, s.browserCancel = allocCancel, browserCancel
+	s.mu.Unlock()
+
+	applyTimezone(browserCtx, s.cfg)
+	applyUserAgentOverride(browserCtx, s.cfg)
+
+	firstTab := ""
+	if s.cfg.CdpURL == "" {
+		firstTab = string(chromedp.FromContext(browserCtx).Target.TargetID)
+	}
+	ids := s.b.Recover(allocCtx, browserCtx, firstTab)
+	slog.Info("Chrome recovered", "tabs", len(ids))
+	return nil
+}
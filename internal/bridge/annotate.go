@@ -0,0 +1,180 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"sort"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/chromedp"
+)
+
+// Box is an element bounding box in screenshot pixel coordinates.
+type Box struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// NodeBox returns the border box of a backend DOM node in CSS pixels,
+// relative to the viewport.
+func NodeBox(ctx context.Context, backendNodeID int64) (Box, error) {
+	var model *dom.BoxModel
+	if err := chromedp.Run(ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			model, err = dom.GetBoxModel().WithBackendNodeID(cdp.BackendNodeID(backendNodeID)).Do(ctx)
+			return err
+		}),
+	); err != nil {
+		return Box{}, err
+	}
+	if model == nil || len(model.Border) < 8 {
+		return Box{}, fmt.Errorf("node %d has no box", backendNodeID)
+	}
+
+	q := model.Border
+	minX, maxX := q[0], q[0]
+	minY, maxY := q[1], q[1]
+	for i := 2; i < len(q); i += 2 {
+		minX = min(minX, q[i])
+		maxX = max(maxX, q[i])
+		minY = min(minY, q[i+1])
+		maxY = max(maxY, q[i+1])
+	}
+	return Box{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}, nil
+}
+
+// markColors cycles so neighbouring boxes stay distinguishable.
+var markColors = []color.RGBA{
+	{230, 25, 75, 255},
+	{60, 180, 75, 255},
+	{0, 130, 200, 255},
+	{245, 130, 48, 255},
+	{145, 30, 180, 255},
+	{240, 50, 230, 255},
+	{0, 128, 128, 255},
+	{170, 110, 40, 255},
+}
+
+// AnnotateImage draws a labelled rectangle for every box onto a JPEG image
+// and returns the re-encoded JPEG. Labels are the map keys (snapshot refs).
+func AnnotateImage(src []byte, boxes map[string]Box, quality int) ([]byte, error) {
+	decoded, err := jpeg.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	img := image.NewRGBA(decoded.Bounds())
+	draw.Draw(img, img.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+
+	refs := make([]string, 0, len(boxes))
+	for ref := range boxes {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refOrder(refs[i]) < refOrder(refs[j]) })
+
+	for i, ref := range refs {
+		b := boxes[ref]
+		c := markColors[i%len(markColors)]
+		r := image.Rect(int(b.X), int(b.Y), int(b.X+b.Width), int(b.Y+b.Height)).Intersect(img.Bounds())
+		if r.Empty() {
+			continue
+		}
+		strokeRect(img, r, c, 2)
+		drawLabel(img, r.Min, ref, c)
+	}
+
+	if quality <= 0 || quality > 100 {
+		quality = 80
+	}
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// refOrder sorts "e2" before "e10"; unknown refs sort last.
+func refOrder(ref string) int {
+	var n int
+	if _, err := fmt.Sscanf(ref, "e%d", &n); err != nil {
+		return 1 << 30
+	}
+	return n
+}
+
+func strokeRect(img *image.RGBA, r image.Rectangle, c color.RGBA, width int) {
+	u := image.NewUniform(c)
+	edges := []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+width),
+		image.Rect(r.Min.X, r.Max.Y-width, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y, r.Min.X+width, r.Max.Y),
+		image.Rect(r.Max.X-width, r.Min.Y, r.Max.X, r.Max.Y),
+	}
+	for _, e := range edges {
+		draw.Draw(img, e.Intersect(r), u, image.Point{}, draw.Over)
+	}
+}
+
+const (
+	glyphW     = 5
+	glyphH     = 7
+	glyphScale = 2
+	labelPad   = 2
+)
+
+// glyphs is a minimal 5x7 bitmap font covering the characters used in refs.
+var glyphs = map[rune][glyphH]uint8{
+	'0': {0x0E, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0E},
+	'1': {0x04, 0x0C, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'2': {0x0E, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1F},
+	'3': {0x1F, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0E},
+	'4': {0x02, 0x06, 0x0A, 0x12, 0x1F, 0x02, 0x02},
+	'5': {0x1F, 0x10, 0x1E, 0x01, 0x01, 0x11, 0x0E},
+	'6': {0x06, 0x08, 0x10, 0x1E, 0x11, 0x11, 0x0E},
+	'7': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8': {0x0E, 0x11, 0x11, 0x0E, 0x11, 0x11, 0x0E},
+	'9': {0x0E, 0x11, 0x11, 0x0F, 0x01, 0x02, 0x0C},
+	'e': {0x00, 0x00, 0x0E, 0x11, 0x1F, 0x10, 0x0E},
+}
+
+// drawLabel paints text on a filled tag anchored at the top-left corner of a
+// box, flipped inside the box when there is no room above it.
+func drawLabel(img *image.RGBA, at image.Point, text string, bg color.RGBA) {
+	advance := (glyphW + 1) * glyphScale
+	w := len(text)*advance - glyphScale + 2*labelPad
+	h := glyphH*glyphScale + 2*labelPad
+
+	origin := image.Pt(at.X, at.Y-h)
+	if origin.Y < img.Bounds().Min.Y {
+		origin.Y = at.Y
+	}
+	tag := image.Rect(origin.X, origin.Y, origin.X+w, origin.Y+h).Intersect(img.Bounds())
+	draw.Draw(img, tag, image.NewUniform(bg), image.Point{}, draw.Src)
+
+	fg := color.RGBA{255, 255, 255, 255}
+	x := origin.X + labelPad
+	for _, ch := range text {
+		g, ok := glyphs[ch]
+		if ok {
+			for row := 0; row < glyphH; row++ {
+				for col := 0; col < glyphW; col++ {
+					if g[row]&(1<<(glyphW-1-col)) == 0 {
+						continue
+					}
+					px := image.Rect(0, 0, glyphScale, glyphScale).
+						Add(image.Pt(x+col*glyphScale, origin.Y+labelPad+row*glyphScale))
+					draw.Draw(img, px.Intersect(tag), image.NewUniform(fg), image.Point{}, draw.Src)
+				}
+			}
+		}
+		x += advance
+	}
+}
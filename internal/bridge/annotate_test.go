@@ -0,0 +1,73 @@
+package bridge
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"testing"
+)
+
+func whiteJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAnnotateImage(t *testing.T) {
+	src := whiteJPEG(t, 200, 150)
+	boxes := map[string]Box{
+		"e0": {X: 40, Y: 40, Width: 100, Height: 60},
+		"e1": {X: 500, Y: 500, Width: 10, Height: 10}, // off-image, skipped
+	}
+
+	out, err := AnnotateImage(src, boxes, 95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("output is not a JPEG: %v", err)
+	}
+	if img.Bounds().Dx() != 200 || img.Bounds().Dy() != 150 {
+		t.Fatalf("size changed: %v", img.Bounds())
+	}
+
+	r, g, b, _ := img.At(90, 40).RGBA()
+	if r>>8 > 240 && g>>8 > 240 && b>>8 > 240 {
+		t.Error("expected box border to be drawn")
+	}
+	r, g, b, _ = img.At(90, 70).RGBA()
+	if r>>8 < 200 || g>>8 < 200 || b>>8 < 200 {
+		t.Error("expected box interior to be untouched")
+	}
+}
+
+func TestAnnotateImage_InvalidInput(t *testing.T) {
+	if _, err := AnnotateImage([]byte("not a jpeg"), nil, 80); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func TestGlyphsCoverRefs(t *testing.T) {
+	for _, ch := range "e0123456789" {
+		if _, ok := glyphs[ch]; !ok {
+			t.Errorf("missing glyph %q", ch)
+		}
+	}
+}
+
+func TestRefOrder(t *testing.T) {
+	if refOrder("e2") >= refOrder("e10") {
+		t.Error("expected e2 before e10")
+	}
+	if refOrder("x") <= refOrder("e999") {
+		t.Error("expected unknown refs last")
+	}
+}
@@ -13,8 +13,22 @@ type BridgeAPI interface {
 	BrowserContext() context.Context
 	TabContext(tabID string) (ctx context.Context, resolvedID string, err error)
 	ListTargets() ([]*target.Info, error)
-	CreateTab(url string) (tabID string, ctx context.Context, cancel context.CancelFunc, err error)
+	CreateTab(url, owner string) (tabID string, ctx context.Context, cancel context.CancelFunc, err error)
 	CloseTab(tabID string) error
+	AcquireTab(ctx context.Context, tabID string) (release func(), err error)
+	TabQueueDepth(tabID string) int
+	MapTabID(tabID string) string
+	RecoveryStatus() RecoveryStatus
+	BrowserInfo(ctx context.Context) (BrowserInfo, error)
+	SetTabOwner(tabID, owner string)
+	TabOwner(tabID string) string
+	SetCurrentTab(agentID, tabID string)
+	CurrentTab(agentID string) string
+	ActivateTab(tabID string) error
+	TabCrash(tabID string) *TabCrash
+	ClearTabCrash(tabID string)
+	PopupsSince(openerID string, since time.Time) []PopupInfo
+	WaitForPopup(ctx context.Context, openerID string, since time.Time) (PopupInfo, error)
 
 	GetRefCache(tabID string) *RefCache
 	SetRefCache(tabID string, cache *RefCache)
@@ -23,14 +37,27 @@ type BridgeAPI interface {
 	ExecuteAction(ctx context.Context, kind string, req ActionRequest) (map[string]any, error)
 	AvailableActions() []string
 
+	SaveSession(name string, tabIDs []string, opts SessionOptions) (SessionState, error)
+	LoadSession(name string) (SessionState, error)
+	ListSessions() ([]SessionInfo, error)
+	DeleteSession(name string) error
+	RestoreSession(name string) ([]RestoredTab, error)
+
+	UserScripts() []UserScript
+	AddUserScript(s UserScript) (UserScript, error)
+	RemoveUserScript(name string) error
+
 	TabLockInfo(tabID string) *LockInfo
 	Lock(tabID, owner string, ttl time.Duration) error
+	LockWait(ctx context.Context, tabID, owner string, ttl time.Duration) error
+	RenewLock(tabID, owner string, ttl time.Duration) (time.Time, error)
 	Unlock(tabID, owner string) error
 }
 
 type LockInfo struct {
 	Owner     string
 	ExpiresAt time.Time
+	Waiters   int // callers queued for the lock
 }
 
 // ProfileService abstracts profile management operations.
@@ -57,6 +84,7 @@ type OrchestratorService interface {
 	FirstRunningURL() string
 	AllTabs() []InstanceTab
 	ScreencastURL(instanceID, tabID string) string
+	InstanceMetrics(ctx context.Context) map[string][]byte
 	Shutdown()
 	ForceShutdown()
 }
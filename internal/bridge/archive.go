@@ -0,0 +1,201 @@
+package bridge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// MHTMLPart is a single resource inside an MHTML archive.
+type MHTMLPart struct {
+	ContentType string
+	Location    string
+	ContentID   string
+	Body        []byte
+}
+
+// MHTMLArchive is a parsed MHTML document: the root HTML page plus the
+// resources Chrome captured with it.
+type MHTMLArchive struct {
+	Main  *MHTMLPart
+	Parts []*MHTMLPart
+
+	byLocation map[string]*MHTMLPart
+	byID       map[string]*MHTMLPart
+}
+
+// ParseMHTML parses the multipart/related output of Page.captureSnapshot.
+func ParseMHTML(data []byte) (*MHTMLArchive, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("read mhtml headers: %w", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("mhtml content type: %w", err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return nil, fmt.Errorf("mhtml is not multipart (%s)", mediaType)
+	}
+
+	a := &MHTMLArchive{
+		byLocation: make(map[string]*MHTMLPart),
+		byID:       make(map[string]*MHTMLPart),
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read mhtml part: %w", err)
+		}
+		// multipart.Reader decodes quoted-printable itself; base64 is left to us.
+		var body io.Reader = p
+		if strings.EqualFold(p.Header.Get("Content-Transfer-Encoding"), "base64") {
+			body = base64.NewDecoder(base64.StdEncoding, p)
+		}
+		raw, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("decode mhtml part: %w", err)
+		}
+
+		part := &MHTMLPart{
+			ContentType: p.Header.Get("Content-Type"),
+			Location:    p.Header.Get("Content-Location"),
+			ContentID:   strings.Trim(p.Header.Get("Content-ID"), "<>"),
+			Body:        raw,
+		}
+		a.Parts = append(a.Parts, part)
+		if part.Location != "" {
+			a.byLocation[part.Location] = part
+		}
+		if part.ContentID != "" {
+			a.byID[part.ContentID] = part
+		}
+		if a.Main == nil && strings.HasPrefix(part.ContentType, "text/html") {
+			a.Main = part
+		}
+	}
+	if a.Main == nil {
+		return nil, fmt.Errorf("mhtml has no html part")
+	}
+	return a, nil
+}
+
+// lookup finds the part a reference points at, resolving it against base.
+func (a *MHTMLArchive) lookup(ref, base string) *MHTMLPart {
+	ref = strings.TrimSpace(html.UnescapeString(ref))
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return nil
+	}
+	if id, ok := strings.CutPrefix(ref, "cid:"); ok {
+		return a.byID[id]
+	}
+	if p, ok := a.byLocation[ref]; ok {
+		return p
+	}
+	if b, err := url.Parse(base); err == nil {
+		if u, err := b.Parse(ref); err == nil {
+			return a.byLocation[u.String()]
+		}
+	}
+	return nil
+}
+
+func dataURI(p *MHTMLPart, body []byte) string {
+	ct := p.ContentType
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	return "data:" + ct + ";base64," + base64.StdEncoding.EncodeToString(body)
+}
+
+var (
+	linkTagRe   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	relStyleRe  = regexp.MustCompile(`(?i)\brel\s*=\s*["']?[^"'>]*stylesheet`)
+	hrefAttrRe  = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	srcAttrRe   = regexp.MustCompile(`(?i)(\s(?:src|poster)\s*=\s*)(?:"([^"]*)"|'([^']*)')`)
+	srcsetRe    = regexp.MustCompile(`(?i)\ssrcset\s*=\s*(?:"[^"]*"|'[^']*')`)
+	cssURLRe    = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^)"'\s]+))\s*\)`)
+	cssImportRe = regexp.MustCompile(`(?i)@import\s+(?:url\()?\s*["']?([^"')\s;]+)["']?\s*\)?[^;]*;`)
+)
+
+const maxInlineDepth = 3
+
+// InlineHTML renders the archive as a single self-contained HTML document:
+// stylesheets become <style> blocks, and images, fonts and frames referenced
+// by the page become data: URIs. srcset is dropped so browsers fall back to
+// the inlined src. Resources Chrome did not capture keep their original URLs.
+func (a *MHTMLArchive) InlineHTML() []byte {
+	return a.inlineDocument(a.Main, 0)
+}
+
+func (a *MHTMLArchive) inlineDocument(doc *MHTMLPart, depth int) []byte {
+	base := doc.Location
+	out := linkTagRe.ReplaceAllStringFunc(string(doc.Body), func(tag string) string {
+		if !relStyleRe.MatchString(tag) {
+			return tag
+		}
+		m := hrefAttrRe.FindStringSubmatch(tag)
+		if m == nil {
+			return tag
+		}
+		css := a.lookup(m[1]+m[2]+m[3], base)
+		if css == nil {
+			return tag
+		}
+		return "<style>" + a.inlineCSS(css, depth) + "</style>"
+	})
+
+	out = srcsetRe.ReplaceAllString(out, "")
+	out = srcAttrRe.ReplaceAllStringFunc(out, func(attr string) string {
+		m := srcAttrRe.FindStringSubmatch(attr)
+		p := a.lookup(m[2]+m[3], base)
+		if p == nil {
+			return attr
+		}
+		body := p.Body
+		if strings.HasPrefix(p.ContentType, "text/html") {
+			if depth >= maxInlineDepth {
+				return attr
+			}
+			body = a.inlineDocument(p, depth+1)
+		}
+		return m[1] + `"` + dataURI(p, body) + `"`
+	})
+
+	return []byte(a.inlineURLs(out, base))
+}
+
+func (a *MHTMLArchive) inlineCSS(css *MHTMLPart, depth int) string {
+	text := cssImportRe.ReplaceAllStringFunc(string(css.Body), func(rule string) string {
+		m := cssImportRe.FindStringSubmatch(rule)
+		imported := a.lookup(m[1], css.Location)
+		if imported == nil || depth >= maxInlineDepth {
+			return rule
+		}
+		return a.inlineCSS(imported, depth+1)
+	})
+	return a.inlineURLs(text, css.Location)
+}
+
+func (a *MHTMLArchive) inlineURLs(text, base string) string {
+	return cssURLRe.ReplaceAllStringFunc(text, func(ref string) string {
+		m := cssURLRe.FindStringSubmatch(ref)
+		p := a.lookup(m[1]+m[2]+m[3], base)
+		if p == nil {
+			return ref
+		}
+		return `url("` + dataURI(p, p.Body) + `")`
+	})
+}
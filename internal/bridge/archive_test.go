@@ -0,0 +1,85 @@
+package bridge
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testMHTML() []byte {
+	png := base64.StdEncoding.EncodeToString([]byte("\x89PNGfake"))
+	return []byte("From: <Saved by Blink>\r\n" +
+		"Subject: Test\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/related;\r\n" +
+		"\ttype=\"text/html\";\r\n" +
+		"\tboundary=\"----MultipartBoundary--abc----\"\r\n" +
+		"\r\n" +
+		"------MultipartBoundary--abc----\r\n" +
+		"Content-Type: text/html\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"Content-Location: https://example.com/page/\r\n" +
+		"\r\n" +
+		"<html><head><link rel=3D\"stylesheet\" href=3D\"style.css\"></head>=\r\n" +
+		"<body><img src=3D\"/logo.png\" srcset=3D\"/logo@2x.png 2x\"><img src=3D\"https://cdn.example.com/missing.png\"></body></html>\r\n" +
+		"------MultipartBoundary--abc----\r\n" +
+		"Content-Type: text/css\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"Content-Location: https://example.com/page/style.css\r\n" +
+		"\r\n" +
+		"body { background: url(\"../logo.png\"); }\r\n" +
+		"------MultipartBoundary--abc----\r\n" +
+		"Content-Type: image/png\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"Content-Location: https://example.com/logo.png\r\n" +
+		"\r\n" +
+		png + "\r\n" +
+		"------MultipartBoundary--abc------\r\n")
+}
+
+func TestParseMHTML(t *testing.T) {
+	a, err := ParseMHTML(testMHTML())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Parts) != 3 {
+		t.Fatalf("expected 3 parts, got %d", len(a.Parts))
+	}
+	if a.Main.Location != "https://example.com/page/" {
+		t.Errorf("unexpected main location %q", a.Main.Location)
+	}
+	if !strings.Contains(string(a.Main.Body), `<link rel="stylesheet"`) {
+		t.Errorf("quoted-printable not decoded: %s", a.Main.Body)
+	}
+	if string(a.Parts[2].Body) != "\x89PNGfake" {
+		t.Errorf("base64 not decoded: %q", a.Parts[2].Body)
+	}
+}
+
+func TestParseMHTML_Invalid(t *testing.T) {
+	if _, err := ParseMHTML([]byte("Content-Type: text/plain\r\n\r\nhello")); err == nil {
+		t.Error("expected error for non-multipart input")
+	}
+}
+
+func TestInlineHTML(t *testing.T) {
+	a, err := ParseMHTML(testMHTML())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(a.InlineHTML())
+	pngURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("\x89PNGfake"))
+
+	if strings.Contains(out, "<link") {
+		t.Error("stylesheet link should be replaced")
+	}
+	if !strings.Contains(out, `<style>body { background: url("`+pngURI+`"); }`) {
+		t.Errorf("stylesheet not inlined with resolved url(): %s", out)
+	}
+	if !strings.Contains(out, `<img src="`+pngURI+`">`) {
+		t.Errorf("image not inlined or srcset kept: %s", out)
+	}
+	if !strings.Contains(out, `src="https://cdn.example.com/missing.png"`) {
+		t.Error("uncaptured resource should keep its url")
+	}
+}
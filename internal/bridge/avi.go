@@ -0,0 +1,206 @@
+package bridge
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/jpeg"
+	"io"
+)
+
+// AVI layout written by AVIWriter. The header has a fixed size so it can be
+// rewritten in place once the frame count and dimensions are known.
+const (
+	aviHeaderSize  = 224
+	aviMoviFourCC  = 220 // offset of the 'movi' fourcc; idx1 offsets are relative to it
+	aviFlagKeyable = 0x10
+	aviHasIndex    = 0x10
+)
+
+type aviIndexEntry struct {
+	offset uint32
+	size   uint32
+	flags  uint32
+}
+
+// AVIWriter writes a Motion-JPEG AVI (RIFF AVI 1.0) with a constant frame
+// rate. Gaps in the source are filled with zero-length "repeat previous
+// frame" chunks, so wall-clock timing survives without re-encoding anything.
+type AVIWriter struct {
+	w      io.WriteSeeker
+	fps    int
+	width  int
+	height int
+	pos    int64
+	index  []aviIndexEntry
+	maxLen uint32
+	closed bool
+}
+
+// NewAVIWriter reserves the header and starts the 'movi' list. The writer
+// does not close w.
+func NewAVIWriter(w io.WriteSeeker, fps int) (*AVIWriter, error) {
+	if fps <= 0 {
+		return nil, fmt.Errorf("fps must be positive")
+	}
+	a := &AVIWriter{w: w, fps: fps}
+	if _, err := w.Write(a.header(0)); err != nil {
+		return nil, err
+	}
+	a.pos = aviHeaderSize
+	return a, nil
+}
+
+// Frames returns the number of frame slots written, including repeats.
+func (a *AVIWriter) Frames() int { return len(a.index) }
+
+// Size returns the number of bytes written so far.
+func (a *AVIWriter) Size() int64 { return a.pos }
+
+// WriteFrame appends a JPEG frame and returns the file offset of its data.
+func (a *AVIWriter) WriteFrame(frame []byte) (int64, error) {
+	if a.closed {
+		return 0, fmt.Errorf("avi writer closed")
+	}
+	if a.width == 0 {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(frame))
+		if err != nil {
+			return 0, fmt.Errorf("frame is not a jpeg: %w", err)
+		}
+		a.width, a.height = cfg.Width, cfg.Height
+	}
+	dataOffset := a.pos + 8
+	if err := a.chunk(frame, aviFlagKeyable); err != nil {
+		return 0, err
+	}
+	return dataOffset, nil
+}
+
+// RepeatFrame appends a zero-length chunk, which players render by holding
+// the previous frame for one more slot.
+func (a *AVIWriter) RepeatFrame() error {
+	if a.closed {
+		return fmt.Errorf("avi writer closed")
+	}
+	if len(a.index) == 0 {
+		return nil
+	}
+	return a.chunk(nil, 0)
+}
+
+func (a *AVIWriter) chunk(data []byte, flags uint32) error {
+	var hdr [8]byte
+	copy(hdr[:4], "00dc")
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(len(data)))
+	buf := append(hdr[:], data...)
+	if len(data)%2 == 1 {
+		buf = append(buf, 0)
+	}
+	if _, err := a.w.Write(buf); err != nil {
+		return err
+	}
+	a.index = append(a.index, aviIndexEntry{
+		offset: uint32(a.pos - aviMoviFourCC),
+		size:   uint32(len(data)),
+		flags:  flags,
+	})
+	a.maxLen = max(a.maxLen, uint32(len(data)))
+	a.pos += int64(len(buf))
+	return nil
+}
+
+// Close writes the idx1 index and patches the header with the final counts.
+func (a *AVIWriter) Close() error {
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	idx := make([]byte, 8+16*len(a.index))
+	copy(idx[:4], "idx1")
+	binary.LittleEndian.PutUint32(idx[4:], uint32(16*len(a.index)))
+	for i, e := range a.index {
+		b := idx[8+16*i:]
+		copy(b[:4], "00dc")
+		binary.LittleEndian.PutUint32(b[4:], e.flags)
+		binary.LittleEndian.PutUint32(b[8:], e.offset)
+		binary.LittleEndian.PutUint32(b[12:], e.size)
+	}
+	moviEnd := a.pos
+	if _, err := a.w.Write(idx); err != nil {
+		return err
+	}
+	a.pos += int64(len(idx))
+
+	if _, err := a.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := a.w.Write(a.header(moviEnd)); err != nil {
+		return err
+	}
+	_, err := a.w.Seek(a.pos, io.SeekStart)
+	return err
+}
+
+func (a *AVIWriter) header(moviEnd int64) []byte {
+	var b bytes.Buffer
+	le := func(vs ...any) {
+		for _, v := range vs {
+			_ = binary.Write(&b, binary.LittleEndian, v)
+		}
+	}
+	frames := uint32(len(a.index))
+	riffSize, moviSize := uint32(0), uint32(4)
+	if moviEnd > 0 {
+		riffSize = uint32(a.pos - 8)
+		moviSize = uint32(moviEnd - aviMoviFourCC)
+	}
+	w, h := uint32(a.width), uint32(a.height)
+
+	b.WriteString("RIFF")
+	le(riffSize)
+	b.WriteString("AVI ")
+
+	b.WriteString("LIST")
+	le(uint32(192))
+	b.WriteString("hdrl")
+
+	b.WriteString("avih")
+	le(uint32(56),
+		uint32(1000000/a.fps), // microseconds per frame
+		a.maxLen*uint32(a.fps),
+		uint32(0),
+		uint32(aviHasIndex),
+		frames,
+		uint32(0),
+		uint32(1), // streams
+		a.maxLen,
+		w, h,
+		[4]uint32{})
+
+	b.WriteString("LIST")
+	le(uint32(116))
+	b.WriteString("strl")
+
+	b.WriteString("strh")
+	le(uint32(56))
+	b.WriteString("vidsMJPG")
+	le(uint32(0), uint16(0), uint16(0), uint32(0),
+		uint32(1), uint32(a.fps), // scale, rate
+		uint32(0), frames,
+		a.maxLen,
+		int32(-1), uint32(0),
+		[4]uint16{0, 0, uint16(w), uint16(h)})
+
+	b.WriteString("strf")
+	le(uint32(40),
+		uint32(40), int32(w), int32(h),
+		uint16(1), uint16(24))
+	b.WriteString("MJPG")
+	le(w*h*3, int32(0), int32(0), uint32(0), uint32(0))
+
+	b.WriteString("LIST")
+	le(moviSize)
+	b.WriteString("movi")
+	return b.Bytes()
+}
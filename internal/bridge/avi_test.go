@@ -0,0 +1,95 @@
+package bridge
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAVIWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec.avi")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	a, err := NewAVIWriter(f, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RepeatFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if a.Frames() != 0 {
+		t.Error("repeat before the first frame should be a no-op")
+	}
+
+	frame := whiteJPEG(t, 64, 48)
+	off, err := a.WriteFrame(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RepeatFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.WriteFrame(frame); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "AVI " {
+		t.Fatalf("bad riff header %q", data[:12])
+	}
+	if got := binary.LittleEndian.Uint32(data[4:]); int(got) != len(data)-8 {
+		t.Errorf("riff size %d, want %d", got, len(data)-8)
+	}
+	if string(data[aviMoviFourCC:aviMoviFourCC+4]) != "movi" {
+		t.Fatalf("movi list not at %d", aviMoviFourCC)
+	}
+	// avih: total frames and dimensions
+	if got := binary.LittleEndian.Uint32(data[48:]); got != 3 {
+		t.Errorf("avih frames = %d, want 3", got)
+	}
+	if w, h := binary.LittleEndian.Uint32(data[64:]), binary.LittleEndian.Uint32(data[68:]); w != 64 || h != 48 {
+		t.Errorf("avih size = %dx%d", w, h)
+	}
+	if !bytes.Equal(data[off:off+int64(len(frame))], frame) {
+		t.Error("frame offset does not point at the jpeg data")
+	}
+
+	idx := bytes.LastIndex(data, []byte("idx1"))
+	if idx < 0 {
+		t.Fatal("missing idx1")
+	}
+	if n := binary.LittleEndian.Uint32(data[idx+4:]) / 16; n != 3 {
+		t.Errorf("idx1 entries = %d, want 3", n)
+	}
+	second := data[idx+8+16:]
+	if binary.LittleEndian.Uint32(second[12:]) != 0 {
+		t.Error("repeat entry should have zero size")
+	}
+}
+
+func TestAVIWriter_RejectsNonJPEG(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "rec.avi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	a, err := NewAVIWriter(f, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.WriteFrame([]byte("png?")); err == nil {
+		t.Error("expected error for non-jpeg frame")
+	}
+}
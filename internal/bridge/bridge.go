@@ -2,6 +2,7 @@ package bridge
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -16,6 +17,14 @@ type TabEntry struct {
 	Ctx      context.Context
 	Cancel   context.CancelFunc
 	Accessed bool
+	Owner    string // agent that created the tab; "" means shared
+	Opener   string // tab that opened this one as a popup, if any
+	LastUsed time.Time
+	Crash    *TabCrash // set while the renderer is crashed or hung
+
+	// ops is the context handed to requests; see opsContextLocked.
+	ops       context.Context
+	opsCancel context.CancelCauseFunc
 }
 
 type RefCache struct {
@@ -31,6 +40,8 @@ type Bridge struct {
 	StealthScript string
 	Actions       map[string]ActionFunc
 	Locks         *LockManager
+
+	recovery recoveryState
 }
 
 func New(allocCtx, browserCtx context.Context, cfg *config.RuntimeConfig) *Bridge {
@@ -39,10 +50,11 @@ func New(allocCtx, browserCtx context.Context, cfg *config.RuntimeConfig) *Bridg
 		BrowserCtx: browserCtx,
 		Config:     cfg,
 	}
+	b.Locks = NewLockManager()
 	if cfg != nil {
 		b.TabManager = NewTabManager(browserCtx, cfg, b.tabSetup)
+		b.isLocked = func(tabID string) bool { return b.Locks.Get(tabID) != nil }
 	}
-	b.Locks = NewLockManager()
 	return b
 }
 
@@ -69,6 +81,7 @@ func (b *Bridge) tabSetup(ctx context.Context) {
 		}
 	}
 	b.injectStealth(ctx)
+	b.injectVitals(ctx)
 	if b.Config.NoAnimations {
 		b.InjectNoAnimations(ctx)
 	}
@@ -78,6 +91,14 @@ func (b *Bridge) Lock(tabID, owner string, ttl time.Duration) error {
 	return b.Locks.TryLock(tabID, owner, ttl)
 }
 
+func (b *Bridge) LockWait(ctx context.Context, tabID, owner string, ttl time.Duration) error {
+	return b.Locks.Acquire(ctx, tabID, owner, ttl)
+}
+
+func (b *Bridge) RenewLock(tabID, owner string, ttl time.Duration) (time.Time, error) {
+	return b.Locks.Renew(tabID, owner, ttl)
+}
+
 func (b *Bridge) Unlock(tabID, owner string) error {
 	return b.Locks.Unlock(tabID, owner)
 }
@@ -87,13 +108,19 @@ func (b *Bridge) TabLockInfo(tabID string) *LockInfo {
 }
 
 func (b *Bridge) BrowserContext() context.Context {
+	if b.TabManager != nil {
+		return b.browser()
+	}
 	return b.BrowserCtx
 }
 
+// ErrUnknownAction is returned by ExecuteAction for kinds it doesn't know.
+var ErrUnknownAction = errors.New("unknown action")
+
 func (b *Bridge) ExecuteAction(ctx context.Context, kind string, req ActionRequest) (map[string]any, error) {
 	fn, ok := b.Actions[kind]
 	if !ok {
-		return nil, fmt.Errorf("unknown action: %s", kind)
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAction, kind)
 	}
 	return fn(ctx, req)
 }
@@ -123,4 +150,8 @@ type ActionRequest struct {
 	ScrollY  int    `json:"scrollY"`
 	WaitNav  bool   `json:"waitNav"`
 	Fast     bool   `json:"fast"`
+	Owner    string `json:"owner,omitempty"`
+
+	// FollowPopup makes a click wait for the popup it opens and switch to it.
+	FollowPopup bool `json:"followPopup,omitempty"`
 }
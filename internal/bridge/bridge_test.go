@@ -12,7 +12,13 @@ func newTestBridge() *Bridge {
 	b := &Bridge{
 		TabManager: &TabManager{
 			tabs:      make(map[string]*TabEntry),
+			accessed:  make(map[string]bool),
 			snapshots: make(map[string]*RefCache),
+			scripts:   newScriptRegistry(""),
+			queues:    make(map[string]*tabQueue),
+			current:   make(map[string]string),
+			popups:    newPopupLog(),
+			adopting:  make(map[string]*adoption),
 		},
 	}
 	return b
@@ -84,7 +90,7 @@ func TestTabManagerRemoteAllocatorInitialization(t *testing.T) {
 	}
 
 	// Attempting to create a tab with an invalid context should fail gracefully
-	_, _, _, err := tm.CreateTab("about:blank")
+	_, _, _, err := tm.CreateTab("about:blank", "")
 	if err == nil {
 		t.Error("CreateTab should fail when browserCtx is invalid")
 	}
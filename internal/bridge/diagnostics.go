@@ -0,0 +1,157 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// BrowserInfo describes the Chrome the bridge is driving.
+type BrowserInfo struct {
+	Product         string `json:"product"`
+	Revision        string `json:"revision,omitempty"`
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+	UserAgent       string `json:"userAgent,omitempty"`
+	JSVersion       string `json:"jsVersion,omitempty"`
+	// PID, MemoryBytes and CPUSeconds are only known when the bridge
+	// launched Chrome itself; a CDP_URL browser runs outside its reach.
+	// MemoryBytes and CPUSeconds cover the browser and all its child
+	// processes.
+	PID         int     `json:"pid,omitempty"`
+	MemoryBytes int64   `json:"memoryBytes,omitempty"`
+	CPUSeconds  float64 `json:"cpuSeconds,omitempty"`
+}
+
+// BrowserInfo asks Chrome for its version and measures the browser process
+// tree.
+func (b *Bridge) BrowserInfo(ctx context.Context) (BrowserInfo, error) {
+	bctx := b.browser()
+	if bctx == nil {
+		return BrowserInfo{}, errors.New("browser not connected")
+	}
+	c := chromedp.FromContext(bctx)
+	if c == nil || c.Browser == nil {
+		return BrowserInfo{}, errors.New("browser not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	protocol, product, revision, ua, js, err := browser.GetVersion().Do(cdp.WithExecutor(ctx, c.Browser))
+	if err != nil {
+		return BrowserInfo{}, fmt.Errorf("browser version: %w", err)
+	}
+	info := BrowserInfo{
+		Product:         product,
+		Revision:        revision,
+		ProtocolVersion: protocol,
+		UserAgent:       ua,
+		JSVersion:       js,
+	}
+	if p := c.Browser.Process(); p != nil {
+		info.PID = p.Pid
+		info.MemoryBytes, info.CPUSeconds, _ = processTreeStats(p.Pid)
+	}
+	return info, nil
+}
+
+// clockTicks is USER_HZ, the unit of CPU times in /proc. It is 100 on every
+// architecture Chrome ships for.
+const clockTicks = 100
+
+// processTreeStats sums the resident memory and CPU time of pid and its
+// descendants from /proc. Chrome spreads a browser over renderer, GPU and
+// utility processes, so the browser process alone undercounts badly. CPU
+// time includes exited children the browser has reaped, which keeps it from
+// dropping when a renderer goes away. Where /proc is missing it returns an
+// error.
+func processTreeStats(pid int) (rss int64, cpuSec float64, err error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, 0, err
+	}
+	children := make(map[int][]int)
+	ticks := make(map[int]int64)
+	for _, e := range entries {
+		child, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if parent, cpu, ok := procStat(child); ok {
+			children[parent] = append(children[parent], child)
+			ticks[child] = cpu
+		}
+	}
+
+	var totalTicks int64
+	pending := []int{pid}
+	seen := map[int]bool{}
+	for len(pending) > 0 {
+		p := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		rss += residentBytes(p)
+		totalTicks += ticks[p]
+		pending = append(pending, children[p]...)
+	}
+	return rss, float64(totalTicks) / clockTicks, nil
+}
+
+// procStat reads the parent PID and the CPU time, in clock ticks, of a
+// process and its reaped children from /proc/<pid>/stat. The command name in
+// field 2 may contain spaces and parentheses, so parsing starts after the
+// last ')'.
+func procStat(pid int) (ppid int, cpuTicks int64, ok bool) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, 0, false
+	}
+	s := string(data)
+	i := strings.LastIndexByte(s, ')')
+	if i < 0 {
+		return 0, 0, false
+	}
+	// fields[0] is field 3 (state): ppid is field 4, utime, stime, cutime
+	// and cstime are fields 14 to 17.
+	fields := strings.Fields(s[i+1:])
+	if len(fields) < 15 {
+		return 0, 0, false
+	}
+	ppid, err = strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	for _, f := range fields[11:15] {
+		n, _ := strconv.ParseInt(f, 10, 64)
+		cpuTicks += n
+	}
+	return ppid, cpuTicks, true
+}
+
+// residentBytes reads the resident set size from /proc/<pid>/statm.
+func residentBytes(pid int) int64 {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "statm"))
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * int64(os.Getpagesize())
+}
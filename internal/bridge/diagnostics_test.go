@@ -0,0 +1,39 @@
+package bridge
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestProcessTreeStats_Self(t *testing.T) {
+	if _, err := os.Stat("/proc/self/statm"); err != nil {
+		t.Skip("no /proc on this platform")
+	}
+	rss, cpu, err := processTreeStats(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rss <= 0 {
+		t.Errorf("expected resident memory for the test process, got %d", rss)
+	}
+	if cpu < 0 {
+		t.Errorf("expected non-negative CPU time, got %v", cpu)
+	}
+}
+
+func TestProcStat_Self(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("no /proc on this platform")
+	}
+	if ppid, _, ok := procStat(os.Getpid()); !ok || ppid != os.Getppid() {
+		t.Errorf("procStat ppid = %d, %v; want %d", ppid, ok, os.Getppid())
+	}
+}
+
+func TestBrowserInfo_NotConnected(t *testing.T) {
+	b := newTestBridge()
+	if _, err := b.BrowserInfo(context.Background()); err == nil {
+		t.Error("expected error without a browser")
+	}
+}
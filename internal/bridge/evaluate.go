@@ -0,0 +1,278 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// isolatedWorldName names the world scripts run in with EvalOptions.Isolated.
+// Pages cannot see or modify its globals.
+const isolatedWorldName = "pinchtab"
+
+// evalGroupSeq numbers the object group of each evaluation. Everything a
+// call creates in the page (the receiver, a resolved element, the result) is
+// put in its group and released before Evaluate returns, except a result
+// kept as a handle, which goes in EvalOptions.HandleGroup.
+var evalGroupSeq atomic.Uint64
+
+// EvalOptions controls a script evaluation.
+//
+// Without Args, NodeID or ObjectID, Expression is evaluated as-is. With any
+// of them, it must be a function expression; it is called with the bound
+// element or object (if any) followed by Args, and the bound value is also
+// passed as `this`. A bound ObjectID runs the function in the world the
+// object came from.
+type EvalOptions struct {
+	Expression    string
+	Args          []json.RawMessage
+	NodeID        int64  // backend node ID to bind, e.g. from a snapshot ref
+	ObjectID      string // handle from an earlier evaluation to bind
+	AwaitPromise  bool
+	ReturnByValue bool
+	HandleGroup   string // keeps the result here when ReturnByValue is false
+	Isolated      bool
+	Timeout       time.Duration // also bounds synchronous execution in the page
+}
+
+// EvalHandle describes the object a script returned when ReturnByValue is
+// false. Objects are kept in the page under ObjectGroup until released with
+// ReleaseHandles or the page navigates; primitives have no ObjectID.
+type EvalHandle struct {
+	ObjectID    string `json:"objectId,omitempty"`
+	ObjectGroup string `json:"objectGroup,omitempty"`
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype,omitempty"`
+	ClassName   string `json:"className,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// EvalResult is either a JSON value or, in handle mode, an object handle.
+type EvalResult struct {
+	Value  any
+	Handle *EvalHandle
+}
+
+// Evaluate runs a script in the tab with arguments, promise awaiting and an
+// optional isolated world.
+func Evaluate(ctx context.Context, opts EvalOptions) (*EvalResult, error) {
+	group := fmt.Sprintf("pinchtab-eval-%d", evalGroupSeq.Add(1))
+	resultGroup := group
+	if !opts.ReturnByValue && opts.HandleGroup != "" {
+		resultGroup = opts.HandleGroup
+	}
+	var res *EvalResult
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		defer func() {
+			// Release even when the caller's context is done.
+			rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+			defer cancel()
+			_ = runtime.ReleaseObjectGroup(group).Do(rctx)
+		}()
+
+		var contextID runtime.ExecutionContextID
+		if opts.Isolated {
+			id, err := isolatedWorld(ctx, false)
+			if err != nil {
+				return err
+			}
+			contextID = id
+		}
+
+		obj, exc, err := evaluateIn(ctx, contextID, group, resultGroup, opts)
+		if err != nil && opts.Isolated && staleContext(err) {
+			// The world went away with its document; make a new one.
+			if contextID, err = isolatedWorld(ctx, true); err != nil {
+				return err
+			}
+			obj, exc, err = evaluateIn(ctx, contextID, group, resultGroup, opts)
+		}
+		if err != nil {
+			return err
+		}
+		if exc != nil {
+			return fmt.Errorf("uncaught %s", exceptionMessage(exc))
+		}
+		res, err = evalResult(obj, opts.ReturnByValue)
+		if err == nil && res.Handle != nil && res.Handle.ObjectID != "" {
+			res.Handle.ObjectGroup = resultGroup
+		}
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ReleaseHandles releases an object kept by a handle-mode evaluation, or
+// with an empty objectID every object in the group.
+func ReleaseHandles(ctx context.Context, group, objectID string) error {
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if objectID != "" {
+			return runtime.ReleaseObject(runtime.RemoteObjectID(objectID)).Do(ctx)
+		}
+		return runtime.ReleaseObjectGroup(group).Do(ctx)
+	}))
+}
+
+// evaluateIn runs the script; objects it needs along the way go in group
+// and the result in resultGroup.
+func evaluateIn(ctx context.Context, contextID runtime.ExecutionContextID, group, resultGroup string, opts EvalOptions) (*runtime.RemoteObject, *runtime.ExceptionDetails, error) {
+	if len(opts.Args) > 0 || opts.NodeID != 0 || opts.ObjectID != "" {
+		return callFunction(ctx, contextID, group, resultGroup, opts)
+	}
+	p := runtime.Evaluate(opts.Expression).
+		WithAwaitPromise(opts.AwaitPromise).
+		WithReturnByValue(opts.ReturnByValue).
+		WithObjectGroup(resultGroup).
+		WithContextID(contextID)
+	if opts.Timeout > 0 {
+		p = p.WithTimeout(runtime.TimeDelta(opts.Timeout.Milliseconds()))
+	}
+	return p.Do(ctx)
+}
+
+// isolatedWorlds caches the isolated world of each document, keyed by frame
+// and loader, so repeated isolated evaluations share one world instead of
+// creating another every call.
+var isolatedWorlds = struct {
+	sync.Mutex
+	ids map[string]runtime.ExecutionContextID
+}{ids: make(map[string]runtime.ExecutionContextID)}
+
+// maxIsolatedWorlds bounds the cache; entries of documents that are gone are
+// never looked up again, so it is simply emptied when full.
+const maxIsolatedWorlds = 1024
+
+// isolatedWorld returns the isolated world of the tab's current document,
+// creating it on first use or when fresh is set.
+func isolatedWorld(ctx context.Context, fresh bool) (runtime.ExecutionContextID, error) {
+	tree, err := page.GetFrameTree().Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("frame tree: %w", err)
+	}
+	key := string(tree.Frame.ID) + "/" + string(tree.Frame.LoaderID)
+
+	isolatedWorlds.Lock()
+	id, ok := isolatedWorlds.ids[key]
+	isolatedWorlds.Unlock()
+	if ok && !fresh {
+		return id, nil
+	}
+
+	id, err = page.CreateIsolatedWorld(tree.Frame.ID).WithWorldName(isolatedWorldName).Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("isolated world: %w", err)
+	}
+	isolatedWorlds.Lock()
+	if len(isolatedWorlds.ids) >= maxIsolatedWorlds {
+		clear(isolatedWorlds.ids)
+	}
+	isolatedWorlds.ids[key] = id
+	isolatedWorlds.Unlock()
+	return id, nil
+}
+
+// staleContext reports whether err says the execution context is gone.
+func staleContext(err error) bool {
+	return strings.Contains(err.Error(), "Cannot find context with specified id")
+}
+
+func callFunction(ctx context.Context, contextID runtime.ExecutionContextID, group, resultGroup string, opts EvalOptions) (*runtime.RemoteObject, *runtime.ExceptionDetails, error) {
+	var target runtime.RemoteObjectID
+	var args []*runtime.CallArgument
+
+	if opts.ObjectID != "" {
+		target = runtime.RemoteObjectID(opts.ObjectID)
+		args = append(args, &runtime.CallArgument{ObjectID: target})
+	} else if opts.NodeID != 0 {
+		p := dom.ResolveNode().
+			WithBackendNodeID(cdp.BackendNodeID(opts.NodeID)).
+			WithObjectGroup(group)
+		if contextID != 0 {
+			p = p.WithExecutionContextID(contextID)
+		}
+		node, err := p.Do(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolve node: %w", err)
+		}
+		target = node.ObjectID
+		args = append(args, &runtime.CallArgument{ObjectID: node.ObjectID})
+	} else {
+		// callFunctionOn needs a receiver; the world's global object will do.
+		global, exc, err := runtime.Evaluate("globalThis").
+			WithObjectGroup(group).
+			WithContextID(contextID).
+			Do(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if exc != nil {
+			return nil, exc, nil
+		}
+		target = global.ObjectID
+	}
+
+	for i, a := range opts.Args {
+		if !json.Valid(a) {
+			return nil, nil, fmt.Errorf("args[%d] is not valid JSON", i)
+		}
+		args = append(args, &runtime.CallArgument{Value: []byte(a)})
+	}
+
+	return runtime.CallFunctionOn(opts.Expression).
+		WithObjectID(target).
+		WithArguments(args).
+		WithAwaitPromise(opts.AwaitPromise).
+		WithReturnByValue(opts.ReturnByValue).
+		WithObjectGroup(resultGroup).
+		Do(ctx)
+}
+
+func exceptionMessage(exc *runtime.ExceptionDetails) string {
+	if exc.Exception != nil && exc.Exception.Description != "" {
+		return exc.Exception.Description
+	}
+	if exc.Exception != nil && len(exc.Exception.Value) > 0 {
+		return exc.Text + " " + string(exc.Exception.Value)
+	}
+	return exc.Text
+}
+
+func evalResult(obj *runtime.RemoteObject, byValue bool) (*EvalResult, error) {
+	if obj == nil {
+		return &EvalResult{}, nil
+	}
+	if !byValue {
+		return &EvalResult{Handle: &EvalHandle{
+			ObjectID:    string(obj.ObjectID),
+			Type:        string(obj.Type),
+			Subtype:     string(obj.Subtype),
+			ClassName:   obj.ClassName,
+			Description: obj.Description,
+		}}, nil
+	}
+	// NaN, Infinity, -0 and bigints have no JSON form; report them as strings.
+	if obj.UnserializableValue != "" {
+		return &EvalResult{Value: string(obj.UnserializableValue)}, nil
+	}
+	if len(obj.Value) == 0 {
+		return &EvalResult{}, nil
+	}
+	var v any
+	if err := json.Unmarshal(obj.Value, &v); err != nil {
+		return nil, fmt.Errorf("decode result: %w", err)
+	}
+	return &EvalResult{Value: v}, nil
+}
@@ -0,0 +1,73 @@
+package bridge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chromedp/cdproto/runtime"
+)
+
+func TestEvalResult_ByValue(t *testing.T) {
+	res, err := evalResult(&runtime.RemoteObject{Type: "object", Value: []byte(`{"a":[1,2]}`)}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := res.Value.(map[string]any)
+	if !ok || len(m["a"].([]any)) != 2 {
+		t.Errorf("unexpected value %#v", res.Value)
+	}
+	if res.Handle != nil {
+		t.Error("no handle expected by value")
+	}
+}
+
+func TestEvalResult_Unserializable(t *testing.T) {
+	res, err := evalResult(&runtime.RemoteObject{Type: "number", UnserializableValue: "NaN"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Value != "NaN" {
+		t.Errorf("expected NaN, got %#v", res.Value)
+	}
+}
+
+func TestEvalResult_Undefined(t *testing.T) {
+	res, err := evalResult(&runtime.RemoteObject{Type: "undefined"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Value != nil {
+		t.Errorf("expected nil, got %#v", res.Value)
+	}
+}
+
+func TestEvalResult_Handle(t *testing.T) {
+	obj := &runtime.RemoteObject{Type: "object", Subtype: "node", ClassName: "HTMLDivElement", Description: "div#main", ObjectID: "42.1"}
+	res, err := evalResult(obj, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Handle == nil || res.Handle.Subtype != "node" || res.Handle.Description != "div#main" || res.Handle.ObjectID != "42.1" {
+		t.Errorf("unexpected handle %#v", res.Handle)
+	}
+}
+
+func TestExceptionMessage(t *testing.T) {
+	exc := &runtime.ExceptionDetails{Text: "Uncaught", Exception: &runtime.RemoteObject{Description: "Error: boom"}}
+	if got := exceptionMessage(exc); got != "Error: boom" {
+		t.Errorf("got %q", got)
+	}
+	exc = &runtime.ExceptionDetails{Text: "Uncaught", Exception: &runtime.RemoteObject{Value: []byte(`"plain"`)}}
+	if got := exceptionMessage(exc); got != `Uncaught "plain"` {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestStaleContext(t *testing.T) {
+	if !staleContext(errors.New("Cannot find context with specified id (-32000)")) {
+		t.Error("expected a stale context")
+	}
+	if staleContext(errors.New("uncaught ReferenceError")) {
+		t.Error("script errors are not stale contexts")
+	}
+}
@@ -0,0 +1,114 @@
+package bridge
+
+import (
+	"image"
+	"image/color"
+)
+
+// DefaultDiffThreshold is the per-pixel colour distance (0-1) below which two
+// pixels are considered equal. It absorbs JPEG noise and sub-pixel AA.
+const DefaultDiffThreshold = 0.1
+
+// DiffResult summarises a pixel comparison between a baseline and an actual
+// image. MismatchPercent is relative to the compared pixels, TotalPixels less
+// IgnoredPixels.
+type DiffResult struct {
+	MismatchPixels  int     `json:"mismatchPixels"`
+	TotalPixels     int     `json:"totalPixels"`
+	IgnoredPixels   int     `json:"ignoredPixels"`
+	MismatchPercent float64 `json:"mismatchPercent"`
+	SizeMismatch    bool    `json:"sizeMismatch,omitempty"`
+}
+
+var (
+	diffMismatch = color.RGBA{255, 0, 64, 255}
+	diffIgnored  = color.RGBA{255, 200, 0, 255}
+)
+
+// DiffImages compares actual against baseline pixel by pixel. Pixels inside
+// any ignore rectangle are skipped. The returned image shows the actual
+// screenshot faded, with mismatching pixels in red and ignored areas tinted.
+//
+// When the sizes differ the area of both images is walked: only the overlap is
+// compared and every pixel present in just one image counts as a mismatch.
+func DiffImages(baseline, actual image.Image, threshold float64, ignore []image.Rectangle) (DiffResult, *image.RGBA) {
+	if threshold < 0 {
+		threshold = 0
+	}
+	ab := actual.Bounds()
+	bb := baseline.Bounds()
+	width, height := max(ab.Dx(), bb.Dx()), max(ab.Dy(), bb.Dy())
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	res := DiffResult{
+		TotalPixels:  width * height,
+		SizeMismatch: ab.Dx() != bb.Dx() || ab.Dy() != bb.Dy(),
+	}
+
+	limit := uint32(threshold * 0xffff)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			inActual := x < ab.Dx() && y < ab.Dy()
+			inBaseline := x < bb.Dx() && y < bb.Dy()
+			var ac color.Color = color.Transparent
+			if inActual {
+				ac = actual.At(ab.Min.X+x, ab.Min.Y+y)
+			}
+			if pointIn(x, y, ignore) {
+				res.IgnoredPixels++
+				out.SetRGBA(x, y, blend(ac, diffIgnored, 0.35))
+				continue
+			}
+			if !inActual || !inBaseline {
+				res.MismatchPixels++
+				out.SetRGBA(x, y, diffMismatch)
+				continue
+			}
+			if colorDistance(ac, baseline.At(bb.Min.X+x, bb.Min.Y+y)) > limit {
+				res.MismatchPixels++
+				out.SetRGBA(x, y, diffMismatch)
+				continue
+			}
+			out.SetRGBA(x, y, blend(ac, color.White, 0.7))
+		}
+	}
+
+	if compared := res.TotalPixels - res.IgnoredPixels; compared > 0 {
+		res.MismatchPercent = float64(res.MismatchPixels) * 100 / float64(compared)
+	}
+	return res, out
+}
+
+func pointIn(x, y int, rects []image.Rectangle) bool {
+	p := image.Pt(x, y)
+	for _, r := range rects {
+		if p.In(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// colorDistance is the largest per-channel difference, in 16-bit units.
+func colorDistance(a, b color.Color) uint32 {
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	return max(absDiff(ar, br), absDiff(ag, bg), absDiff(ab, bb))
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// blend mixes c towards over by the given weight (0 keeps c, 1 is over).
+func blend(c, over color.Color, weight float64) color.RGBA {
+	cr, cg, cb, _ := c.RGBA()
+	or, og, ob, _ := over.RGBA()
+	mix := func(a, b uint32) uint8 {
+		return uint8((float64(a)*(1-weight) + float64(b)*weight) / 257)
+	}
+	return color.RGBA{mix(cr, or), mix(cg, og), mix(cb, ob), 255}
+}
@@ -0,0 +1,101 @@
+package bridge
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func solid(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestDiffImages_Identical(t *testing.T) {
+	a := solid(10, 10, color.White)
+	res, out := DiffImages(a, solid(10, 10, color.White), DefaultDiffThreshold, nil)
+	if res.MismatchPixels != 0 || res.MismatchPercent != 0 {
+		t.Errorf("expected no mismatch, got %+v", res)
+	}
+	if res.TotalPixels != 100 {
+		t.Errorf("expected 100 pixels, got %d", res.TotalPixels)
+	}
+	if out.Bounds().Dx() != 10 {
+		t.Errorf("unexpected diff size %v", out.Bounds())
+	}
+}
+
+func TestDiffImages_Changed(t *testing.T) {
+	base := solid(10, 10, color.White)
+	actual := solid(10, 10, color.White)
+	draw.Draw(actual, image.Rect(0, 0, 5, 2), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	res, out := DiffImages(base, actual, DefaultDiffThreshold, nil)
+	if res.MismatchPixels != 10 {
+		t.Errorf("expected 10 mismatches, got %d", res.MismatchPixels)
+	}
+	if res.MismatchPercent != 10 {
+		t.Errorf("expected 10%%, got %v", res.MismatchPercent)
+	}
+	if out.RGBAAt(0, 0) != diffMismatch {
+		t.Errorf("expected mismatch colour, got %v", out.RGBAAt(0, 0))
+	}
+}
+
+func TestDiffImages_Threshold(t *testing.T) {
+	base := solid(4, 4, color.RGBA{100, 100, 100, 255})
+	actual := solid(4, 4, color.RGBA{110, 100, 100, 255})
+
+	if res, _ := DiffImages(base, actual, 0.1, nil); res.MismatchPixels != 0 {
+		t.Errorf("small delta should be within threshold, got %d", res.MismatchPixels)
+	}
+	if res, _ := DiffImages(base, actual, 0, nil); res.MismatchPixels != 16 {
+		t.Errorf("zero threshold should flag all, got %d", res.MismatchPixels)
+	}
+}
+
+func TestDiffImages_Ignore(t *testing.T) {
+	base := solid(10, 10, color.White)
+	actual := solid(10, 10, color.Black)
+	res, _ := DiffImages(base, actual, DefaultDiffThreshold, []image.Rectangle{image.Rect(0, 0, 10, 5)})
+	if res.MismatchPixels != 50 {
+		t.Errorf("expected 50 mismatches outside ignore region, got %d", res.MismatchPixels)
+	}
+}
+
+func TestDiffImages_PercentOfCompared(t *testing.T) {
+	// The bottom half is ignored; two of the five compared rows differ.
+	base := solid(10, 10, color.White)
+	actual := solid(10, 10, color.Black)
+	draw.Draw(actual, image.Rect(0, 2, 10, 5), image.NewUniform(color.White), image.Point{}, draw.Src)
+	ignore := []image.Rectangle{image.Rect(0, 5, 10, 10)}
+
+	res, _ := DiffImages(base, actual, DefaultDiffThreshold, ignore)
+	if res.IgnoredPixels != 50 || res.TotalPixels != 100 {
+		t.Errorf("expected 50 of 100 pixels ignored, got %+v", res)
+	}
+	if res.MismatchPixels != 20 || res.MismatchPercent != 40 {
+		t.Errorf("expected 20 mismatches = 40%% of the compared pixels, got %+v", res)
+	}
+}
+
+func TestDiffImages_SizeMismatch(t *testing.T) {
+	res, _ := DiffImages(solid(10, 5, color.White), solid(10, 10, color.White), DefaultDiffThreshold, nil)
+	if !res.SizeMismatch {
+		t.Error("expected size mismatch")
+	}
+	if res.MismatchPixels != 50 {
+		t.Errorf("expected non-overlapping rows to mismatch, got %d", res.MismatchPixels)
+	}
+
+	// Rows that only the baseline has count too.
+	res, diff := DiffImages(solid(10, 10, color.White), solid(10, 5, color.White), DefaultDiffThreshold, nil)
+	if res.MismatchPixels != 50 || res.TotalPixels != 100 {
+		t.Errorf("expected 50/100 mismatches for a larger baseline, got %d/%d", res.MismatchPixels, res.TotalPixels)
+	}
+	if diff.Bounds().Dy() != 10 {
+		t.Errorf("diff should cover both images, got %v", diff.Bounds())
+	}
+}
@@ -1,6 +1,8 @@
 package bridge
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -8,45 +10,167 @@ import (
 
 const DefaultLockTimeout = 10 * time.Minute
 
+// ErrNotLockOwner is returned when renewing a lock the caller doesn't hold.
+var ErrNotLockOwner = errors.New("lock not held")
+
 type lockEntry struct {
 	owner   string
 	expires time.Time
+	ttl     time.Duration
+}
+
+// lockWaiter is a caller queued in LockManager.Acquire. ready is signalled
+// when the waiter reaches the head of the queue or the lock is released.
+type lockWaiter struct {
+	owner string
+	ready chan struct{}
 }
 
 type LockManager struct {
-	locks map[string]lockEntry
-	mu    sync.Mutex
+	locks   map[string]lockEntry
+	waiters map[string][]*lockWaiter
+	mu      sync.Mutex
 }
 
 func NewLockManager() *LockManager {
 	return &LockManager{
-		locks: make(map[string]lockEntry),
+		locks:   make(map[string]lockEntry),
+		waiters: make(map[string][]*lockWaiter),
 	}
 }
 
+// heldLocked returns the live lock on a tab, dropping it if expired.
+// Callers hold m.mu.
+func (m *LockManager) heldLocked(tabID string) (lockEntry, bool) {
+	l, ok := m.locks[tabID]
+	if ok && !time.Now().Before(l.expires) {
+		delete(m.locks, tabID)
+		return lockEntry{}, false
+	}
+	return l, ok
+}
+
 func (m *LockManager) TryLock(tabID, owner string, ttl time.Duration) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	l, ok := m.locks[tabID]
-	if ok && time.Now().Before(l.expires) && l.owner != owner {
+	l, ok := m.heldLocked(tabID)
+	if ok && l.owner != owner {
 		return fmt.Errorf("tab %s is locked by %s for another %v", tabID, l.owner, time.Until(l.expires).Round(time.Second))
 	}
+	// A free tab still goes to the queue first; only the holder may jump it.
+	if !ok {
+		if q := m.waiters[tabID]; len(q) > 0 && q[0].owner != owner {
+			return fmt.Errorf("tab %s has %d queued waiter(s)", tabID, len(q))
+		}
+	}
 
 	m.locks[tabID] = lockEntry{
 		owner:   owner,
 		expires: time.Now().Add(ttl),
+		ttl:     ttl,
 	}
 	return nil
 }
 
+// Acquire waits in FIFO order until the lock can be taken, ctx is done, or
+// the caller already holds it. The wait ends with ctx's error on timeout.
+func (m *LockManager) Acquire(ctx context.Context, tabID, owner string, ttl time.Duration) error {
+	if err := m.TryLock(tabID, owner, ttl); err == nil {
+		return nil
+	}
+
+	w := &lockWaiter{owner: owner, ready: make(chan struct{}, 1)}
+	m.mu.Lock()
+	m.waiters[tabID] = append(m.waiters[tabID], w)
+	for {
+		l, held := m.heldLocked(tabID)
+		q := m.waiters[tabID]
+		if len(q) > 0 && q[0] == w && (!held || l.owner == owner) {
+			m.removeWaiterLocked(tabID, w)
+			m.locks[tabID] = lockEntry{owner: owner, expires: time.Now().Add(ttl), ttl: ttl}
+			m.mu.Unlock()
+			return nil
+		}
+
+		// Wake up when signalled or when the current lock would expire.
+		var expiry <-chan time.Time
+		var timer *time.Timer
+		if held {
+			timer = time.NewTimer(time.Until(l.expires))
+			expiry = timer.C
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-w.ready:
+		case <-expiry:
+		case <-ctx.Done():
+			m.mu.Lock()
+			m.removeWaiterLocked(tabID, w)
+			m.mu.Unlock()
+			return fmt.Errorf("waiting for tab %s: %w", tabID, ctx.Err())
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		m.mu.Lock()
+	}
+}
+
+// removeWaiterLocked drops w from the queue and wakes the new head.
+func (m *LockManager) removeWaiterLocked(tabID string, w *lockWaiter) {
+	q := m.waiters[tabID]
+	for i, x := range q {
+		if x == w {
+			q = append(q[:i:i], q[i+1:]...)
+			break
+		}
+	}
+	if len(q) == 0 {
+		delete(m.waiters, tabID)
+		return
+	}
+	m.waiters[tabID] = q
+	m.wakeLocked(tabID)
+}
+
+// wakeLocked signals the head of a tab's queue to re-check the lock.
+func (m *LockManager) wakeLocked(tabID string) {
+	if q := m.waiters[tabID]; len(q) > 0 {
+		select {
+		case q[0].ready <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Renew extends a held lock by ttl, or by its original ttl when ttl is 0.
+// Short leases kept alive this way free the tab soon after an agent dies.
+func (m *LockManager) Renew(tabID, owner string, ttl time.Duration) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.heldLocked(tabID)
+	if !ok || l.owner != owner {
+		return time.Time{}, fmt.Errorf("tab %s: %w by %s", tabID, ErrNotLockOwner, owner)
+	}
+	if ttl <= 0 {
+		ttl = l.ttl
+	}
+	l.expires = time.Now().Add(ttl)
+	l.ttl = ttl
+	m.locks[tabID] = l
+	return l.expires, nil
+}
+
 func (m *LockManager) Unlock(tabID, owner string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	l, ok := m.locks[tabID]
-	if !ok || time.Now().After(l.expires) {
-		delete(m.locks, tabID)
+	l, ok := m.heldLocked(tabID)
+	if !ok {
+		m.wakeLocked(tabID)
 		return nil
 	}
 
@@ -55,6 +179,7 @@ func (m *LockManager) Unlock(tabID, owner string) error {
 	}
 
 	delete(m.locks, tabID)
+	m.wakeLocked(tabID)
 	return nil
 }
 
@@ -62,13 +187,47 @@ func (m *LockManager) Get(tabID string) *LockInfo {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	l, ok := m.locks[tabID]
-	if !ok || time.Now().After(l.expires) {
+	l, ok := m.heldLocked(tabID)
+	if !ok {
 		return nil
 	}
 
 	return &LockInfo{
 		Owner:     l.owner,
 		ExpiresAt: l.expires,
+		Waiters:   len(m.waiters[tabID]),
+	}
+}
+
+// ReleaseOwner drops every lock held by owner and returns the freed tab IDs.
+func (m *LockManager) ReleaseOwner(owner string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var freed []string
+	for tabID, l := range m.locks {
+		if l.owner != owner {
+			continue
+		}
+		delete(m.locks, tabID)
+		m.wakeLocked(tabID)
+		freed = append(freed, tabID)
+	}
+	return freed
+}
+
+// Rekey moves locks from old to new tab IDs after the browser was relaunched
+// and its tabs recreated under new IDs.
+func (m *LockManager) Rekey(ids map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	locks := make(map[string]lockEntry, len(m.locks))
+	for tabID, l := range m.locks {
+		if newID, ok := ids[tabID]; ok {
+			tabID = newID
+		}
+		locks[tabID] = l
 	}
+	m.locks = locks
 }
@@ -1,6 +1,8 @@
 package bridge
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -57,3 +59,113 @@ func TestLockManagerGet(t *testing.T) {
 		t.Errorf("expected owner %s, got %s", owner, info.Owner)
 	}
 }
+
+func waitForWaiters(t *testing.T, m *LockManager, tabID string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		m.mu.Lock()
+		got := len(m.waiters[tabID])
+		m.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d waiters", n)
+}
+
+func TestLockManager_AcquireFIFO(t *testing.T) {
+	m := NewLockManager()
+	if err := m.TryLock("tab1", "a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan string, 2)
+	for i, owner := range []string{"b", "c"} {
+		go func() {
+			if err := m.Acquire(context.Background(), "tab1", owner, time.Minute); err != nil {
+				t.Errorf("%s: %v", owner, err)
+				return
+			}
+			order <- owner
+		}()
+		waitForWaiters(t, m, "tab1", i+1)
+	}
+
+	if err := m.TryLock("tab1", "d", time.Minute); err == nil {
+		t.Error("TryLock should not jump a held lock")
+	}
+	if info := m.Get("tab1"); info == nil || info.Waiters != 2 {
+		t.Errorf("expected 2 waiters, got %+v", info)
+	}
+
+	_ = m.Unlock("tab1", "a")
+	if got := <-order; got != "b" {
+		t.Fatalf("expected b first, got %s", got)
+	}
+	if err := m.TryLock("tab1", "d", time.Minute); err == nil {
+		t.Error("TryLock should not jump the queue")
+	}
+	_ = m.Unlock("tab1", "b")
+	if got := <-order; got != "c" {
+		t.Fatalf("expected c second, got %s", got)
+	}
+}
+
+func TestLockManager_AcquireTimeout(t *testing.T) {
+	m := NewLockManager()
+	_ = m.TryLock("tab1", "a", time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.Acquire(ctx, "tab1", "b", time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if info := m.Get("tab1"); info.Waiters != 0 {
+		t.Errorf("timed-out waiter should leave the queue, got %d", info.Waiters)
+	}
+}
+
+func TestLockManager_AcquireAfterExpiry(t *testing.T) {
+	m := NewLockManager()
+	_ = m.TryLock("tab1", "a", 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := m.Acquire(ctx, "tab1", "b", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("waiter should wake when the lease lapses")
+	}
+	if info := m.Get("tab1"); info.Owner != "b" {
+		t.Errorf("expected b to hold the lock, got %s", info.Owner)
+	}
+}
+
+func TestLockManager_Renew(t *testing.T) {
+	m := NewLockManager()
+	_ = m.TryLock("tab1", "a", 100*time.Millisecond)
+
+	if _, err := m.Renew("tab1", "b", 0); !errors.Is(err, ErrNotLockOwner) {
+		t.Errorf("expected ErrNotLockOwner, got %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	exp, err := m.Renew("tab1", "a", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(exp) < 80*time.Millisecond {
+		t.Errorf("renew should restore the full lease, expires in %v", time.Until(exp))
+	}
+	time.Sleep(60 * time.Millisecond)
+	if m.Get("tab1") == nil {
+		t.Error("renewed lock expired early")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err := m.Renew("tab1", "a", 0); err == nil {
+		t.Error("renewing a lapsed lease should fail")
+	}
+}
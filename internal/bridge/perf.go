@@ -0,0 +1,151 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/performance"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// WebVitalsJS records Core Web Vitals as the page loads. It is registered on
+// every new document so entries from the first paint on are seen; buffered
+// observers also pick up what happened before the script ran. It runs in its
+// own isolated world, which shares the performance timeline with the page
+// but not its globals, so the page has no way to find the collector.
+//
+// CLS uses the session-window definition (gaps under 1s, windows up to 5s).
+// INP is the slowest interaction, which equals the spec's percentile for
+// pages with fewer than 50 interactions.
+const WebVitalsJS = `
+(() => {
+  const key = Symbol.for('pinchtab.vitals');
+  if (window[key] || typeof PerformanceObserver === 'undefined') return;
+  const v = { lcp: null, cls: 0, inp: null, fid: null };
+  Object.defineProperty(window, key, { value: v });
+  const observe = (type, fn, opts) => {
+    try {
+      new PerformanceObserver(list => list.getEntries().forEach(fn))
+        .observe(Object.assign({ type, buffered: true }, opts));
+    } catch (e) {}
+  };
+  observe('largest-contentful-paint', e => { v.lcp = e.startTime; });
+  let session = 0, first = 0, last = 0;
+  observe('layout-shift', e => {
+    if (e.hadRecentInput) return;
+    if (session && e.startTime - last < 1000 && e.startTime - first < 5000) {
+      session += e.value;
+    } else {
+      session = e.value;
+      first = e.startTime;
+    }
+    last = e.startTime;
+    if (session > v.cls) v.cls = session;
+  });
+  observe('first-input', e => {
+    if (v.fid === null) v.fid = e.processingStart - e.startTime;
+  });
+  observe('event', e => {
+    if (e.interactionId && (v.inp === null || e.duration > v.inp)) v.inp = e.duration;
+  }, { durationThreshold: 16 });
+})();
+`
+
+// vitalsWorldName is the isolated world WebVitalsJS runs and is read in.
+const vitalsWorldName = "pinchtab-vitals"
+
+// readVitalsJS reads what WebVitalsJS collected. TTFB and FCP come straight
+// from the performance timeline, so they are there even in tabs opened
+// before the observer was registered.
+const readVitalsJS = `
+(() => {
+  const v = window[Symbol.for('pinchtab.vitals')];
+  const ms = x => (x === null || x === undefined) ? null : Math.round(x * 100) / 100;
+  const nav = performance.getEntriesByType('navigation')[0];
+  const fcp = performance.getEntriesByName('first-contentful-paint')[0];
+  return {
+    lcp: v ? ms(v.lcp) : null,
+    cls: v ? Math.round(v.cls * 10000) / 10000 : null,
+    inp: v ? ms(v.inp) : null,
+    fid: v ? ms(v.fid) : null,
+    ttfb: nav ? ms(Math.max(nav.responseStart - (nav.activationStart || 0), 0)) : null,
+    fcp: fcp ? ms(fcp.startTime) : null,
+  };
+})()
+`
+
+// WebVitals are in milliseconds, except CLS which is unitless. A nil value
+// hasn't happened yet: LCP settles once the user interacts, INP and FID need
+// an interaction.
+type WebVitals struct {
+	LCP  *float64 `json:"lcp"`
+	CLS  *float64 `json:"cls"`
+	INP  *float64 `json:"inp"`
+	FID  *float64 `json:"fid"`
+	TTFB *float64 `json:"ttfb"`
+	FCP  *float64 `json:"fcp"`
+}
+
+// PerfReport pairs Chrome's Performance.getMetrics counters (JSHeapUsedSize,
+// Nodes, LayoutCount, ...) with the page's Web Vitals.
+type PerfReport struct {
+	Metrics map[string]float64 `json:"metrics"`
+	Vitals  WebVitals          `json:"vitals"`
+}
+
+func (b *Bridge) injectVitals(ctx context.Context) {
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		_, err := page.AddScriptToEvaluateOnNewDocument(WebVitalsJS).WithWorldName(vitalsWorldName).Do(ctx)
+		return err
+	})); err != nil {
+		slog.Warn("web vitals injection failed", "err", err)
+	}
+}
+
+// PagePerf collects performance counters and Web Vitals for the tab in ctx.
+func PagePerf(ctx context.Context) (PerfReport, error) {
+	var metrics []*performance.Metric
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := performance.Enable().Do(ctx); err != nil {
+			return err
+		}
+		var err error
+		metrics, err = performance.GetMetrics().Do(ctx)
+		return err
+	})); err != nil {
+		return PerfReport{}, fmt.Errorf("performance metrics: %w", err)
+	}
+
+	report := PerfReport{Metrics: make(map[string]float64, len(metrics))}
+	for _, m := range metrics {
+		report.Metrics[m.Name] = m.Value
+	}
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		// Chrome keeps one world per name and frame, so this finds the world
+		// the collector runs in (or makes an empty one in tabs opened before
+		// it was registered).
+		tree, err := page.GetFrameTree().Do(ctx)
+		if err != nil {
+			return err
+		}
+		world, err := page.CreateIsolatedWorld(tree.Frame.ID).WithWorldName(vitalsWorldName).Do(ctx)
+		if err != nil {
+			return err
+		}
+		obj, exc, err := runtime.Evaluate(readVitalsJS).WithContextID(world).WithReturnByValue(true).Do(ctx)
+		if err != nil {
+			return err
+		}
+		if exc != nil {
+			return fmt.Errorf("uncaught %s", exceptionMessage(exc))
+		}
+		return json.Unmarshal(obj.Value, &report.Vitals)
+	})); err != nil {
+		return PerfReport{}, fmt.Errorf("web vitals: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,31 @@
+package bridge
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWebVitalsJS_Observers(t *testing.T) {
+	for _, entry := range []string{"largest-contentful-paint", "layout-shift", "first-input", "event"} {
+		if !strings.Contains(WebVitalsJS, "'"+entry+"'") {
+			t.Errorf("missing %s observer", entry)
+		}
+	}
+	if !strings.Contains(WebVitalsJS, "Symbol.for('pinchtab.vitals')") || !strings.Contains(readVitalsJS, "Symbol.for('pinchtab.vitals')") {
+		t.Error("collector and reader must share the registered symbol")
+	}
+}
+
+func TestWebVitals_NullsStayNil(t *testing.T) {
+	var v WebVitals
+	if err := json.Unmarshal([]byte(`{"lcp":1200.5,"cls":0,"inp":null,"fid":null,"ttfb":210,"fcp":800}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.LCP == nil || *v.LCP != 1200.5 || v.CLS == nil || *v.CLS != 0 {
+		t.Errorf("expected lcp and a zero cls, got %+v", v)
+	}
+	if v.INP != nil || v.FID != nil {
+		t.Error("vitals that haven't happened should be nil")
+	}
+}
@@ -0,0 +1,166 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// PopupInfo describes a tab opened by another tab: target=_blank links,
+// window.open and OAuth popups.
+type PopupInfo struct {
+	TabID    string    `json:"tabId"`
+	OpenerID string    `json:"openerId"`
+	URL      string    `json:"url"`
+	OpenedAt time.Time `json:"openedAt"`
+}
+
+// maxPopupHistory bounds how many adopted popups are remembered for
+// PopupsSince and WaitForPopup.
+const maxPopupHistory = 100
+
+type popupLog struct {
+	mu      sync.Mutex
+	entries []PopupInfo
+	changed chan struct{} // closed and replaced whenever a popup is added
+}
+
+func newPopupLog() *popupLog {
+	return &popupLog{changed: make(chan struct{})}
+}
+
+func (l *popupLog) add(p PopupInfo) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, p)
+	if n := len(l.entries); n > maxPopupHistory {
+		l.entries = append([]PopupInfo(nil), l.entries[n-maxPopupHistory:]...)
+	}
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+func (l *popupLog) since(openerID string, t time.Time) []PopupInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []PopupInfo
+	for _, p := range l.entries {
+		if p.OpenerID == openerID && !p.OpenedAt.Before(t) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (l *popupLog) wait(ctx context.Context, openerID string, t time.Time) (PopupInfo, error) {
+	for {
+		l.mu.Lock()
+		for _, p := range l.entries {
+			if p.OpenerID == openerID && !p.OpenedAt.Before(t) {
+				l.mu.Unlock()
+				return p, nil
+			}
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return PopupInfo{}, fmt.Errorf("no popup opened by tab %s: %w", openerID, ctx.Err())
+		}
+	}
+}
+
+// PopupsSince lists popups opened by openerID at or after t.
+func (tm *TabManager) PopupsSince(openerID string, t time.Time) []PopupInfo {
+	return tm.popups.since(openerID, t)
+}
+
+// WaitForPopup blocks until openerID opens a popup at or after t, or ctx ends.
+func (tm *TabManager) WaitForPopup(ctx context.Context, openerID string, t time.Time) (PopupInfo, error) {
+	return tm.popups.wait(ctx, openerID, t)
+}
+
+// WatchPopups adopts pages opened by other pages as soon as Chrome reports
+// them, so they get the same setup as tabs created through the API instead
+// of waiting for someone to ask for them by ID.
+func (tm *TabManager) WatchPopups() {
+	if tm.browser() == nil {
+		return
+	}
+	chromedp.ListenBrowser(tm.browser(), func(ev any) {
+		e, ok := ev.(*target.EventTargetCreated)
+		if !ok || e.TargetInfo == nil || e.TargetInfo.Type != "page" || e.TargetInfo.OpenerID == "" {
+			return
+		}
+		// Listeners must not block the event loop; adopting sends CDP commands.
+		go tm.adoptPopup(*e.TargetInfo, time.Now())
+	})
+}
+
+// adoption is a popup whose tab context is still being set up.
+type adoption struct {
+	owner string
+	done  chan struct{}
+}
+
+// adoptPopup records the popup straight away, so the action that opened it
+// can report it, then sets its tab up like CreateTab does: outside tm.mu,
+// taking the lock only to insert the entry.
+func (tm *TabManager) adoptPopup(info target.Info, openedAt time.Time) {
+	tabID := string(info.TargetID)
+	openerID := string(info.OpenerID)
+
+	tm.mu.Lock()
+	if _, busy := tm.adopting[tabID]; busy {
+		tm.mu.Unlock()
+		return
+	}
+	if _, tracked := tm.tabs[tabID]; tracked {
+		tm.mu.Unlock()
+		tm.popups.add(PopupInfo{TabID: tabID, OpenerID: openerID, URL: info.URL, OpenedAt: openedAt})
+		return
+	}
+	// Popups belong to whoever owns the tab that opened them.
+	a := &adoption{done: make(chan struct{})}
+	if opener, ok := tm.tabs[openerID]; ok {
+		a.owner = opener.Owner
+	}
+	tm.adopting[tabID] = a
+	tm.mu.Unlock()
+
+	tm.popups.add(PopupInfo{TabID: tabID, OpenerID: openerID, URL: info.URL, OpenedAt: openedAt})
+
+	var entry *TabEntry
+	defer func() {
+		tm.mu.Lock()
+		if entry != nil {
+			tm.tabs[tabID] = entry
+			tm.accessed[tabID] = true
+		}
+		delete(tm.adopting, tabID)
+		tm.mu.Unlock()
+		close(a.done)
+	}()
+
+	ctx, cancel := chromedp.NewContext(tm.browser(), chromedp.WithTargetID(info.TargetID))
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		slog.Warn("adopt popup", "tab", tabID, "opener", openerID, "err", err)
+		return
+	}
+	if tm.onTabSetup != nil {
+		tm.onTabSetup(ctx)
+	}
+	tm.applyUserScripts(ctx, tabID, true)
+	tm.applyBlocking(ctx)
+
+	entry = &TabEntry{Ctx: ctx, Cancel: cancel, Owner: a.owner, Opener: openerID, LastUsed: openedAt}
+	slog.Info("popup adopted", "tab", tabID, "opener", openerID, "url", info.URL)
+}
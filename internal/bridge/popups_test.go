@@ -0,0 +1,82 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/target"
+)
+
+func TestPopupLog_Since(t *testing.T) {
+	l := newPopupLog()
+	start := time.Now()
+	l.add(PopupInfo{TabID: "old", OpenerID: "t1", OpenedAt: start.Add(-time.Second)})
+	l.add(PopupInfo{TabID: "p1", OpenerID: "t1", OpenedAt: start})
+	l.add(PopupInfo{TabID: "p2", OpenerID: "t2", OpenedAt: start.Add(time.Millisecond)})
+
+	got := l.since("t1", start)
+	if len(got) != 1 || got[0].TabID != "p1" {
+		t.Errorf("since(t1) = %+v", got)
+	}
+}
+
+func TestPopupLog_Trims(t *testing.T) {
+	l := newPopupLog()
+	now := time.Now()
+	for i := range maxPopupHistory + 10 {
+		l.add(PopupInfo{TabID: fmt.Sprint(i), OpenerID: "t1", OpenedAt: now})
+	}
+	got := l.since("t1", now)
+	if len(got) != maxPopupHistory || got[0].TabID != "10" {
+		t.Errorf("expected the newest %d popups, got %d starting at %s", maxPopupHistory, len(got), got[0].TabID)
+	}
+}
+
+func TestPopupLog_Wait(t *testing.T) {
+	l := newPopupLog()
+	start := time.Now()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		l.add(PopupInfo{TabID: "other", OpenerID: "t2", OpenedAt: time.Now()})
+		l.add(PopupInfo{TabID: "p1", OpenerID: "t1", OpenedAt: time.Now()})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	p, err := l.wait(ctx, "t1", start)
+	if err != nil || p.TabID != "p1" {
+		t.Fatalf("wait = %+v, %v", p, err)
+	}
+}
+
+func TestPopupLog_WaitTimeout(t *testing.T) {
+	l := newPopupLog()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.wait(ctx, "t1", time.Now()); err == nil {
+		t.Fatal("expected timeout")
+	}
+}
+
+func TestAdoptPopup_RecordsBeforeSetup(t *testing.T) {
+	b := newTestBridge()
+	b.browserCtx = context.Background() // no browser: attaching fails
+	b.tabs["opener"] = &TabEntry{Owner: "agent-a"}
+
+	b.adoptPopup(target.Info{TargetID: "popup1", OpenerID: "opener", URL: "https://a.test"}, time.Now())
+	if got := b.PopupsSince("opener", time.Time{}); len(got) != 1 || got[0].TabID != "popup1" {
+		t.Errorf("popup should be recorded even if setup fails, got %+v", got)
+	}
+	if _, ok := b.adopting["popup1"]; ok {
+		t.Error("adoption should be cleared")
+	}
+
+	// While a popup is set up it already belongs to its opener's owner.
+	b.adopting["popup2"] = &adoption{owner: "agent-a", done: make(chan struct{})}
+	if got := b.TabOwner("popup2"); got != "agent-a" {
+		t.Errorf("TabOwner during adoption = %q, want agent-a", got)
+	}
+}
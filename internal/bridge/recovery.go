@@ -0,0 +1,207 @@
+package bridge
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Recovery states reported in RecoveryStatus.State.
+const (
+	RecoveryOK         = "ok"
+	RecoveryInProgress = "recovering"
+)
+
+// RecoveryStatus describes browser crash recovery. TabMap maps tab IDs from
+// before a relaunch to the IDs of the tabs that replaced them.
+type RecoveryStatus struct {
+	State       string            `json:"state"`
+	Crashes     int               `json:"crashes"`
+	Attempts    int               `json:"attempts,omitempty"`
+	LastCrash   time.Time         `json:"lastCrash,omitzero"`
+	RecoveredAt time.Time         `json:"recoveredAt,omitzero"`
+	Error       string            `json:"error,omitempty"`
+	TabMap      map[string]string `json:"tabMap,omitempty"`
+}
+
+// knownTab is the last seen state of an open tab, kept so tabs can be
+// recreated once the browser that held them is gone.
+type knownTab struct {
+	ID    string
+	URL   string
+	Owner string
+	First bool // the browser's own first tab
+}
+
+type recoveryState struct {
+	mu     sync.Mutex
+	status RecoveryStatus
+	known  []knownTab
+}
+
+// RememberTabs records the open tabs for a later Recover. The supervisor
+// calls it periodically while the browser is healthy.
+func (b *Bridge) RememberTabs() {
+	targets, err := b.ListTargets()
+	if err != nil {
+		return
+	}
+	first := b.browser()
+	known := make([]knownTab, 0, len(targets))
+	for _, t := range targets {
+		id := string(t.TargetID)
+		k := knownTab{ID: id, URL: t.URL, Owner: b.TabOwner(id)}
+		b.mu.RLock()
+		if entry, ok := b.tabs[id]; ok && entry.Ctx == first {
+			k.First = true
+		}
+		b.mu.RUnlock()
+		known = append(known, k)
+	}
+
+	b.recovery.mu.Lock()
+	defer b.recovery.mu.Unlock()
+	if b.recovery.status.State != RecoveryInProgress {
+		b.recovery.known = known
+	}
+}
+
+// BeginRecovery marks the browser as lost.
+func (b *Bridge) BeginRecovery(cause error) {
+	b.recovery.mu.Lock()
+	defer b.recovery.mu.Unlock()
+	b.recovery.status.State = RecoveryInProgress
+	b.recovery.status.Crashes++
+	b.recovery.status.Attempts = 0
+	b.recovery.status.LastCrash = time.Now()
+	b.recovery.status.Error = cause.Error()
+}
+
+// RecoveryFailed records a failed relaunch attempt.
+func (b *Bridge) RecoveryFailed(err error) {
+	b.recovery.mu.Lock()
+	defer b.recovery.mu.Unlock()
+	b.recovery.status.Attempts++
+	b.recovery.status.Error = err.Error()
+}
+
+func (b *Bridge) RecoveryStatus() RecoveryStatus {
+	b.recovery.mu.Lock()
+	defer b.recovery.mu.Unlock()
+	s := b.recovery.status
+	if s.State == "" {
+		s.State = RecoveryOK
+	}
+	s.TabMap = maps.Clone(s.TabMap)
+	return s
+}
+
+// MapTabID translates a tab ID from before a browser relaunch to the tab that
+// replaced it. Other IDs are returned unchanged.
+func (b *Bridge) MapTabID(tabID string) string {
+	b.recovery.mu.Lock()
+	defer b.recovery.mu.Unlock()
+	if newID, ok := b.recovery.status.TabMap[tabID]; ok {
+		return newID
+	}
+	return tabID
+}
+
+// Recover moves the bridge onto a relaunched browser: tab state from the dead
+// one is dropped, the remembered tabs are reopened with their URLs and
+// owners, and locks and current tabs follow them to their new IDs.
+// firstTabID is the new browser's initial tab, or "" when there is none.
+func (b *Bridge) Recover(allocCtx, browserCtx context.Context, firstTabID string) map[string]string {
+	b.recovery.mu.Lock()
+	known := b.recovery.known
+	b.recovery.mu.Unlock()
+
+	b.AllocCtx = allocCtx
+	b.BrowserCtx = browserCtx
+	current := b.rebind(browserCtx)
+	if firstTabID != "" {
+		b.RegisterTab(firstTabID, browserCtx)
+	}
+	b.WatchPopups()
+	b.WatchCrashes()
+
+	ids := make(map[string]string, len(known))
+	for _, t := range known {
+		newID := ""
+		if t.First && firstTabID != "" {
+			newID = firstTabID
+			if !isTransientURL(t.URL) {
+				go func(url string) {
+					_ = chromedp.Run(browserCtx, chromedp.Navigate(url))
+				}(t.URL)
+			}
+		} else {
+			id, _, _, err := b.CreateTab(t.URL, "")
+			if err != nil {
+				slog.Warn("recover tab", "tab", t.ID, "url", t.URL, "err", err)
+				continue
+			}
+			newID = id
+		}
+		ids[t.ID] = newID
+		if t.Owner != "" {
+			b.SetTabOwner(newID, t.Owner)
+		}
+	}
+
+	for agent, oldID := range current {
+		if newID, ok := ids[oldID]; ok {
+			b.SetCurrentTab(agent, newID)
+		}
+	}
+	b.Locks.Rekey(ids)
+
+	b.recovery.mu.Lock()
+	defer b.recovery.mu.Unlock()
+	s := &b.recovery.status
+	// Keep IDs from earlier crashes pointing at the latest tabs.
+	tabMap := make(map[string]string, len(s.TabMap)+len(ids))
+	for oldID, id := range s.TabMap {
+		if newID, ok := ids[id]; ok {
+			tabMap[oldID] = newID
+		}
+	}
+	maps.Copy(tabMap, ids)
+	s.TabMap = tabMap
+	s.State = RecoveryOK
+	s.RecoveredAt = time.Now()
+	s.Error = ""
+	return ids
+}
+
+// rebind drops all state tied to the old browser and switches to browserCtx.
+// It returns the agents' current tabs so the caller can remap them.
+func (tm *TabManager) rebind(browserCtx context.Context) map[string]string {
+	tm.mu.Lock()
+	old := tm.tabs
+	current := tm.current
+	tm.tabs = make(map[string]*TabEntry)
+	tm.snapshots = make(map[string]*RefCache)
+	tm.accessed = make(map[string]bool)
+	tm.current = make(map[string]string)
+	for id := range old {
+		tm.dropQueueLocked(id)
+	}
+	tm.mu.Unlock()
+
+	for id, entry := range old {
+		if entry.Cancel != nil {
+			entry.Cancel()
+		}
+		tm.forgetScripts(id)
+	}
+
+	tm.browserMu.Lock()
+	tm.browserCtx = browserCtx
+	tm.browserMu.Unlock()
+	return current
+}
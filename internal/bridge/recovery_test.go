@@ -0,0 +1,91 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestRecoveryStatus_Lifecycle(t *testing.T) {
+	b := newTestBridge()
+	if s := b.RecoveryStatus(); s.State != RecoveryOK || s.Crashes != 0 {
+		t.Fatalf("unexpected initial status %+v", s)
+	}
+
+	b.BeginRecovery(errors.New("browser connection lost"))
+	b.RecoveryFailed(errors.New("chrome not found"))
+	s := b.RecoveryStatus()
+	if s.State != RecoveryInProgress || s.Crashes != 1 || s.Attempts != 1 || s.Error != "chrome not found" {
+		t.Errorf("unexpected status %+v", s)
+	}
+}
+
+func TestLockManager_Rekey(t *testing.T) {
+	lm := NewLockManager()
+	_ = lm.TryLock("old", "agent-a", time.Minute)
+	_ = lm.TryLock("other", "agent-b", time.Minute)
+
+	lm.Rekey(map[string]string{"old": "new"})
+	if lm.Get("old") != nil {
+		t.Error("old ID should no longer be locked")
+	}
+	if info := lm.Get("new"); info == nil || info.Owner != "agent-a" {
+		t.Errorf("expected agent-a lock on new, got %+v", info)
+	}
+	if lm.Get("other") == nil {
+		t.Error("unmapped lock should be kept")
+	}
+}
+
+func TestRecover_RemapsTabState(t *testing.T) {
+	b := newTestBridge()
+	b.config = &config.RuntimeConfig{}
+	b.Locks = NewLockManager()
+
+	b.RegisterTab("old1", context.Background())
+	b.SetTabOwner("old1", "agent-a")
+	b.SetCurrentTab("agent-a", "old1")
+	_ = b.Locks.TryLock("old1", "agent-a", time.Minute)
+	b.recovery.known = []knownTab{{ID: "old1", URL: "about:blank", Owner: "agent-a", First: true}}
+	b.BeginRecovery(errors.New("browser connection lost"))
+
+	browserCtx, cancel := chromedp.NewContext(context.Background())
+	defer cancel()
+	ids := b.Recover(context.Background(), browserCtx, "new1")
+
+	if ids["old1"] != "new1" {
+		t.Fatalf("expected old1 -> new1, got %v", ids)
+	}
+	if _, ok := b.tabs["old1"]; ok {
+		t.Error("old tab should be dropped")
+	}
+	if got := b.TabOwner("new1"); got != "agent-a" {
+		t.Errorf("owner not carried over: %q", got)
+	}
+	if got := b.CurrentTab("agent-a"); got != "new1" {
+		t.Errorf("current tab not remapped: %q", got)
+	}
+	if info := b.Locks.Get("new1"); info == nil || info.Owner != "agent-a" {
+		t.Errorf("lock not remapped: %+v", info)
+	}
+	if s := b.RecoveryStatus(); s.State != RecoveryOK || s.RecoveredAt.IsZero() {
+		t.Errorf("unexpected status %+v", s)
+	}
+	if got := b.MapTabID("old1"); got != "new1" {
+		t.Errorf("MapTabID(old1) = %q", got)
+	}
+
+	// A second crash keeps the oldest IDs pointing at the newest tabs.
+	b.recovery.known = []knownTab{{ID: "new1", URL: "about:blank", First: true}}
+	b.Recover(context.Background(), browserCtx, "new2")
+	if got := b.MapTabID("old1"); got != "new2" {
+		t.Errorf("MapTabID(old1) after second recovery = %q", got)
+	}
+	if got := b.MapTabID("unrelated"); got != "unrelated" {
+		t.Errorf("unknown IDs should pass through, got %q", got)
+	}
+}
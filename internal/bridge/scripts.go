@@ -0,0 +1,259 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrScriptNotFound is returned when removing a script that isn't registered.
+var ErrScriptNotFound = errors.New("script not found")
+
+// ErrInvalidScript is returned when a script to add has a bad name or no source.
+var ErrInvalidScript = errors.New("invalid script")
+
+var scriptNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
+// UserScript is a named script injected into every tab before page scripts
+// run. Match holds URL globs (`*` matches anything); empty means all URLs.
+type UserScript struct {
+	Name      string    `json:"name"`
+	Source    string    `json:"source"`
+	Match     []string  `json:"match,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// wrapped returns the source guarded by the URL patterns. The script runs in
+// its own function scope, so helpers must be attached to window explicitly.
+func (s *UserScript) wrapped() string {
+	var b strings.Builder
+	b.WriteString("(function() {\n")
+	if len(s.Match) > 0 {
+		res := make([]string, len(s.Match))
+		for i, m := range s.Match {
+			res[i] = globToRegexp(m)
+		}
+		pats, _ := json.Marshal(res)
+		fmt.Fprintf(&b, "if (!%s.some(function(p) { return new RegExp(p).test(location.href); })) return;\n", pats)
+	}
+	b.WriteString(s.Source)
+	b.WriteString("\n})();\n//# sourceURL=pinchtab-script:")
+	b.WriteString(s.Name)
+	return b.String()
+}
+
+func globToRegexp(glob string) string {
+	parts := strings.Split(glob, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return "^" + strings.Join(parts, ".*") + "$"
+}
+
+// scriptRegistry holds the registered user scripts and the CDP identifiers
+// they were installed under in each tab.
+type scriptRegistry struct {
+	mu      sync.Mutex
+	path    string
+	scripts map[string]*UserScript
+	applied map[string]map[string]page.ScriptIdentifier // tabID -> name -> identifier
+}
+
+func newScriptRegistry(stateDir string) *scriptRegistry {
+	r := &scriptRegistry{
+		scripts: make(map[string]*UserScript),
+		applied: make(map[string]map[string]page.ScriptIdentifier),
+	}
+	if stateDir == "" {
+		return r
+	}
+	r.path = filepath.Join(stateDir, "scripts.json")
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return r
+	}
+	var list []*UserScript
+	if err := json.Unmarshal(data, &list); err != nil {
+		slog.Warn("load user scripts", "path", r.path, "err", err)
+		return r
+	}
+	for _, s := range list {
+		r.scripts[s.Name] = s
+	}
+	return r
+}
+
+// listLocked returns the scripts sorted by name. Callers hold r.mu.
+func (r *scriptRegistry) listLocked() []UserScript {
+	out := make([]UserScript, 0, len(r.scripts))
+	for _, s := range r.scripts {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (r *scriptRegistry) saveLocked() error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.listLocked(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0600)
+}
+
+// UserScripts returns the registered scripts sorted by name.
+func (tm *TabManager) UserScripts() []UserScript {
+	tm.scripts.mu.Lock()
+	defer tm.scripts.mu.Unlock()
+	return tm.scripts.listLocked()
+}
+
+// AddUserScript registers (or replaces) a script, persists it and installs it
+// in every tracked tab, running it once in the current document as well.
+func (tm *TabManager) AddUserScript(s UserScript) (UserScript, error) {
+	if !scriptNameRe.MatchString(s.Name) {
+		return UserScript{}, fmt.Errorf("%w: bad name %q", ErrInvalidScript, s.Name)
+	}
+	if strings.TrimSpace(s.Source) == "" {
+		return UserScript{}, fmt.Errorf("%w: source required", ErrInvalidScript)
+	}
+	s.CreatedAt = time.Now().UTC()
+
+	reg := tm.scripts
+	reg.mu.Lock()
+	prev := reg.scripts[s.Name]
+	reg.scripts[s.Name] = &s
+	if err := reg.saveLocked(); err != nil {
+		if prev != nil {
+			reg.scripts[s.Name] = prev
+		} else {
+			delete(reg.scripts, s.Name)
+		}
+		reg.mu.Unlock()
+		return UserScript{}, fmt.Errorf("save scripts: %w", err)
+	}
+	reg.mu.Unlock()
+
+	for tabID, ctx := range tm.trackedTabs() {
+		if prev != nil {
+			tm.removeScriptFromTab(ctx, tabID, s.Name)
+		}
+		tm.installScript(ctx, tabID, &s, true)
+	}
+	return s, nil
+}
+
+// RemoveUserScript unregisters a script and uninstalls it from tracked tabs.
+// Documents it already ran in keep its effects until they reload.
+func (tm *TabManager) RemoveUserScript(name string) error {
+	reg := tm.scripts
+	reg.mu.Lock()
+	prev, ok := reg.scripts[name]
+	if !ok {
+		reg.mu.Unlock()
+		return ErrScriptNotFound
+	}
+	delete(reg.scripts, name)
+	if err := reg.saveLocked(); err != nil {
+		reg.scripts[name] = prev
+		reg.mu.Unlock()
+		return fmt.Errorf("save scripts: %w", err)
+	}
+	reg.mu.Unlock()
+
+	for tabID, ctx := range tm.trackedTabs() {
+		tm.removeScriptFromTab(ctx, tabID, name)
+	}
+	return nil
+}
+
+// applyUserScripts installs every registered script in a newly created or
+// adopted tab. New documents get them via Page.addScriptToEvaluateOnNewDocument;
+// adopted tabs also run them in the document that is already loaded.
+func (tm *TabManager) applyUserScripts(ctx context.Context, tabID string, runNow bool) {
+	for _, s := range tm.UserScripts() {
+		tm.installScript(ctx, tabID, &s, runNow)
+	}
+}
+
+func (tm *TabManager) installScript(ctx context.Context, tabID string, s *UserScript, runNow bool) {
+	src := s.wrapped()
+	var id page.ScriptIdentifier
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
+		var err error
+		id, err = page.AddScriptToEvaluateOnNewDocument(src).Do(c)
+		return err
+	}))
+	if err != nil {
+		slog.Warn("user script install failed", "script", s.Name, "tab", tabID, "err", err)
+		return
+	}
+
+	reg := tm.scripts
+	reg.mu.Lock()
+	if reg.applied[tabID] == nil {
+		reg.applied[tabID] = make(map[string]page.ScriptIdentifier)
+	}
+	reg.applied[tabID][s.Name] = id
+	reg.mu.Unlock()
+
+	if runNow {
+		rctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := chromedp.Run(rctx, chromedp.Evaluate(src, nil)); err != nil {
+			slog.Debug("user script run failed", "script", s.Name, "tab", tabID, "err", err)
+		}
+	}
+}
+
+func (tm *TabManager) removeScriptFromTab(ctx context.Context, tabID, name string) {
+	reg := tm.scripts
+	reg.mu.Lock()
+	id, ok := reg.applied[tabID][name]
+	delete(reg.applied[tabID], name)
+	reg.mu.Unlock()
+	if !ok {
+		return
+	}
+	_ = chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
+		return page.RemoveScriptToEvaluateOnNewDocument(id).Do(c)
+	}))
+}
+
+// forgetScripts drops the identifiers recorded for a closed tab.
+func (tm *TabManager) forgetScripts(tabID string) {
+	tm.scripts.mu.Lock()
+	delete(tm.scripts.applied, tabID)
+	tm.scripts.mu.Unlock()
+}
+
+// trackedTabs returns the contexts of all tabs the manager holds.
+func (tm *TabManager) trackedTabs() map[string]context.Context {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	out := make(map[string]context.Context, len(tm.tabs))
+	for id, entry := range tm.tabs {
+		if entry.Ctx != nil {
+			out[id] = entry.Ctx
+		}
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	re := regexp.MustCompile(globToRegexp("https://*.example.com/*"))
+	for url, want := range map[string]bool{
+		"https://shop.example.com/cart": true,
+		"https://example.com/":          false,
+		"https://shop.example.co/cart":  false,
+		"http://shop.example.com/":      false,
+	} {
+		if got := re.MatchString(url); got != want {
+			t.Errorf("%s: got %v, want %v", url, got, want)
+		}
+	}
+}
+
+func TestUserScriptWrapped(t *testing.T) {
+	s := UserScript{Name: "banner", Source: "window.x = 1;", Match: []string{"https://a.test/*"}}
+	src := s.wrapped()
+	if !strings.Contains(src, "window.x = 1;") || !strings.Contains(src, "location.href") {
+		t.Errorf("unexpected wrapper:\n%s", src)
+	}
+	if !strings.Contains(src, "sourceURL=pinchtab-script:banner") {
+		t.Error("missing sourceURL")
+	}
+
+	all := UserScript{Name: "all", Source: "1"}
+	if strings.Contains(all.wrapped(), "location.href") {
+		t.Error("script without patterns should not be guarded")
+	}
+}
+
+func TestUserScriptsPersist(t *testing.T) {
+	dir := t.TempDir()
+	tm := NewTabManager(context.TODO(), &config.RuntimeConfig{StateDir: dir}, nil)
+
+	if _, err := tm.AddUserScript(UserScript{Name: "bad name", Source: "1"}); err == nil {
+		t.Error("expected invalid name error")
+	}
+	if _, err := tm.AddUserScript(UserScript{Name: "empty", Source: "  "}); err == nil {
+		t.Error("expected source error")
+	}
+	if _, err := tm.AddUserScript(UserScript{Name: "b", Source: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.AddUserScript(UserScript{Name: "a", Source: "1", Match: []string{"*"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.AddUserScript(UserScript{Name: "b", Source: "3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := NewTabManager(context.TODO(), &config.RuntimeConfig{StateDir: dir}, nil)
+	list := reloaded.UserScripts()
+	if len(list) != 2 || list[0].Name != "a" || list[1].Source != "3" {
+		t.Fatalf("unexpected scripts after reload: %+v", list)
+	}
+
+	if err := reloaded.RemoveUserScript("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloaded.RemoveUserScript("a"); !errors.Is(err, ErrScriptNotFound) {
+		t.Errorf("expected ErrScriptNotFound, got %v", err)
+	}
+	if n := len(NewTabManager(context.TODO(), &config.RuntimeConfig{StateDir: dir}, nil).UserScripts()); n != 1 {
+		t.Errorf("expected 1 script on disk, got %d", n)
+	}
+}
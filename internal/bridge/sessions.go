@@ -0,0 +1,414 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrSessionNotFound is returned for a named session that was never saved.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrInvalidSessionName is returned for names that can't be used as a file name.
+var ErrInvalidSessionName = errors.New("invalid session name (letters, digits, '.', '_' or '-')")
+
+var sessionNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
+// AutosaveSession is the name periodic autosaves are written under.
+const AutosaveSession = "autosave"
+
+// sessionCaptureTimeout bounds reading scroll and storage from one tab, so a
+// hung page can't stall a save.
+const sessionCaptureTimeout = 5 * time.Second
+
+// SessionOptions picks what SaveSession records beyond tab URLs, titles and
+// scroll positions.
+type SessionOptions struct {
+	Cookies bool   `json:"cookies,omitempty"`
+	Storage bool   `json:"storage,omitempty"` // localStorage and sessionStorage per tab
+	Owner   string `json:"-"`                 // agent saving the session, set by the caller's handler
+	// TabCookies limits Cookies to those the saved tabs' pages would send,
+	// instead of the whole browser's jar.
+	TabCookies bool `json:"-"`
+}
+
+// SessionInfo summarises a saved session for listings.
+type SessionInfo struct {
+	Name    string `json:"name"`
+	Owner   string `json:"owner,omitempty"`
+	Tabs    int    `json:"tabs"`
+	Cookies int    `json:"cookies,omitempty"`
+	SavedAt string `json:"savedAt"`
+}
+
+// RestoredTab reports one tab reopened by RestoreSession. A tab whose page
+// failed to load stays open and carries the error.
+type RestoredTab struct {
+	TabID string `json:"tabId"`
+	URL   string `json:"url"`
+	Error string `json:"error,omitempty"`
+}
+
+// sessionURL reports whether a page is worth saving in a named session.
+// Unlike the shutdown state, local development servers are kept.
+func sessionURL(url string) bool {
+	return url != "" &&
+		!strings.HasPrefix(url, "about:") &&
+		!strings.HasPrefix(url, "chrome://") &&
+		!strings.HasPrefix(url, "chrome-extension://") &&
+		!strings.HasPrefix(url, "devtools://")
+}
+
+func (b *Bridge) sessionPath(name string) (string, error) {
+	if !sessionNameRe.MatchString(name) {
+		return "", ErrInvalidSessionName
+	}
+	return filepath.Join(b.Config.StateDir, "sessions", name+".json"), nil
+}
+
+// trackedContext returns the context of a tab the manager already holds, or
+// nil. Unlike TabContext it doesn't count as using the tab.
+func (tm *TabManager) trackedContext(tabID string) context.Context {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if entry, ok := tm.tabs[tabID]; ok {
+		return entry.Ctx
+	}
+	return nil
+}
+
+// tabCookies returns the cookies the given tabs' pages would send, read
+// through the first of them that is tracked.
+func (b *Bridge) tabCookies(tabs []TabState) ([]*network.Cookie, error) {
+	var tabCtx context.Context
+	urls := make([]string, 0, len(tabs))
+	for _, t := range tabs {
+		urls = append(urls, t.URL)
+		if tabCtx == nil {
+			tabCtx = b.trackedContext(t.ID)
+		}
+	}
+	if tabCtx == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(tabCtx, 10*time.Second)
+	defer cancel()
+	var cookies []*network.Cookie
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		cookies, err = network.GetCookies().WithURLs(urls).Do(ctx)
+		return err
+	}))
+	return cookies, err
+}
+
+// SaveSession writes the given tabs (all tabs when tabIDs is nil) to a named
+// session file under StateDir/sessions, replacing any earlier save.
+func (b *Bridge) SaveSession(name string, tabIDs []string, opts SessionOptions) (SessionState, error) {
+	path, err := b.sessionPath(name)
+	if err != nil {
+		return SessionState{}, err
+	}
+	targets, err := b.ListTargets()
+	if err != nil {
+		return SessionState{}, fmt.Errorf("list targets: %w", err)
+	}
+
+	var want map[string]bool
+	if tabIDs != nil {
+		want = make(map[string]bool, len(tabIDs))
+		for _, id := range tabIDs {
+			want[id] = true
+		}
+	}
+
+	state := SessionState{Name: name, Owner: opts.Owner, Tabs: make([]TabState, 0, len(targets))}
+	for _, t := range targets {
+		id := string(t.TargetID)
+		if (want != nil && !want[id]) || !sessionURL(t.URL) {
+			continue
+		}
+		tab := TabState{ID: id, URL: t.URL, Title: t.Title}
+		if ctx := b.trackedContext(id); ctx != nil {
+			captureTab(ctx, &tab, opts.Storage)
+		}
+		state.Tabs = append(state.Tabs, tab)
+	}
+
+	if opts.Cookies && opts.TabCookies {
+		cookies, err := b.tabCookies(state.Tabs)
+		if err != nil {
+			return SessionState{}, fmt.Errorf("get cookies: %w", err)
+		}
+		state.Cookies = cookies
+	} else if opts.Cookies {
+		ctx, cancel := context.WithTimeout(b.browser(), 10*time.Second)
+		cookies, err := storage.GetCookies().Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Browser))
+		cancel()
+		if err != nil {
+			return SessionState{}, fmt.Errorf("get cookies: %w", err)
+		}
+		state.Cookies = cookies
+	}
+
+	state.SavedAt = time.Now().UTC().Format(time.RFC3339)
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return SessionState{}, fmt.Errorf("marshal session: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return SessionState{}, fmt.Errorf("create sessions dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return SessionState{}, fmt.Errorf("write session: %w", err)
+	}
+	return state, nil
+}
+
+const captureTabJS = `(() => {
+  const dump = (name) => {
+    try {
+      const s = window[name], o = {};
+      for (let i = 0; i < s.length; i++) { const k = s.key(i); o[k] = s.getItem(k); }
+      return o;
+    } catch (e) { return null; }
+  };
+  return {x: scrollX, y: scrollY, local: %[1]t ? dump('localStorage') : null, session: %[1]t ? dump('sessionStorage') : null};
+})()`
+
+// captureTab records scroll position and, when asked, web storage. Pages
+// that don't answer are saved by URL alone.
+func captureTab(tabCtx context.Context, tab *TabState, withStorage bool) {
+	ctx, cancel := context.WithTimeout(tabCtx, sessionCaptureTimeout)
+	defer cancel()
+	var res struct {
+		X       float64           `json:"x"`
+		Y       float64           `json:"y"`
+		Local   map[string]string `json:"local"`
+		Session map[string]string `json:"session"`
+	}
+	if err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(captureTabJS, withStorage), &res)); err != nil {
+		slog.Debug("capture tab for session", "tab", tab.ID, "err", err)
+		return
+	}
+	tab.ScrollX, tab.ScrollY = res.X, res.Y
+	tab.LocalStorage, tab.SessionStorage = res.Local, res.Session
+}
+
+// LoadSession reads a saved session.
+func (b *Bridge) LoadSession(name string) (SessionState, error) {
+	path, err := b.sessionPath(name)
+	if err != nil {
+		return SessionState{}, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return SessionState{}, fmt.Errorf("%w: %s", ErrSessionNotFound, name)
+	}
+	if err != nil {
+		return SessionState{}, fmt.Errorf("read session: %w", err)
+	}
+	var state SessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return SessionState{}, fmt.Errorf("parse session %s: %w", name, err)
+	}
+	if state.Name == "" {
+		state.Name = name
+	}
+	return state, nil
+}
+
+// ListSessions returns the saved sessions sorted by name.
+func (b *Bridge) ListSessions() ([]SessionInfo, error) {
+	entries, err := os.ReadDir(filepath.Join(b.Config.StateDir, "sessions"))
+	if errors.Is(err, os.ErrNotExist) {
+		return []SessionInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read sessions dir: %w", err)
+	}
+
+	out := make([]SessionInfo, 0, len(entries))
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		state, err := b.LoadSession(name)
+		if err != nil {
+			slog.Warn("skip session", "name", name, "err", err)
+			continue
+		}
+		out = append(out, SessionInfo{Name: name, Owner: state.Owner, Tabs: len(state.Tabs), Cookies: len(state.Cookies), SavedAt: state.SavedAt})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// DeleteSession removes a saved session.
+func (b *Bridge) DeleteSession(name string) error {
+	path, err := b.sessionPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrSessionNotFound, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// RestoreSession sets the session's cookies and reopens its tabs in new
+// tabs, each loaded with its web storage and scrolled to where it was. It
+// returns once every page has loaded or failed.
+func (b *Bridge) RestoreSession(name string) ([]RestoredTab, error) {
+	state, err := b.LoadSession(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(state.Cookies) > 0 {
+		ctx, cancel := context.WithTimeout(b.browser(), 10*time.Second)
+		err := storage.SetCookies(cookieParams(state.Cookies)).Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Browser))
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("set cookies: %w", err)
+		}
+	}
+
+	const maxConcurrentNavs = 3
+	navSem := make(chan struct{}, maxConcurrentNavs)
+	var wg sync.WaitGroup
+
+	out := make([]RestoredTab, 0, len(state.Tabs))
+	for _, tab := range state.Tabs {
+		id, ctx, _, err := b.CreateTab("about:blank", "")
+		if err != nil {
+			// Usually the tab limit; the remaining tabs won't fit either.
+			wg.Wait()
+			return out, fmt.Errorf("open tab for %s: %w", tab.URL, err)
+		}
+		// out has room for every tab, so res stays valid as it grows.
+		out = append(out, RestoredTab{TabID: id, URL: tab.URL})
+		res := &out[len(out)-1]
+
+		wg.Go(func() {
+			navSem <- struct{}{}
+			defer func() { <-navSem }()
+			if err := b.loadSessionTab(ctx, tab); err != nil {
+				res.Error = err.Error()
+			}
+		})
+	}
+	wg.Wait()
+	return out, nil
+}
+
+const restoreStorageJS = `((local, session) => {
+  for (const [k, v] of Object.entries(local || {})) localStorage.setItem(k, v);
+  for (const [k, v] of Object.entries(session || {})) sessionStorage.setItem(k, v);
+})(%s, %s)`
+
+// loadSessionTab navigates a fresh tab to a saved page. Storage can only be
+// written once the origin is loaded, so the page is reloaded afterwards to
+// let its scripts see it.
+func (b *Bridge) loadSessionTab(tabCtx context.Context, tab TabState) error {
+	ctx, cancel := context.WithTimeout(tabCtx, b.Config.NavigateTimeout)
+	defer cancel()
+
+	if err := NavigatePage(ctx, tab.URL); err != nil {
+		return fmt.Errorf("navigate: %w", err)
+	}
+	if len(tab.LocalStorage) > 0 || len(tab.SessionStorage) > 0 {
+		local, _ := json.Marshal(tab.LocalStorage)
+		session, _ := json.Marshal(tab.SessionStorage)
+		if err := chromedp.Run(ctx,
+			chromedp.Evaluate(fmt.Sprintf(restoreStorageJS, local, session), nil),
+			chromedp.Reload(),
+		); err != nil {
+			return fmt.Errorf("restore storage: %w", err)
+		}
+	}
+	if tab.ScrollX != 0 || tab.ScrollY != 0 {
+		if err := chromedp.Run(ctx,
+			chromedp.Evaluate(fmt.Sprintf(`window.scrollTo(%g, %g)`, tab.ScrollX, tab.ScrollY), nil),
+		); err != nil {
+			return fmt.Errorf("restore scroll: %w", err)
+		}
+	}
+	return nil
+}
+
+func cookieParams(cookies []*network.Cookie) []*network.CookieParam {
+	params := make([]*network.CookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		p := &network.CookieParam{
+			Name:         c.Name,
+			Value:        c.Value,
+			Domain:       c.Domain,
+			Path:         c.Path,
+			Secure:       c.Secure,
+			HTTPOnly:     c.HTTPOnly,
+			SameSite:     c.SameSite,
+			Priority:     c.Priority,
+			SourceScheme: c.SourceScheme,
+			SourcePort:   c.SourcePort,
+			PartitionKey: c.PartitionKey,
+		}
+		if !c.Session && c.Expires > 0 {
+			expires := cdp.TimeSinceEpoch(time.Unix(int64(c.Expires), 0))
+			p.Expires = &expires
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// AutosaveSessions saves all tabs as the "autosave" session every interval
+// until ctx is done, so a crash loses at most one interval of browsing. An
+// empty tab set doesn't overwrite the last good save.
+func (b *Bridge) AutosaveSessions(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		targets, err := b.ListTargets()
+		if err != nil {
+			continue
+		}
+		var ids []string
+		for _, t := range targets {
+			if sessionURL(t.URL) {
+				ids = append(ids, string(t.TargetID))
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if _, err := b.SaveSession(AutosaveSession, ids, SessionOptions{}); err != nil {
+			slog.Warn("autosave session", "err", err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package bridge
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func writeTestSession(t *testing.T, dir string, state SessionState) {
+	t.Helper()
+	data, _ := json.Marshal(state)
+	_ = os.MkdirAll(filepath.Join(dir, "sessions"), 0755)
+	if err := os.WriteFile(filepath.Join(dir, "sessions", state.Name+".json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessions_LoadListDelete(t *testing.T) {
+	dir := t.TempDir()
+	b := &Bridge{Config: &config.RuntimeConfig{StateDir: dir}}
+
+	list, err := b.ListSessions()
+	if err != nil || len(list) != 0 {
+		t.Fatalf("expected no sessions, got %v %v", list, err)
+	}
+
+	writeTestSession(t, dir, SessionState{Name: "research", SavedAt: "2026-01-01T00:00:00Z", Tabs: []TabState{
+		{URL: "https://example.com", ScrollY: 400, LocalStorage: map[string]string{"k": "v"}},
+		{URL: "https://example.org"},
+	}, Cookies: []*network.Cookie{{Name: "sid", Value: "1", Domain: "example.com",
+		Priority: network.CookiePriorityMedium, SourceScheme: network.CookieSourceSchemeSecure}}})
+	writeTestSession(t, dir, SessionState{Name: "autosave", Tabs: []TabState{{URL: "https://a.test"}}})
+
+	list, err = b.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "autosave" || list[1].Tabs != 2 || list[1].Cookies != 1 {
+		t.Errorf("unexpected listing: %+v", list)
+	}
+
+	state, err := b.LoadSession("research")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Tabs[0].ScrollY != 400 || state.Tabs[0].LocalStorage["k"] != "v" {
+		t.Errorf("tab state not round-tripped: %+v", state.Tabs[0])
+	}
+
+	if err := b.DeleteSession("research"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.LoadSession("research"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+	if err := b.DeleteSession("research"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound on second delete, got %v", err)
+	}
+}
+
+func TestSessions_InvalidName(t *testing.T) {
+	b := &Bridge{Config: &config.RuntimeConfig{StateDir: t.TempDir()}}
+	for _, name := range []string{"", "../etc", ".hidden", "a/b"} {
+		if _, err := b.LoadSession(name); !errors.Is(err, ErrInvalidSessionName) {
+			t.Errorf("%q: expected ErrInvalidSessionName, got %v", name, err)
+		}
+	}
+}
+
+func TestSessionURL(t *testing.T) {
+	for url, want := range map[string]bool{
+		"https://example.com":    true,
+		"http://localhost:3000/": true,
+		"about:blank":            false,
+		"chrome://newtab/":       false,
+		"":                       false,
+	} {
+		if got := sessionURL(url); got != want {
+			t.Errorf("sessionURL(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
+
+func TestCookieParams(t *testing.T) {
+	params := cookieParams([]*network.Cookie{
+		{Name: "a", Value: "1", Domain: "x.test", Path: "/", Expires: 1900000000, HTTPOnly: true},
+		{Name: "b", Value: "2", Domain: "x.test", Session: true, Expires: -1},
+	})
+	if len(params) != 2 {
+		t.Fatalf("expected 2 params, got %d", len(params))
+	}
+	if params[0].Expires == nil || !params[0].HTTPOnly {
+		t.Errorf("persistent cookie lost fields: %+v", params[0])
+	}
+	if params[1].Expires != nil {
+		t.Error("session cookie must not get an expiry")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
 )
 
@@ -23,11 +24,21 @@ type TabState struct {
 	ID    string `json:"id"`
 	URL   string `json:"url"`
 	Title string `json:"title"`
+
+	// Named sessions also record where the page was and, optionally, its
+	// web storage.
+	ScrollX        float64           `json:"scrollX,omitempty"`
+	ScrollY        float64           `json:"scrollY,omitempty"`
+	LocalStorage   map[string]string `json:"localStorage,omitempty"`
+	SessionStorage map[string]string `json:"sessionStorage,omitempty"`
 }
 
 type SessionState struct {
-	Tabs    []TabState `json:"tabs"`
-	SavedAt string     `json:"savedAt"`
+	Name    string            `json:"name,omitempty"`
+	Owner   string            `json:"owner,omitempty"` // agent that saved a named session
+	Tabs    []TabState        `json:"tabs"`
+	Cookies []*network.Cookie `json:"cookies,omitempty"`
+	SavedAt string            `json:"savedAt"`
 }
 
 func isTransientURL(url string) bool {
@@ -158,7 +169,7 @@ func (b *Bridge) RestoreState() {
 			time.Sleep(200 * time.Millisecond)
 		}
 
-		ctx, cancel := chromedp.NewContext(b.BrowserCtx)
+		ctx, cancel := chromedp.NewContext(b.browser())
 
 		if err := chromedp.Run(ctx); err != nil {
 			cancel()
@@ -169,8 +180,9 @@ func (b *Bridge) RestoreState() {
 
 		newID := string(chromedp.FromContext(ctx).Target.TargetID)
 		b.tabSetup(ctx)
+		b.applyUserScripts(ctx, newID, false)
 		b.mu.Lock()
-		b.tabs[newID] = &TabEntry{Ctx: ctx, Cancel: cancel}
+		b.tabs[newID] = &TabEntry{Ctx: ctx, Cancel: cancel, LastUsed: time.Now()}
 		b.mu.Unlock()
 		restored++
 
@@ -0,0 +1,238 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrTabCrashed is the cancellation cause of requests that were running in a
+// tab when its renderer crashed or stopped responding.
+var ErrTabCrashed = errors.New("tab crashed")
+
+// Reasons a tab is marked crashed.
+const (
+	CrashRenderer     = "renderer crashed"
+	CrashUnresponsive = "page unresponsive"
+)
+
+// TabCrash records a renderer crash or hang in a tab that is still open.
+type TabCrash struct {
+	TabID  string    `json:"tabId"`
+	Reason string    `json:"reason"`
+	URL    string    `json:"url,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// SetCrashHandler registers fn to be told about every tab that crashes.
+func (tm *TabManager) SetCrashHandler(fn func(TabCrash)) {
+	tm.mu.Lock()
+	tm.onCrash = fn
+	tm.mu.Unlock()
+}
+
+// opsContextLocked returns the context requests on a tab run in. It is a
+// child of the tab context so markCrashed can fail in-flight work with
+// ErrTabCrashed; the tab context itself must stay alive, since cancelling it
+// closes the target. While the tab is marked crashed, new requests get a
+// context that has already failed the same way. Callers hold tm.mu.
+func (tm *TabManager) opsContextLocked(entry *TabEntry) context.Context {
+	if entry.Crash != nil {
+		ctx, cancel := context.WithCancelCause(entry.Ctx)
+		cancel(fmt.Errorf("%w (%s)", ErrTabCrashed, entry.Crash.Reason))
+		return ctx
+	}
+	if entry.ops == nil {
+		entry.ops, entry.opsCancel = context.WithCancelCause(entry.Ctx)
+	}
+	return entry.ops
+}
+
+// TabCrash returns the crash recorded for a tab, or nil if it is healthy.
+func (tm *TabManager) TabCrash(tabID string) *TabCrash {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	entry, ok := tm.tabs[tabID]
+	if !ok || entry.Crash == nil {
+		return nil
+	}
+	c := *entry.Crash
+	return &c
+}
+
+// ClearTabCrash marks a tab healthy again, before it is navigated away from
+// the crash or once it answers again.
+func (tm *TabManager) ClearTabCrash(tabID string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if entry, ok := tm.tabs[tabID]; ok {
+		entry.Crash = nil
+	}
+}
+
+// WatchCrashes marks tabs crashed as soon as Chrome reports their renderer
+// gone (Target.targetCrashed, the browser-wide form of
+// Inspector.targetCrashed).
+func (tm *TabManager) WatchCrashes() {
+	if tm.browser() == nil {
+		return
+	}
+	chromedp.ListenBrowser(tm.browser(), func(ev any) {
+		e, ok := ev.(*target.EventTargetCrashed)
+		if !ok {
+			return
+		}
+		go tm.markCrashed(string(e.TargetID), CrashRenderer)
+	})
+}
+
+// WatchHangs checks every tracked tab each timeout period and marks the ones
+// whose page doesn't answer within timeout as unresponsive. A page blocked by
+// a JavaScript dialog or a long synchronous script can't answer either, which
+// is why the watcher is opt-in. Tabs marked unresponsive keep being probed
+// and are cleared as soon as their page answers again.
+func (tm *TabManager) WatchHangs(ctx context.Context, timeout time.Duration) {
+	ticker := time.NewTicker(timeout)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		tm.probeTabs(timeout)
+	}
+}
+
+func (tm *TabManager) probeTabs(timeout time.Duration) {
+	type probe struct {
+		ctx  context.Context
+		hung bool // already marked unresponsive
+	}
+	tm.mu.RLock()
+	probes := make(map[string]probe, len(tm.tabs))
+	for id, entry := range tm.tabs {
+		switch {
+		case entry.Ctx == nil:
+		case entry.Crash == nil:
+			probes[id] = probe{ctx: entry.Ctx}
+		case entry.Crash.Reason == CrashUnresponsive:
+			probes[id] = probe{ctx: entry.Ctx, hung: true}
+		}
+	}
+	tm.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for id, p := range probes {
+		wg.Go(func() {
+			err := evalProbe(p.ctx, timeout)
+			switch {
+			case p.hung && err == nil:
+				tm.ClearTabCrash(id)
+				slog.Info("tab responsive again", "tab", id)
+			case !p.hung && hung(p.ctx, err):
+				tm.markCrashed(id, CrashUnresponsive)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// evalProbe runs a trivial script in the page's main world. When the probe
+// runs out of time it says so with context.DeadlineExceeded, whatever error
+// the CDP call ended with.
+func evalProbe(tabCtx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(tabCtx, timeout)
+	defer cancel()
+	var one int
+	err := chromedp.Run(ctx, chromedp.Evaluate(`1`, &one))
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+// hung reports true only when the probe ran out of time. Other failures, such
+// as the tab closing or a navigation in progress, are left to the request
+// paths.
+func hung(tabCtx context.Context, err error) bool {
+	return err != nil && tabCtx.Err() == nil && errors.Is(err, context.DeadlineExceeded)
+}
+
+// markCrashed records a crash, fails the tab's in-flight requests with
+// ErrTabCrashed and drops its ref cache, whose node IDs died with the
+// renderer. With TabAutoReload the tab is then reloaded to its last URL.
+func (tm *TabManager) markCrashed(tabID, reason string) {
+	tm.mu.Lock()
+	entry, ok := tm.tabs[tabID]
+	if !ok || entry.Crash != nil {
+		tm.mu.Unlock()
+		return
+	}
+	crash := TabCrash{TabID: tabID, Reason: reason, At: time.Now()}
+	recorded := crash
+	entry.Crash = &recorded
+	if entry.opsCancel != nil {
+		entry.opsCancel(fmt.Errorf("%w (%s)", ErrTabCrashed, reason))
+		entry.ops, entry.opsCancel = nil, nil
+	}
+	delete(tm.snapshots, tabID)
+	onCrash := tm.onCrash
+	tm.mu.Unlock()
+
+	if targets, err := tm.ListTargets(); err == nil {
+		for _, t := range targets {
+			if string(t.TargetID) == tabID {
+				crash.URL = t.URL
+			}
+		}
+	}
+	tm.mu.Lock()
+	if entry.Crash != nil {
+		entry.Crash.URL = crash.URL
+	}
+	tm.mu.Unlock()
+
+	slog.Warn("tab crashed", "tab", tabID, "reason", reason, "url", crash.URL)
+	if onCrash != nil {
+		onCrash(crash)
+	}
+	if tm.config.TabAutoReload {
+		tm.reloadCrashed(tabID, crash.URL)
+	}
+}
+
+// reloadCrashed brings a crashed tab back on the page it was showing.
+func (tm *TabManager) reloadCrashed(tabID, url string) {
+	tm.mu.RLock()
+	entry, ok := tm.tabs[tabID]
+	tm.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(entry.Ctx, tm.config.NavigateTimeout)
+	defer cancel()
+	var err error
+	if url == "" {
+		err = chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return page.Reload().Do(ctx)
+		}))
+	} else {
+		err = NavigatePage(ctx, url)
+	}
+	if err != nil {
+		slog.Warn("reload crashed tab", "tab", tabID, "url", url, "err", err)
+		return
+	}
+	tm.ClearTabCrash(tabID)
+	slog.Info("crashed tab reloaded", "tab", tabID, "url", url)
+}
@@ -0,0 +1,91 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestMarkCrashed_FailsInFlightRequests(t *testing.T) {
+	b := newTestBridge()
+	b.config = &config.RuntimeConfig{}
+	tabCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b.tabs["t1"] = &TabEntry{Ctx: tabCtx}
+	b.snapshots["t1"] = &RefCache{Refs: map[string]int64{"e0": 1}}
+
+	var reported []TabCrash
+	b.SetCrashHandler(func(c TabCrash) { reported = append(reported, c) })
+
+	ctx, _, err := b.TabContext("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.markCrashed("t1", CrashRenderer)
+
+	if !errors.Is(context.Cause(ctx), ErrTabCrashed) {
+		t.Errorf("expected in-flight ctx cancelled with ErrTabCrashed, got %v", context.Cause(ctx))
+	}
+	if tabCtx.Err() != nil {
+		t.Error("tab context must stay alive")
+	}
+	if b.GetRefCache("t1") != nil {
+		t.Error("expected ref cache dropped")
+	}
+	if c := b.TabCrash("t1"); c == nil || c.Reason != CrashRenderer {
+		t.Errorf("expected crash recorded, got %+v", c)
+	}
+
+	// A second report of the same crash is ignored.
+	b.markCrashed("t1", CrashUnresponsive)
+	if len(reported) != 1 {
+		t.Errorf("expected one crash reported, got %d", len(reported))
+	}
+
+	// New requests fail the same way until the crash is cleared.
+	ctx2, _, err := b.TabContext("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(context.Cause(ctx2), ErrTabCrashed) {
+		t.Errorf("expected new requests to fail while crashed, got %v", context.Cause(ctx2))
+	}
+
+	b.ClearTabCrash("t1")
+	if b.TabCrash("t1") != nil {
+		t.Error("expected crash cleared")
+	}
+	ctx3, _, err := b.TabContext("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx3.Err() != nil {
+		t.Error("expected a live context once the crash is cleared")
+	}
+}
+
+func TestHung(t *testing.T) {
+	alive := context.Background()
+	gone, cancel := context.WithCancel(context.Background())
+	cancel()
+	if !hung(alive, context.DeadlineExceeded) {
+		t.Error("a timed-out probe on a live tab is a hang")
+	}
+	if hung(alive, nil) || hung(alive, errors.New("navigating")) {
+		t.Error("only timeouts count as hangs")
+	}
+	if hung(gone, context.DeadlineExceeded) {
+		t.Error("a closed tab is not hung")
+	}
+}
+
+func TestMarkCrashed_UnknownTab(t *testing.T) {
+	b := newTestBridge()
+	b.config = &config.RuntimeConfig{}
+	b.markCrashed("nope", CrashRenderer)
+	if b.TabCrash("nope") != nil {
+		t.Error("untracked tab must not be recorded")
+	}
+}
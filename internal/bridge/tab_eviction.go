@@ -0,0 +1,158 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Tab eviction policies for when MaxTabs is reached.
+const (
+	EvictNone = "none" // refuse to open more tabs
+	EvictLRU  = "lru"  // close the least recently used tab
+	EvictIdle = "idle" // like lru, but only tabs idle for minIdleForEviction
+)
+
+// minIdleForEviction keeps the idle policy from closing tabs an agent is
+// still working in.
+const minIdleForEviction = time.Minute
+
+// TabEviction describes a tab the bridge closed on its own.
+type TabEviction struct {
+	TabID  string
+	URL    string
+	Owner  string
+	Reason string
+}
+
+// SetEvictionHandler registers fn to be told about every evicted tab.
+func (tm *TabManager) SetEvictionHandler(fn func(TabEviction)) {
+	tm.mu.Lock()
+	tm.onEvict = fn
+	tm.mu.Unlock()
+}
+
+// evictableLocked reports whether a tracked tab may be closed without being
+// asked to. Locked and busy tabs are off limits, as is the browser's first
+// tab: its context is the browser context, which new tabs are created from.
+// Callers hold tm.mu.
+func (tm *TabManager) evictableLocked(tabID string, entry *TabEntry) bool {
+	if entry.Ctx == nil || entry.Ctx == tm.browser() {
+		return false
+	}
+	if q, ok := tm.queues[tabID]; ok && q.depth() > 0 {
+		return false
+	}
+	if tm.isLocked != nil && tm.isLocked(tabID) {
+		return false
+	}
+	return true
+}
+
+// evictableFor reports whether opening a tab for owner may close tabID. With
+// strict ownership an agent only makes room among its own and shared tabs.
+func (tm *TabManager) evictableFor(tabID, owner string) bool {
+	if tm.config.TabOwnership != "strict" {
+		return true
+	}
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	entry, ok := tm.tabs[tabID]
+	return ok && (entry.Owner == "" || entry.Owner == owner)
+}
+
+// lruCandidates lists evictable tabs unused for at least minIdle, least
+// recently used first.
+func (tm *TabManager) lruCandidates(now time.Time, minIdle time.Duration) []string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	var ids []string
+	for id, entry := range tm.tabs {
+		if now.Sub(entry.LastUsed) >= minIdle && tm.evictableLocked(id, entry) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return tm.tabs[ids[i]].LastUsed.Before(tm.tabs[ids[j]].LastUsed)
+	})
+	return ids
+}
+
+// evictForLimit makes room for one more tab for owner when n tabs are open,
+// or returns the tab-limit error if the policy or the open tabs don't allow it.
+func (tm *TabManager) evictForLimit(n int, owner string) error {
+	limitErr := fmt.Errorf("tab limit reached (%d/%d) — close a tab first", n, tm.config.MaxTabs)
+
+	var minIdle time.Duration
+	switch tm.config.TabEviction {
+	case EvictLRU:
+	case EvictIdle:
+		minIdle = minIdleForEviction
+	default:
+		return limitErr
+	}
+
+	for _, id := range tm.lruCandidates(time.Now(), minIdle) {
+		if !tm.evictableFor(id, owner) {
+			continue
+		}
+		reason := fmt.Sprintf("%s eviction: tab limit %d reached", tm.config.TabEviction, tm.config.MaxTabs)
+		if tm.evict(id, reason) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w; no unlocked tab to evict", limitErr)
+}
+
+// evict closes a tab and reports it to the eviction handler.
+func (tm *TabManager) evict(tabID, reason string) bool {
+	tm.mu.RLock()
+	entry, ok := tm.tabs[tabID]
+	var ev TabEviction
+	if ok {
+		ev = TabEviction{TabID: tabID, Owner: entry.Owner, Reason: reason}
+	}
+	onEvict := tm.onEvict
+	tm.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if targets, err := tm.ListTargets(); err == nil {
+		for _, t := range targets {
+			if string(t.TargetID) == tabID {
+				ev.URL = t.URL
+			}
+		}
+	}
+
+	if err := tm.CloseTab(tabID); err != nil {
+		slog.Warn("evict tab", "tab", tabID, "err", err)
+		return false
+	}
+	slog.Info("tab evicted", "tab", tabID, "owner", ev.Owner, "reason", reason)
+	if onEvict != nil {
+		onEvict(ev)
+	}
+	return true
+}
+
+// CloseIdleTabs closes tabs nobody has touched for timeout, checking a few
+// times per timeout period until ctx is done.
+func (tm *TabManager) CloseIdleTabs(ctx context.Context, timeout time.Duration) {
+	ticker := time.NewTicker(min(timeout/4, time.Minute))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, id := range tm.lruCandidates(time.Now(), timeout) {
+			tm.evict(id, fmt.Sprintf("idle for more than %v", timeout))
+		}
+	}
+}
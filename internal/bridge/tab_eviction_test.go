@@ -0,0 +1,94 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func newEvictionBridge(policy string) *Bridge {
+	b := newTestBridge()
+	b.config = &config.RuntimeConfig{MaxTabs: 3, TabEviction: policy}
+	b.browserCtx = context.Background()
+	b.Locks = NewLockManager()
+	b.isLocked = func(tabID string) bool { return b.Locks.Get(tabID) != nil }
+
+	now := time.Now()
+	for i, age := range []time.Duration{time.Second, 10 * time.Minute, 2 * time.Minute} {
+		ctx, cancel := context.WithCancel(context.Background())
+		b.tabs[fmt.Sprintf("t%d", i)] = &TabEntry{Ctx: ctx, Cancel: cancel, LastUsed: now.Add(-age)}
+	}
+	return b
+}
+
+func TestLRUCandidates_Order(t *testing.T) {
+	b := newEvictionBridge(EvictLRU)
+	got := fmt.Sprint(b.lruCandidates(time.Now(), 0))
+	if got != "[t1 t2 t0]" {
+		t.Errorf("expected least recently used first, got %s", got)
+	}
+}
+
+func TestLRUCandidates_SkipsLockedBusyAndBrowserTab(t *testing.T) {
+	b := newEvictionBridge(EvictLRU)
+	_ = b.Locks.TryLock("t1", "agent-a", time.Minute)
+	release, _ := b.AcquireTab(context.Background(), "t2")
+	defer release()
+	b.tabs["first"] = &TabEntry{Ctx: b.browserCtx, LastUsed: time.Now().Add(-time.Hour)}
+
+	got := fmt.Sprint(b.lruCandidates(time.Now(), 0))
+	if got != "[t0]" {
+		t.Errorf("expected only t0 evictable, got %s", got)
+	}
+}
+
+func TestLRUCandidates_MinIdle(t *testing.T) {
+	b := newEvictionBridge(EvictIdle)
+	got := fmt.Sprint(b.lruCandidates(time.Now(), 5*time.Minute))
+	if got != "[t1]" {
+		t.Errorf("expected only the 10-minute idle tab, got %s", got)
+	}
+}
+
+func TestEvictForLimit_NonePolicy(t *testing.T) {
+	b := newEvictionBridge(EvictNone)
+	err := b.evictForLimit(3, "")
+	if err == nil || !strings.Contains(err.Error(), "tab limit reached (3/3)") {
+		t.Errorf("expected tab limit error, got %v", err)
+	}
+}
+
+func TestEvictForLimit_NothingEvictable(t *testing.T) {
+	b := newEvictionBridge(EvictLRU)
+	for _, id := range []string{"t0", "t1", "t2"} {
+		_ = b.Locks.TryLock(id, "agent-a", time.Minute)
+	}
+	err := b.evictForLimit(3, "")
+	if err == nil || !strings.Contains(err.Error(), "no unlocked tab to evict") {
+		t.Errorf("expected no-candidate error, got %v", err)
+	}
+}
+
+func TestEvictForLimit_StrictSparesOtherAgents(t *testing.T) {
+	b := newEvictionBridge(EvictLRU)
+	b.config.TabOwnership = "strict"
+	for _, id := range []string{"t0", "t1", "t2"} {
+		b.tabs[id].Owner = "agent-b"
+	}
+	err := b.evictForLimit(3, "agent-a")
+	if err == nil || !strings.Contains(err.Error(), "no unlocked tab to evict") {
+		t.Errorf("expected no-candidate error, got %v", err)
+	}
+	if len(b.tabs) != 3 {
+		t.Errorf("agent-b's tabs should stay open, got %d", len(b.tabs))
+	}
+
+	b.tabs["t1"].Owner = ""
+	if !b.evictableFor("t1", "agent-a") || b.evictableFor("t0", "agent-a") || !b.evictableFor("t0", "agent-b") {
+		t.Error("only shared and own tabs should be evictable")
+	}
+}
@@ -16,12 +16,21 @@ import (
 type TabSetupFunc func(ctx context.Context)
 
 type TabManager struct {
-	browserCtx context.Context
+	browserCtx context.Context // swapped by rebind after a browser relaunch
+	browserMu  sync.RWMutex
 	config     *config.RuntimeConfig
 	tabs       map[string]*TabEntry
 	accessed   map[string]bool
 	snapshots  map[string]*RefCache
 	onTabSetup TabSetupFunc
+	scripts    *scriptRegistry
+	queues     map[string]*tabQueue
+	current    map[string]string // agent ID -> current tab
+	popups     *popupLog
+	adopting   map[string]*adoption // popups being set up, by tab ID
+	isLocked   func(tabID string) bool
+	onEvict    func(TabEviction)
+	onCrash    func(TabCrash)
 	mu         sync.RWMutex
 }
 
@@ -33,12 +42,27 @@ func NewTabManager(browserCtx context.Context, cfg *config.RuntimeConfig, onTabS
 		accessed:   make(map[string]bool),
 		snapshots:  make(map[string]*RefCache),
 		onTabSetup: onTabSetup,
+		scripts:    newScriptRegistry(cfg.StateDir),
+		queues:     make(map[string]*tabQueue),
+		current:    make(map[string]string),
+		popups:     newPopupLog(),
+		adopting:   make(map[string]*adoption),
 	}
 }
 
+// browser returns the context of the current browser connection.
+func (tm *TabManager) browser() context.Context {
+	tm.browserMu.RLock()
+	defer tm.browserMu.RUnlock()
+	return tm.browserCtx
+}
+
 func (tm *TabManager) markAccessed(tabID string) {
 	tm.mu.Lock()
 	tm.accessed[tabID] = true
+	if entry, ok := tm.tabs[tabID]; ok {
+		entry.LastUsed = time.Now()
+	}
 	tm.mu.Unlock()
 }
 
@@ -65,27 +89,26 @@ func (tm *TabManager) TabContext(tabID string) (context.Context, string, error)
 		tabID = string(targets[0].TargetID)
 	}
 
-	tm.mu.RLock()
-	if entry, ok := tm.tabs[tabID]; ok && entry.Ctx != nil {
-		tm.mu.RUnlock()
-		tm.markAccessed(tabID)
-		return entry.Ctx, tabID, nil
-	}
-	tm.mu.RUnlock()
-
 	tm.mu.Lock()
+	for a := tm.adopting[tabID]; a != nil; a = tm.adopting[tabID] {
+		// A popup is being set up; use its context rather than attach twice.
+		tm.mu.Unlock()
+		<-a.done
+		tm.mu.Lock()
+	}
 	defer tm.mu.Unlock()
 
 	if entry, ok := tm.tabs[tabID]; ok && entry.Ctx != nil {
 		tm.accessed[tabID] = true
-		return entry.Ctx, tabID, nil
+		entry.LastUsed = time.Now()
+		return tm.opsContextLocked(entry), tabID, nil
 	}
 
-	if tm.browserCtx == nil {
+	if tm.browser() == nil {
 		return nil, "", fmt.Errorf("no browser connection")
 	}
 
-	ctx, cancel := chromedp.NewContext(tm.browserCtx,
+	ctx, cancel := chromedp.NewContext(tm.browser(),
 		chromedp.WithTargetID(target.ID(tabID)),
 	)
 	if err := chromedp.Run(ctx); err != nil {
@@ -96,13 +119,18 @@ func (tm *TabManager) TabContext(tabID string) (context.Context, string, error)
 	if tm.onTabSetup != nil {
 		tm.onTabSetup(ctx)
 	}
+	tm.applyUserScripts(ctx, tabID, true)
 
-	tm.tabs[tabID] = &TabEntry{Ctx: ctx, Cancel: cancel}
-	return ctx, tabID, nil
+	entry := &TabEntry{Ctx: ctx, Cancel: cancel, LastUsed: time.Now()}
+	tm.tabs[tabID] = entry
+	return tm.opsContextLocked(entry), tabID, nil
 }
 
-func (tm *TabManager) CreateTab(url string) (string, context.Context, context.CancelFunc, error) {
-	if tm.browserCtx == nil {
+// CreateTab opens a tab owned by owner, the agent asking for it, which also
+// decides what may be evicted to make room. The tab is tracked with its owner
+// from the start, so it is never briefly visible to other agents.
+func (tm *TabManager) CreateTab(url, owner string) (string, context.Context, context.CancelFunc, error) {
+	if tm.browser() == nil {
 		return "", nil, nil, fmt.Errorf("no browser context available")
 	}
 
@@ -111,8 +139,10 @@ func (tm *TabManager) CreateTab(url string) (string, context.Context, context.Ca
 		if err != nil {
 			return "", nil, nil, fmt.Errorf("check tab count: %w", err)
 		}
-		if len(targets) >= tm.config.MaxTabs {
-			return "", nil, nil, fmt.Errorf("tab limit reached (%d/%d) — close a tab first", len(targets), tm.config.MaxTabs)
+		for n := len(targets); n >= tm.config.MaxTabs; n-- {
+			if err := tm.evictForLimit(n, owner); err != nil {
+				return "", nil, nil, err
+			}
 		}
 	}
 
@@ -124,7 +154,7 @@ func (tm *TabManager) CreateTab(url string) (string, context.Context, context.Ca
 	}
 
 	var targetID target.ID
-	createCtx, createCancel := context.WithTimeout(tm.browserCtx, 10*time.Second)
+	createCtx, createCancel := context.WithTimeout(tm.browser(), 10*time.Second)
 	if err := chromedp.Run(createCtx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			var err error
@@ -138,29 +168,34 @@ func (tm *TabManager) CreateTab(url string) (string, context.Context, context.Ca
 	createCancel()
 
 	// Create a context for the new tab
-	ctx, cancel := chromedp.NewContext(tm.browserCtx,
+	ctx, cancel := chromedp.NewContext(tm.browser(),
 		chromedp.WithTargetID(targetID),
 	)
 
 	if tm.onTabSetup != nil {
 		tm.onTabSetup(ctx)
 	}
-
-	if tm.config.BlockMedia {
-		_ = SetResourceBlocking(ctx, MediaBlockPatterns)
-	} else if tm.config.BlockImages {
-		_ = SetResourceBlocking(ctx, ImageBlockPatterns)
-	}
+	tm.applyUserScripts(ctx, string(targetID), true)
+	tm.applyBlocking(ctx)
 
 	newTargetID := string(targetID)
 	tm.mu.Lock()
-	tm.tabs[newTargetID] = &TabEntry{Ctx: ctx, Cancel: cancel}
+	tm.tabs[newTargetID] = &TabEntry{Ctx: ctx, Cancel: cancel, Owner: owner, LastUsed: time.Now()}
 	tm.accessed[newTargetID] = true
 	tm.mu.Unlock()
 
 	return newTargetID, ctx, cancel, nil
 }
 
+// applyBlocking turns on the configured image/media blocking for a new tab.
+func (tm *TabManager) applyBlocking(ctx context.Context) {
+	if tm.config.BlockMedia {
+		_ = SetResourceBlocking(ctx, MediaBlockPatterns)
+	} else if tm.config.BlockImages {
+		_ = SetResourceBlocking(ctx, ImageBlockPatterns)
+	}
+}
+
 func (tm *TabManager) CloseTab(tabID string) error {
 	tm.mu.Lock()
 	entry, tracked := tm.tabs[tabID]
@@ -170,7 +205,7 @@ func (tm *TabManager) CloseTab(tabID string) error {
 		entry.Cancel()
 	}
 
-	closeCtx, closeCancel := context.WithTimeout(tm.browserCtx, 5*time.Second)
+	closeCtx, closeCancel := context.WithTimeout(tm.browser(), 5*time.Second)
 	defer closeCancel()
 
 	if err := target.CloseTarget(target.ID(tabID)).Do(cdp.WithExecutor(closeCtx, chromedp.FromContext(closeCtx).Browser)); err != nil {
@@ -183,17 +218,20 @@ func (tm *TabManager) CloseTab(tabID string) error {
 	tm.mu.Lock()
 	delete(tm.tabs, tabID)
 	delete(tm.snapshots, tabID)
+	tm.dropQueueLocked(tabID)
+	tm.dropCurrentLocked(tabID)
 	tm.mu.Unlock()
+	tm.forgetScripts(tabID)
 
 	return nil
 }
 
 func (tm *TabManager) ListTargets() ([]*target.Info, error) {
-	if tm.browserCtx == nil {
+	if tm.browser() == nil {
 		return nil, fmt.Errorf("no browser connection")
 	}
 	var targets []*target.Info
-	if err := chromedp.Run(tm.browserCtx,
+	if err := chromedp.Run(tm.browser(),
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			var err error
 			targets, err = target.GetTargets().Do(ctx)
@@ -233,7 +271,7 @@ func (tm *TabManager) DeleteRefCache(tabID string) {
 func (tm *TabManager) RegisterTab(tabID string, ctx context.Context) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
-	tm.tabs[tabID] = &TabEntry{Ctx: ctx}
+	tm.tabs[tabID] = &TabEntry{Ctx: ctx, LastUsed: time.Now()}
 }
 
 func (tm *TabManager) CleanStaleTabs(ctx context.Context, interval time.Duration) {
@@ -265,6 +303,9 @@ func (tm *TabManager) CleanStaleTabs(ctx context.Context, interval time.Duration
 				}
 				delete(tm.tabs, id)
 				delete(tm.snapshots, id)
+				tm.dropQueueLocked(id)
+				tm.dropCurrentLocked(id)
+				tm.forgetScripts(id)
 				slog.Info("cleaned stale tab", "id", id)
 			}
 		}
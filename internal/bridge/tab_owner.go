@@ -0,0 +1,111 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	cdp "github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// SetTabOwner records the agent that owns a tracked tab. An empty owner
+// marks the tab as shared.
+func (tm *TabManager) SetTabOwner(tabID, owner string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if entry, ok := tm.tabs[tabID]; ok {
+		entry.Owner = owner
+	}
+}
+
+// TabOwner returns the agent that owns tabID, or "" for shared and untracked
+// tabs.
+func (tm *TabManager) TabOwner(tabID string) string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if entry, ok := tm.tabs[tabID]; ok {
+		return entry.Owner
+	}
+	if a, ok := tm.adopting[tabID]; ok {
+		return a.owner
+	}
+	return ""
+}
+
+// TabsOwnedBy lists the tabs owned by an agent, sorted by ID.
+func (tm *TabManager) TabsOwnedBy(owner string) []string {
+	if owner == "" {
+		return nil
+	}
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	var ids []string
+	for id, entry := range tm.tabs {
+		if entry.Owner == owner {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SetCurrentTab makes tabID the tab an agent's requests default to when they
+// omit tabId. Anonymous callers share the "" agent.
+func (tm *TabManager) SetCurrentTab(agentID, tabID string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.current[agentID] = tabID
+}
+
+// CurrentTab returns the agent's current tab, or "" if it has none.
+func (tm *TabManager) CurrentTab(agentID string) string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.current[agentID]
+}
+
+// dropCurrentLocked clears tabID as anyone's current tab. Callers hold tm.mu.
+func (tm *TabManager) dropCurrentLocked(tabID string) {
+	for agent, id := range tm.current {
+		if id == tabID {
+			delete(tm.current, agent)
+		}
+	}
+}
+
+// ActivateTab brings a tab to the foreground in the browser window.
+func (tm *TabManager) ActivateTab(tabID string) error {
+	if tm.browser() == nil {
+		return fmt.Errorf("no browser context available")
+	}
+	ctx, cancel := context.WithTimeout(tm.browser(), 5*time.Second)
+	defer cancel()
+	if err := target.ActivateTarget(target.ID(tabID)).Do(cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Browser)); err != nil {
+		return fmt.Errorf("activate target: %w", err)
+	}
+	return nil
+}
+
+// ReleaseAgent closes the tabs an agent owns and drops its locks. It runs
+// when the dashboard reaper decides the agent has gone away.
+func (b *Bridge) ReleaseAgent(agentID string) (tabs, locks int) {
+	for _, id := range b.TabsOwnedBy(agentID) {
+		if err := b.CloseTab(id); err != nil {
+			slog.Warn("close orphaned tab", "tab", id, "agent", agentID, "err", err)
+			continue
+		}
+		tabs++
+	}
+	locks = len(b.Locks.ReleaseOwner(agentID))
+	b.mu.Lock()
+	delete(b.current, agentID)
+	b.mu.Unlock()
+	if tabs > 0 || locks > 0 {
+		slog.Info("released disconnected agent", "agent", agentID, "tabs", tabs, "locks", locks)
+	}
+	return tabs, locks
+}
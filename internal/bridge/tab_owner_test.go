@@ -0,0 +1,94 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTabOwners(t *testing.T) {
+	b := newTestBridge()
+	for _, id := range []string{"t1", "t2", "t3"} {
+		b.RegisterTab(id, context.Background())
+	}
+	b.SetTabOwner("t2", "agent-a")
+	b.SetTabOwner("t1", "agent-a")
+	b.SetTabOwner("t3", "agent-b")
+	b.SetTabOwner("untracked", "agent-a")
+
+	if got := b.TabOwner("t3"); got != "agent-b" {
+		t.Errorf("TabOwner(t3) = %q", got)
+	}
+	if got := b.TabOwner("untracked"); got != "" {
+		t.Errorf("untracked tab should have no owner, got %q", got)
+	}
+	if got := fmt.Sprint(b.TabsOwnedBy("agent-a")); got != "[t1 t2]" {
+		t.Errorf("TabsOwnedBy(agent-a) = %s", got)
+	}
+	if got := b.TabsOwnedBy(""); got != nil {
+		t.Errorf("shared tabs have no owner to list, got %v", got)
+	}
+}
+
+func TestLockManager_ReleaseOwner(t *testing.T) {
+	lm := NewLockManager()
+	_ = lm.TryLock("t1", "agent-a", time.Minute)
+	_ = lm.TryLock("t2", "agent-b", time.Minute)
+	_ = lm.TryLock("t3", "agent-a", time.Minute)
+
+	if freed := lm.ReleaseOwner("agent-a"); len(freed) != 2 {
+		t.Errorf("expected 2 freed locks, got %v", freed)
+	}
+	if lm.Get("t1") != nil || lm.Get("t3") != nil {
+		t.Error("agent-a locks should be gone")
+	}
+	if lm.Get("t2") == nil {
+		t.Error("agent-b lock should remain")
+	}
+}
+
+func TestLockManager_ReleaseOwnerWakesWaiter(t *testing.T) {
+	lm := NewLockManager()
+	_ = lm.TryLock("t1", "agent-a", time.Minute)
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		done <- lm.Acquire(ctx, "t1", "agent-b", time.Minute)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	lm.ReleaseOwner("agent-a")
+
+	if err := <-done; err != nil {
+		t.Fatalf("waiter should get the lock: %v", err)
+	}
+	if info := lm.Get("t1"); info == nil || info.Owner != "agent-b" {
+		t.Errorf("expected agent-b to hold t1, got %+v", info)
+	}
+}
+
+func TestCurrentTab(t *testing.T) {
+	b := newTestBridge()
+	b.SetCurrentTab("agent-a", "t1")
+	b.SetCurrentTab("agent-b", "t1")
+	b.SetCurrentTab("", "t2")
+
+	if got := b.CurrentTab("agent-a"); got != "t1" {
+		t.Errorf("CurrentTab(agent-a) = %q", got)
+	}
+	if got := b.CurrentTab("agent-c"); got != "" {
+		t.Errorf("unknown agent should have no current tab, got %q", got)
+	}
+
+	b.mu.Lock()
+	b.dropCurrentLocked("t1")
+	b.mu.Unlock()
+	if b.CurrentTab("agent-a") != "" || b.CurrentTab("agent-b") != "" {
+		t.Error("closed tab should no longer be current")
+	}
+	if got := b.CurrentTab(""); got != "t2" {
+		t.Errorf("anonymous current tab = %q", got)
+	}
+}
@@ -0,0 +1,136 @@
+package bridge
+
+import (
+	"context"
+	"sync"
+)
+
+// tabQueue runs operations on one tab one at a time, in arrival order.
+// Ownership passes straight from the releasing caller to the next waiter so
+// nobody can slip in between.
+type tabQueue struct {
+	mu      sync.Mutex
+	busy    bool
+	closed  bool // the tab closed while operations were still queued
+	waiters []chan struct{}
+}
+
+func (q *tabQueue) acquire(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.busy {
+		q.busy = true
+		q.mu.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	q.waiters = append(q.waiters, ch)
+	q.mu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		select {
+		case <-ch:
+			// Handed the turn while giving up; pass it on.
+			q.mu.Unlock()
+			q.release()
+		default:
+			for i, w := range q.waiters {
+				if w == ch {
+					q.waiters = append(q.waiters[:i:i], q.waiters[i+1:]...)
+					break
+				}
+			}
+			q.mu.Unlock()
+		}
+		return ctx.Err()
+	}
+}
+
+func (q *tabQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.waiters) > 0 {
+		next := q.waiters[0]
+		q.waiters = q.waiters[1:]
+		close(next)
+		return
+	}
+	q.busy = false
+}
+
+// depth counts the running operation plus those waiting.
+func (q *tabQueue) depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := len(q.waiters)
+	if q.busy {
+		n++
+	}
+	return n
+}
+
+// AcquireTab waits for the tab's turn in its FIFO queue. Callers must call
+// release when their CDP work is done. Different tabs never wait on each other.
+func (tm *TabManager) AcquireTab(ctx context.Context, tabID string) (release func(), err error) {
+	tm.mu.Lock()
+	q, ok := tm.queues[tabID]
+	if !ok {
+		q = &tabQueue{}
+		tm.queues[tabID] = q
+	}
+	tm.mu.Unlock()
+
+	if err := q.acquire(ctx); err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.release()
+			tm.forgetQueue(tabID, q)
+		})
+	}, nil
+}
+
+// forgetQueue removes the queue of a tab that closed while it was busy once
+// its last operation is done.
+func (tm *TabManager) forgetQueue(tabID string, q *tabQueue) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	q.mu.Lock()
+	closed := q.closed
+	q.mu.Unlock()
+	if closed && tm.queues[tabID] == q && q.depth() == 0 {
+		delete(tm.queues, tabID)
+	}
+}
+
+// TabQueueDepth reports how many operations are running or queued on a tab.
+func (tm *TabManager) TabQueueDepth(tabID string) int {
+	tm.mu.RLock()
+	q := tm.queues[tabID]
+	tm.mu.RUnlock()
+	if q == nil {
+		return 0
+	}
+	return q.depth()
+}
+
+// dropQueueLocked forgets the queue of a closed tab. A queue still in use is
+// marked and goes when its last operation releases it. Callers hold tm.mu.
+func (tm *TabManager) dropQueueLocked(tabID string) {
+	q := tm.queues[tabID]
+	if q == nil {
+		return
+	}
+	if q.depth() == 0 {
+		delete(tm.queues, tabID)
+		return
+	}
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+}
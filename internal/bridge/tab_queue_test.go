@@ -0,0 +1,102 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestAcquireTab_FIFO(t *testing.T) {
+	tm := NewTabManager(context.TODO(), &config.RuntimeConfig{}, nil)
+	release, err := tm.AcquireTab(context.Background(), "tab1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			rel, err := tm.AcquireTab(context.Background(), "tab1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+			rel()
+		}()
+		// Queue them one by one so arrival order is known.
+		for tm.TabQueueDepth("tab1") != i+2 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	release()
+	release() // idempotent
+	for want := 0; want < 3; want++ {
+		if got := <-order; got != want {
+			t.Fatalf("expected waiter %d, got %d", want, got)
+		}
+	}
+	for tm.TabQueueDepth("tab1") != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAcquireTab_TabsIndependent(t *testing.T) {
+	tm := NewTabManager(context.TODO(), &config.RuntimeConfig{}, nil)
+	release, _ := tm.AcquireTab(context.Background(), "tab1")
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	rel2, err := tm.AcquireTab(ctx, "tab2")
+	if err != nil {
+		t.Fatalf("another tab should not wait: %v", err)
+	}
+	rel2()
+}
+
+func TestAcquireTab_CancelLeavesQueue(t *testing.T) {
+	tm := NewTabManager(context.TODO(), &config.RuntimeConfig{}, nil)
+	release, _ := tm.AcquireTab(context.Background(), "tab1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := tm.AcquireTab(ctx, "tab1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if d := tm.TabQueueDepth("tab1"); d != 1 {
+		t.Errorf("expected depth 1 after cancel, got %d", d)
+	}
+	release()
+	if d := tm.TabQueueDepth("tab1"); d != 0 {
+		t.Errorf("expected empty queue, got %d", d)
+	}
+}
+
+func TestAcquireTab_ClosedWhileBusyDropsQueue(t *testing.T) {
+	tm := NewTabManager(context.TODO(), &config.RuntimeConfig{}, nil)
+	release, err := tm.AcquireTab(context.Background(), "tab1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tm.mu.Lock()
+	tm.dropQueueLocked("tab1")
+	_, kept := tm.queues["tab1"]
+	tm.mu.Unlock()
+	if !kept {
+		t.Fatal("a busy queue should survive until released")
+	}
+
+	release()
+	tm.mu.RLock()
+	_, kept = tm.queues["tab1"]
+	tm.mu.RUnlock()
+	if kept {
+		t.Error("queue of a closed tab should go with its last release")
+	}
+}
@@ -0,0 +1,141 @@
+package bridge
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	cdpio "github.com/chromedp/cdproto/io"
+	"github.com/chromedp/cdproto/tracing"
+	"github.com/chromedp/chromedp"
+)
+
+// DefaultTraceCategories match what the DevTools Performance panel records,
+// so the trace opens there with the usual tracks.
+var DefaultTraceCategories = []string{
+	"-*",
+	"devtools.timeline",
+	"v8.execute",
+	"disabled-by-default-devtools.timeline",
+	"disabled-by-default-devtools.timeline.frame",
+	"toplevel",
+	"blink.console",
+	"blink.user_timing",
+	"latencyInfo",
+	"disabled-by-default-devtools.timeline.stack",
+	"disabled-by-default-v8.cpu_profiler",
+}
+
+// TraceScreenshotCategory adds filmstrip screenshots to a trace.
+const TraceScreenshotCategory = "disabled-by-default-devtools.screenshot"
+
+// TraceResult describes a finished trace.
+type TraceResult struct {
+	Size     int64 `json:"size"`
+	DataLoss bool  `json:"dataLoss,omitempty"` // the trace buffer filled up
+}
+
+// traceConfig splits category filters the way chrome://tracing writes them:
+// a leading '-' excludes a category.
+func traceConfig(categories []string) *tracing.TraceConfig {
+	cfg := &tracing.TraceConfig{}
+	for _, c := range categories {
+		if excluded, ok := strings.CutPrefix(c, "-"); ok {
+			cfg.ExcludedCategories = append(cfg.ExcludedCategories, excluded)
+		} else if c != "" {
+			cfg.IncludedCategories = append(cfg.IncludedCategories, c)
+		}
+	}
+	return cfg
+}
+
+// StartTrace begins recording a trace of the tab in ctx. Chrome keeps the
+// events until StopTrace and then hands them over as a stream.
+func StartTrace(ctx context.Context, categories []string) error {
+	if len(categories) == 0 {
+		categories = DefaultTraceCategories
+	}
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return tracing.Start().
+			WithTransferMode(tracing.TransferModeReturnAsStream).
+			WithStreamFormat(tracing.StreamFormatJSON).
+			WithTraceConfig(traceConfig(categories)).
+			Do(ctx)
+	}))
+}
+
+// StopTrace ends the trace of the tab in ctx and copies it to w as Chrome
+// trace-event JSON, reading the stream chunk by chunk so large traces never
+// sit in memory whole.
+func StopTrace(ctx context.Context, w io.Writer) (TraceResult, error) {
+	complete := make(chan *tracing.EventTracingComplete, 1)
+	lctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	chromedp.ListenTarget(lctx, func(ev any) {
+		if e, ok := ev.(*tracing.EventTracingComplete); ok {
+			select {
+			case complete <- e:
+			default:
+			}
+		}
+	})
+
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return tracing.End().Do(ctx)
+	})); err != nil {
+		return TraceResult{}, fmt.Errorf("end trace: %w", err)
+	}
+
+	var done *tracing.EventTracingComplete
+	select {
+	case done = <-complete:
+	case <-ctx.Done():
+		return TraceResult{}, fmt.Errorf("wait for trace: %w", context.Cause(ctx))
+	}
+	if done.Stream == "" {
+		return TraceResult{}, errors.New("chrome returned no trace stream")
+	}
+
+	result := TraceResult{DataLoss: done.DataLossOccurred}
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		defer func() { _ = cdpio.Close(done.Stream).Do(ctx) }()
+		var err error
+		result.Size, err = readStream(ctx, done.Stream, w)
+		return err
+	}))
+	if err != nil {
+		return result, fmt.Errorf("read trace: %w", err)
+	}
+	return result, nil
+}
+
+// readStream copies a CDP IO stream to w. IO.read's own Do drops the
+// base64Encoded flag, so the command is executed directly.
+func readStream(ctx context.Context, handle cdpio.StreamHandle, w io.Writer) (int64, error) {
+	var total int64
+	for {
+		var res cdpio.ReadReturns
+		if err := cdp.Execute(ctx, cdpio.CommandRead, cdpio.Read(handle), &res); err != nil {
+			return total, err
+		}
+		chunk := []byte(res.Data)
+		if res.Base64encoded {
+			var err error
+			if chunk, err = base64.StdEncoding.DecodeString(res.Data); err != nil {
+				return total, fmt.Errorf("decode chunk: %w", err)
+			}
+		}
+		n, err := w.Write(chunk)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		if res.EOF {
+			return total, nil
+		}
+	}
+}
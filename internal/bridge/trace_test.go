@@ -0,0 +1,16 @@
+package bridge
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTraceConfig_SplitsExclusions(t *testing.T) {
+	cfg := traceConfig([]string{"-*", "devtools.timeline", "", "-v8"})
+	if !slices.Equal(cfg.IncludedCategories, []string{"devtools.timeline"}) {
+		t.Errorf("included = %v", cfg.IncludedCategories)
+	}
+	if !slices.Equal(cfg.ExcludedCategories, []string{"*", "v8"}) {
+		t.Errorf("excluded = %v", cfg.ExcludedCategories)
+	}
+}
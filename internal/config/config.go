@@ -1,8 +1,11 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -29,6 +32,12 @@ type RuntimeConfig struct {
 	UserAgent        string
 	NoAnimations     bool
 	StealthLevel     string
+	TabOwnership     string
+	TabEviction      string
+	TabIdleTimeout   time.Duration
+	TabAutoReload    bool
+	TabHangTimeout   time.Duration
+	SessionAutosave  time.Duration
 	ActionTimeout    time.Duration
 	NavigateTimeout  time.Duration
 	ShutdownTimeout  time.Duration
@@ -54,6 +63,14 @@ func envIntOr(key string, fallback int) int {
 	return n
 }
 
+// envPositiveIntOr is envIntOr for settings where zero makes no sense.
+func envPositiveIntOr(key string, fallback int) int {
+	if n := envIntOr(key, fallback); n > 0 {
+		return n
+	}
+	return fallback
+}
+
 func envBoolOr(key string, fallback bool) bool {
 	v, ok := os.LookupEnv(key)
 	if !ok {
@@ -78,6 +95,17 @@ func (c *RuntimeConfig) ListenAddr() string {
 	return c.Bind + ":" + c.Port
 }
 
+// Digest is a short fingerprint of the effective config, for telling apart
+// instances that were started with different settings. The token is left
+// out so the digest can be shown to anyone who can reach /health.
+func (c *RuntimeConfig) Digest() string {
+	cp := *c
+	cp.Token = ""
+	data, _ := json.Marshal(cp)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
 type FileConfig struct {
 	Port        string `json:"port"`
 	CdpURL      string `json:"cdpUrl,omitempty"`
@@ -89,9 +117,40 @@ type FileConfig struct {
 	MaxTabs     *int   `json:"maxTabs,omitempty"`
 	TimeoutSec  int    `json:"timeoutSec,omitempty"`
 	NavigateSec int    `json:"navigateSec,omitempty"`
+	// TabOwnership is "strict" to scope agents to their own tabs, or "" /
+	// "shared" for none. Unknown values are treated as strict.
+	TabOwnership string `json:"tabOwnership,omitempty"`
+	// TabEviction is "lru", "idle" or "none" (default) for when maxTabs is hit.
+	TabEviction    string `json:"tabEviction,omitempty"`
+	TabIdleMinutes int    `json:"tabIdleMinutes,omitempty"`
+	// TabAutoReload reloads a tab to its last URL after its renderer crashes.
+	TabAutoReload bool `json:"tabAutoReload,omitempty"`
+	TabHangSec    *int `json:"tabHangSec,omitempty"`
+	// SessionAutosaveMinutes saves all tabs as the "autosave" session this often.
+	SessionAutosaveMinutes int `json:"sessionAutosaveMinutes,omitempty"`
+	// ShutdownSec is how long shutdown waits for in-flight requests.
+	ShutdownSec int `json:"shutdownSec,omitempty"`
 }
 
 func Load() *RuntimeConfig {
+	cfg := load()
+	switch cfg.TabEviction {
+	case "none", "lru", "idle":
+	default:
+		slog.Warn("unknown tab eviction policy, using none", "value", cfg.TabEviction)
+		cfg.TabEviction = "none"
+	}
+	switch cfg.TabOwnership {
+	case "", "shared", "strict":
+	default:
+		// A typo must not silently turn off isolation someone asked for.
+		slog.Error("unknown tab ownership mode, using strict", "value", cfg.TabOwnership)
+		cfg.TabOwnership = "strict"
+	}
+	return cfg
+}
+
+func load() *RuntimeConfig {
 	cfg := &RuntimeConfig{
 		Bind:             envOr("BRIDGE_BIND", "127.0.0.1"),
 		Port:             envOr("BRIDGE_PORT", "9867"),
@@ -111,9 +170,15 @@ func Load() *RuntimeConfig {
 		UserAgent:        os.Getenv("BRIDGE_USER_AGENT"),
 		NoAnimations:     os.Getenv("BRIDGE_NO_ANIMATIONS") == "true",
 		StealthLevel:     envOr("BRIDGE_STEALTH", "light"),
+		TabOwnership:     os.Getenv("BRIDGE_TAB_OWNERSHIP"),
+		TabEviction:      envOr("BRIDGE_TAB_EVICTION", "none"),
+		TabIdleTimeout:   time.Duration(envIntOr("BRIDGE_TAB_IDLE_TIMEOUT", 0)) * time.Minute,
+		TabAutoReload:    envBoolOr("BRIDGE_TAB_AUTO_RELOAD", false),
+		TabHangTimeout:   time.Duration(envIntOr("BRIDGE_TAB_HANG_TIMEOUT", 0)) * time.Second,
+		SessionAutosave:  time.Duration(envIntOr("BRIDGE_SESSION_AUTOSAVE", 0)) * time.Minute,
 		ActionTimeout:    15 * time.Second,
 		NavigateTimeout:  30 * time.Second,
-		ShutdownTimeout:  10 * time.Second,
+		ShutdownTimeout:  time.Duration(envPositiveIntOr("BRIDGE_SHUTDOWN_TIMEOUT", 10)) * time.Second,
 		WaitNavDelay:     1 * time.Second,
 	}
 
@@ -159,6 +224,27 @@ func Load() *RuntimeConfig {
 	if fc.NavigateSec > 0 && os.Getenv("BRIDGE_NAV_TIMEOUT") == "" {
 		cfg.NavigateTimeout = time.Duration(fc.NavigateSec) * time.Second
 	}
+	if fc.TabOwnership != "" && os.Getenv("BRIDGE_TAB_OWNERSHIP") == "" {
+		cfg.TabOwnership = fc.TabOwnership
+	}
+	if fc.TabEviction != "" && os.Getenv("BRIDGE_TAB_EVICTION") == "" {
+		cfg.TabEviction = fc.TabEviction
+	}
+	if fc.TabIdleMinutes > 0 && os.Getenv("BRIDGE_TAB_IDLE_TIMEOUT") == "" {
+		cfg.TabIdleTimeout = time.Duration(fc.TabIdleMinutes) * time.Minute
+	}
+	if fc.TabAutoReload && os.Getenv("BRIDGE_TAB_AUTO_RELOAD") == "" {
+		cfg.TabAutoReload = true
+	}
+	if fc.TabHangSec != nil && os.Getenv("BRIDGE_TAB_HANG_TIMEOUT") == "" {
+		cfg.TabHangTimeout = time.Duration(*fc.TabHangSec) * time.Second
+	}
+	if fc.SessionAutosaveMinutes > 0 && os.Getenv("BRIDGE_SESSION_AUTOSAVE") == "" {
+		cfg.SessionAutosave = time.Duration(fc.SessionAutosaveMinutes) * time.Minute
+	}
+	if fc.ShutdownSec > 0 && os.Getenv("BRIDGE_SHUTDOWN_TIMEOUT") == "" {
+		cfg.ShutdownTimeout = time.Duration(fc.ShutdownSec) * time.Second
+	}
 
 	return cfg
 }
@@ -113,6 +113,26 @@ func TestLoadConfigEnvOverrides(t *testing.T) {
 	}
 }
 
+func TestLoadConfigTabOwnership(t *testing.T) {
+	defer func() { _ = os.Unsetenv("BRIDGE_TAB_OWNERSHIP") }()
+	for value, want := range map[string]string{"": "", "shared": "shared", "strict": "strict", "Strict": "strict", "stirct": "strict"} {
+		_ = os.Setenv("BRIDGE_TAB_OWNERSHIP", value)
+		if cfg := Load(); cfg.TabOwnership != want {
+			t.Errorf("TabOwnership %q = %q, want %q", value, cfg.TabOwnership, want)
+		}
+	}
+}
+
+func TestLoadConfigUnknownEviction(t *testing.T) {
+	_ = os.Setenv("BRIDGE_TAB_EVICTION", "LRU-ish")
+	defer func() { _ = os.Unsetenv("BRIDGE_TAB_EVICTION") }()
+
+	cfg := Load()
+	if cfg.TabEviction != "none" {
+		t.Errorf("unknown TabEviction = %v, want none", cfg.TabEviction)
+	}
+}
+
 func TestDefaultFileConfig(t *testing.T) {
 	fc := DefaultFileConfig()
 	if fc.Port != "9867" {
@@ -133,7 +153,13 @@ func TestLoadConfigFile(t *testing.T) {
 	configData := `{
 		"port": "8888",
 		"headless": false,
-		"timeoutSec": 60
+		"timeoutSec": 60,
+		"tabEviction": "lru",
+		"tabIdleMinutes": 15,
+		"tabAutoReload": true,
+		"tabHangSec": 0,
+		"sessionAutosaveMinutes": 5,
+		"shutdownSec": 25
 	}`
 	if err := os.WriteFile(configPath, []byte(configData), 0644); err != nil {
 		t.Fatal(err)
@@ -149,6 +175,34 @@ func TestLoadConfigFile(t *testing.T) {
 	if cfg.ActionTimeout != 60*time.Second {
 		t.Errorf("file ActionTimeout = %v, want 60s", cfg.ActionTimeout)
 	}
+	if cfg.TabEviction != "lru" {
+		t.Errorf("file TabEviction = %v, want lru", cfg.TabEviction)
+	}
+	if cfg.TabIdleTimeout != 15*time.Minute {
+		t.Errorf("file TabIdleTimeout = %v, want 15m", cfg.TabIdleTimeout)
+	}
+	if !cfg.TabAutoReload {
+		t.Error("file TabAutoReload = false, want true")
+	}
+	if cfg.TabHangTimeout != 0 {
+		t.Errorf("file TabHangTimeout = %v, want 0 (disabled)", cfg.TabHangTimeout)
+	}
+	if cfg.SessionAutosave != 5*time.Minute {
+		t.Errorf("file SessionAutosave = %v, want 5m", cfg.SessionAutosave)
+	}
+	if cfg.ShutdownTimeout != 25*time.Second {
+		t.Errorf("file ShutdownTimeout = %v, want 25s", cfg.ShutdownTimeout)
+	}
+}
+
+func TestLoadConfigShutdownTimeoutFallback(t *testing.T) {
+	defer func() { _ = os.Unsetenv("BRIDGE_SHUTDOWN_TIMEOUT") }()
+	for _, v := range []string{"0", "-5", "soon"} {
+		_ = os.Setenv("BRIDGE_SHUTDOWN_TIMEOUT", v)
+		if cfg := Load(); cfg.ShutdownTimeout != 10*time.Second {
+			t.Errorf("BRIDGE_SHUTDOWN_TIMEOUT=%s: got %v, want 10s", v, cfg.ShutdownTimeout)
+		}
+	}
 }
 
 func TestListenAddr(t *testing.T) {
@@ -162,3 +216,18 @@ func TestListenAddr(t *testing.T) {
 		t.Errorf("expected 0.0.0.0:8080, got %s", got)
 	}
 }
+
+func TestDigest(t *testing.T) {
+	a := &RuntimeConfig{Port: "9867", StealthLevel: "light", Token: "one"}
+	b := &RuntimeConfig{Port: "9867", StealthLevel: "light", Token: "two"}
+	if a.Digest() != b.Digest() {
+		t.Error("digest must not depend on the token")
+	}
+	if len(a.Digest()) != 12 {
+		t.Errorf("expected 12 hex chars, got %q", a.Digest())
+	}
+	b.StealthLevel = "full"
+	if a.Digest() == b.Digest() {
+		t.Error("digest must change with settings")
+	}
+}
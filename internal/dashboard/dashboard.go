@@ -55,12 +55,22 @@ type InstanceLister interface {
 }
 
 type Dashboard struct {
-	cfg       DashboardConfig
-	agents    map[string]*AgentActivity
-	sseConns  map[chan AgentEvent]struct{}
-	cancel    context.CancelFunc
-	instances InstanceLister
-	mu        sync.RWMutex
+	cfg          DashboardConfig
+	agents       map[string]*AgentActivity
+	sseConns     map[chan AgentEvent]struct{}
+	ctx          context.Context
+	cancel       context.CancelFunc
+	instances    InstanceLister
+	onDisconnect func(agentID string)
+	mu           sync.RWMutex
+}
+
+// SetDisconnectHandler registers fn to run, outside the dashboard lock, for
+// each agent the reaper marks as disconnected.
+func (d *Dashboard) SetDisconnectHandler(fn func(agentID string)) {
+	d.mu.Lock()
+	d.onDisconnect = fn
+	d.mu.Unlock()
 }
 
 // SetInstanceLister sets the orchestrator for aggregating agents from child instances.
@@ -187,12 +197,14 @@ func NewDashboard(cfg *DashboardConfig) *Dashboard {
 		cfg:      c,
 		agents:   make(map[string]*AgentActivity),
 		sseConns: make(map[chan AgentEvent]struct{}),
+		ctx:      ctx,
 		cancel:   cancel,
 	}
 	go d.reaper(ctx)
 	return d
 }
 
+// Shutdown stops the reaper and ends open event streams.
 func (d *Dashboard) Shutdown() { d.cancel() }
 
 func (d *Dashboard) reaper(ctx context.Context) {
@@ -205,17 +217,25 @@ func (d *Dashboard) reaper(ctx context.Context) {
 		case <-ticker.C:
 			d.mu.Lock()
 			now := time.Now()
+			var gone []string
 			for id, a := range d.agents {
 				if a.Status == "disconnected" {
 					continue
 				}
 				if now.Sub(a.LastSeen) > d.cfg.DisconnectTimeout {
 					d.agents[id].Status = "disconnected"
+					gone = append(gone, id)
 				} else if now.Sub(a.LastSeen) > d.cfg.IdleTimeout {
 					d.agents[id].Status = "idle"
 				}
 			}
+			onDisconnect := d.onDisconnect
 			d.mu.Unlock()
+			if onDisconnect != nil {
+				for _, id := range gone {
+					onDisconnect(id)
+				}
+			}
 		}
 	}
 }
@@ -239,13 +259,30 @@ func (d *Dashboard) RecordEvent(evt AgentEvent) {
 	if evt.TabID != "" {
 		a.CurrentTab = evt.TabID
 	}
+	chans := d.sseChansLocked()
+	d.mu.Unlock()
 
+	sendEvent(chans, evt)
+}
+
+// BroadcastEvent sends an event to SSE listeners without attributing it to an
+// agent's activity, for things the bridge does on its own like tab evictions.
+func (d *Dashboard) BroadcastEvent(evt AgentEvent) {
+	d.mu.RLock()
+	chans := d.sseChansLocked()
+	d.mu.RUnlock()
+	sendEvent(chans, evt)
+}
+
+func (d *Dashboard) sseChansLocked() []chan AgentEvent {
 	chans := make([]chan AgentEvent, 0, len(d.sseConns))
 	for ch := range d.sseConns {
 		chans = append(chans, ch)
 	}
-	d.mu.Unlock()
+	return chans
+}
 
+func sendEvent(chans []chan AgentEvent, evt AgentEvent) {
 	for _, ch := range chans {
 		select {
 		case ch <- evt:
@@ -321,6 +358,8 @@ func (d *Dashboard) handleSSE(w http.ResponseWriter, r *http.Request) {
 			flusher.Flush()
 		case <-r.Context().Done():
 			return
+		case <-d.ctx.Done():
+			return
 		}
 	}
 }
@@ -367,7 +406,8 @@ func isManagementRoute(path string) bool {
 		strings.HasPrefix(path, "/profiles") ||
 		strings.HasPrefix(path, "/instances") ||
 		strings.HasPrefix(path, "/screencast/tabs") ||
-		path == "/welcome" || path == "/favicon.ico" || path == "/health"
+		path == "/welcome" || path == "/favicon.ico" ||
+		path == "/health" || strings.HasPrefix(path, "/health/") || path == "/metrics"
 }
 
 func actionDetail(r *http.Request) string {
@@ -34,6 +34,7 @@ function connect() {
 
     renderAgents();
     renderFeed();
+    if (evt.action.startsWith('HANDOFF') && typeof loadHandoffs === 'function') loadHandoffs();
   });
 
   es.onerror = () => {
@@ -0,0 +1,82 @@
+'use strict';
+
+let pendingHandoffs = [];
+
+async function loadHandoffs() {
+  try {
+    const res = await fetch('/handoff');
+    const data = res.ok ? await res.json() : {};
+    pendingHandoffs = (data.handoffs || []).filter((h) => h.status === 'waiting');
+  } catch (e) {
+    pendingHandoffs = [];
+  }
+  renderHandoffBadge();
+}
+
+function renderHandoffBadge() {
+  const badge = document.getElementById('handoff-badge');
+  if (!badge) return;
+  const n = pendingHandoffs.length;
+  badge.style.display = n ? '' : 'none';
+  badge.textContent = n === 1 ? '✋ 1 agent needs a human' : '✋ ' + n + ' agents need a human';
+}
+
+function showHandoffs() {
+  if (pendingHandoffs.length === 1) {
+    openHandoff(pendingHandoffs[0]);
+    return;
+  }
+  const rows = pendingHandoffs.map((h, i) => `
+    <div class="handoff-row" data-idx="${i}">
+      <span class="handoff-agent">${esc(h.agentId)}</span>
+      <span class="handoff-reason">${esc(h.reason)}</span>
+      <span class="rec-meta">${timeAgo(new Date(h.createdAt))}</span>
+    </div>
+  `).join('');
+  showModal('Waiting for a human', '<div class="handoff-list">' + (rows || '<div class="empty-state">Nothing waiting.</div>') + '</div>',
+    '<button class="secondary" onclick="closeModal()">Close</button>');
+  document.querySelectorAll('.handoff-row').forEach((row) => {
+    row.onclick = () => openHandoff(pendingHandoffs[parseInt(row.dataset.idx, 10)]);
+  });
+}
+
+function openHandoff(ho) {
+  closeScreencastSockets();
+  showModal('✋ ' + ho.agentId + ' needs a human', `
+    <div class="handoff-panel">
+      <div class="handoff-reason">${esc(ho.reason)}</div>
+      <div class="rec-meta">${esc(ho.url || ho.tabId)} · ${timeAgo(new Date(ho.createdAt))} · use Control on the tile to drive the tab</div>
+      <div id="handoff-stream" class="screencast-grid"></div>
+      <textarea id="handoff-note" placeholder="Note for the agent (optional)"></textarea>
+    </div>
+  `, '<button class="secondary" onclick="closeModal()">Close</button><button id="handoff-resume">Resume agent</button>', {
+    wide: true,
+    onClose: closeScreencastSockets,
+  });
+
+  renderStreams(document.getElementById('handoff-stream'), [{
+    key: makeTileKey(ho.tabId, 'handoff'),
+    tabId: ho.tabId,
+    baseUrl: null,
+    label: String(ho.tabId || '').substring(0, 8),
+    url: ho.url,
+  }]);
+
+  document.getElementById('handoff-resume').onclick = async () => {
+    const note = document.getElementById('handoff-note').value;
+    const res = await fetch('/handoff/' + encodeURIComponent(ho.id) + '/resume', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ note }),
+    });
+    if (!res.ok) {
+      const data = await res.json().catch(() => ({}));
+      appAlert(data.error || 'Resume failed', 'Handoff');
+    }
+    closeModal();
+    loadHandoffs();
+  };
+}
+
+loadHandoffs();
+setInterval(loadHandoffs, 15000);
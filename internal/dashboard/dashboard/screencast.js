@@ -24,7 +24,8 @@ function connectScreencast(stream) {
   const key = stream.key;
   const wsProto = baseUrl ? 'ws:' : (location.protocol === 'https:' ? 'wss:' : 'ws:');
   const wsHost = baseUrl ? baseUrl.replace(/^https?:\/\//, '') : location.host;
-  const wsUrl = wsProto + '//' + wsHost + '/screencast?tabId=' + encodeURIComponent(tabId) + getScreencastParams();
+  const wsUrl = wsProto + '//' + wsHost + '/screencast?tabId=' + encodeURIComponent(tabId) + getScreencastParams() +
+    '&control=true&owner=' + encodeURIComponent(CONTROL_OWNER);
 
   const socket = new WebSocket(wsUrl);
   socket.binaryType = 'arraybuffer';
@@ -44,6 +45,10 @@ function connectScreencast(stream) {
     if (statusEl) statusEl.className = 'tile-status streaming';
   };
   socket.onmessage = (evt) => {
+    if (typeof evt.data === 'string') {
+      handleControlReply(stream, evt.data);
+      return;
+    }
     const blob = new Blob([evt.data], { type: 'image/jpeg' });
     const url = URL.createObjectURL(blob);
     const img = new Image();
@@ -71,6 +76,121 @@ function connectScreencast(stream) {
   };
 }
 
+const CONTROL_OWNER = 'dashboard';
+
+function handleControlReply(stream, raw) {
+  let msg;
+  try { msg = JSON.parse(raw); } catch (e) { return; }
+  const btn = document.getElementById('ctl-' + stream.key);
+  const tile = document.getElementById('tile-' + stream.key);
+  if (msg.type === 'control') {
+    if (btn) {
+      btn.classList.toggle('active', !!msg.granted);
+      btn.textContent = msg.granted ? 'Release' : 'Control';
+    }
+    if (tile) tile.classList.toggle('controlling', !!msg.granted);
+    if (msg.granted) {
+      const canvas = document.getElementById('canvas-' + stream.key);
+      if (canvas) canvas.focus();
+    }
+    if (msg.lockedBy) {
+      appConfirm('This tab is locked by ' + msg.lockedBy + '. Take over anyway? The agent will be blocked until you release it.', 'Remote control', true)
+        .then((ok) => {
+          const socket = screencastSockets[stream.key];
+          if (ok && socket && socket.readyState === WebSocket.OPEN) {
+            socket.send(JSON.stringify({ type: 'takeover', force: true }));
+          }
+        });
+    } else if (msg.error) {
+      appAlert(msg.error, 'Remote control');
+    }
+  } else if (msg.type === 'error') {
+    const sizeEl = document.getElementById('size-' + stream.key);
+    if (sizeEl) sizeEl.textContent = msg.error;
+  }
+}
+
+function controlModifiers(e) {
+  return (e.altKey ? 1 : 0) | (e.ctrlKey ? 2 : 0) | (e.metaKey ? 4 : 0) | (e.shiftKey ? 8 : 0);
+}
+
+// attachRemoteControl forwards canvas input over the screencast socket while
+// the tile holds control. Coordinates are sent in frame pixels; the server
+// scales them back to the viewport.
+function attachRemoteControl(stream) {
+  const canvas = document.getElementById('canvas-' + stream.key);
+  const btn = document.getElementById('ctl-' + stream.key);
+  const tile = document.getElementById('tile-' + stream.key);
+  if (!canvas || !btn || !tile) return;
+  canvas.tabIndex = 0;
+
+  const send = (msg) => {
+    const socket = screencastSockets[stream.key];
+    if (socket && socket.readyState === WebSocket.OPEN) socket.send(JSON.stringify(msg));
+  };
+  const active = () => tile.classList.contains('controlling');
+  const point = (e) => {
+    const rect = canvas.getBoundingClientRect();
+    return {
+      x: (e.clientX - rect.left) * canvas.width / rect.width,
+      y: (e.clientY - rect.top) * canvas.height / rect.height,
+    };
+  };
+  const buttons = ['left', 'middle', 'right'];
+
+  btn.onclick = () => send({ type: active() ? 'release' : 'takeover' });
+
+  let lastMove = 0;
+  canvas.addEventListener('mousemove', (e) => {
+    if (!active()) return;
+    const now = Date.now();
+    if (now - lastMove < 33) return;
+    lastMove = now;
+    send({ type: 'mouse', event: 'mouseMoved', ...point(e), modifiers: controlModifiers(e) });
+  });
+  ['mousedown', 'mouseup'].forEach((name) => {
+    canvas.addEventListener(name, (e) => {
+      if (!active()) return;
+      e.preventDefault();
+      canvas.focus();
+      send({
+        type: 'mouse',
+        event: name === 'mousedown' ? 'mousePressed' : 'mouseReleased',
+        ...point(e),
+        button: buttons[e.button] || 'left',
+        clickCount: e.detail || 1,
+        modifiers: controlModifiers(e),
+      });
+    });
+  });
+  canvas.addEventListener('contextmenu', (e) => { if (active()) e.preventDefault(); });
+  canvas.addEventListener('wheel', (e) => {
+    if (!active()) return;
+    e.preventDefault();
+    send({ type: 'wheel', ...point(e), deltaX: e.deltaX, deltaY: e.deltaY, modifiers: controlModifiers(e) });
+  }, { passive: false });
+  ['keydown', 'keyup'].forEach((name) => {
+    canvas.addEventListener(name, (e) => {
+      if (!active()) return;
+      e.preventDefault();
+      let text = '';
+      if (name === 'keydown') {
+        if (e.key.length === 1 && !e.ctrlKey && !e.metaKey) text = e.key;
+        else if (e.key === 'Enter') text = '\r';
+      }
+      send({
+        type: 'key',
+        event: name === 'keydown' ? 'keyDown' : 'keyUp',
+        key: e.key,
+        code: e.code,
+        keyCode: e.keyCode,
+        text,
+        modifiers: controlModifiers(e),
+      });
+    });
+  });
+}
+
 function renderLiveEmpty(grid, messageHtml) {
   if (!grid) return;
   grid.classList.add('empty');
@@ -92,6 +212,10 @@ function renderStreams(grid, streams) {
             <span class="tile-status connecting" id="status-${s.key}"></span>
           </span>
           <span class="tile-url" id="url-${s.key}">${esc(s.url || 'about:blank')}</span>
+          <span class="tile-actions">
+            <button class="tile-ctl" id="ctl-${s.key}" title="Take control of this tab (acquires the tab lock)">Control</button>
+            <button class="tile-rec" id="rec-${s.key}" title="Record this tab">● Rec</button>
+          </span>
         </div>
         <canvas id="canvas-${s.key}" width="800" height="600"></canvas>
         <div class="tile-footer">
@@ -100,7 +224,185 @@ function renderStreams(grid, streams) {
         </div>
       </div>
     `).join(''));
-  streams.forEach((s) => connectScreencast(s));
+  streams.forEach((s) => {
+    connectScreencast(s);
+    attachRemoteControl(s);
+    const recBtn = document.getElementById('rec-' + s.key);
+    if (recBtn) recBtn.onclick = () => toggleRecording(s, recBtn);
+  });
+  syncRecordingButtons(streams);
+}
+
+function apiBase(baseUrl) {
+  return baseUrl || '';
+}
+
+async function syncRecordingButtons(streams) {
+  const bases = [...new Set(streams.map((s) => apiBase(s.baseUrl)))];
+  for (const base of bases) {
+    try {
+      const res = await fetch(base + '/recordings');
+      if (!res.ok) continue;
+      const data = await res.json();
+      (data.recordings || []).filter((r) => r.active).forEach((r) => {
+        const s = streams.find((st) => apiBase(st.baseUrl) === base && st.tabId === r.tabId);
+        const btn = s && document.getElementById('rec-' + s.key);
+        if (btn) setRecordingButton(btn, r.id);
+      });
+    } catch (e) {}
+  }
+}
+
+function setRecordingButton(btn, id) {
+  btn.dataset.recId = id || '';
+  btn.classList.toggle('recording', !!id);
+  btn.textContent = id ? '■ Stop' : '● Rec';
+}
+
+async function toggleRecording(stream, btn) {
+  const base = apiBase(stream.baseUrl);
+  const recId = btn.dataset.recId;
+  const body = recId
+    ? { action: 'stop', id: recId }
+    : { action: 'start', tabId: stream.tabId, fps: screencastSettings.fps > 5 ? screencastSettings.fps : 5 };
+  btn.disabled = true;
+  try {
+    const res = await fetch(base + '/recordings', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(body),
+    });
+    const data = await res.json();
+    if (!res.ok) {
+      if (res.status === 409 && data.id) setRecordingButton(btn, data.id);
+      else appAlert(data.error || 'Recording failed', 'Recording');
+      return;
+    }
+    setRecordingButton(btn, recId ? '' : data.id);
+  } catch (e) {
+    appAlert('Recording request failed: ' + e.message, 'Recording');
+  } finally {
+    btn.disabled = false;
+  }
+}
+
+function formatDuration(ms) {
+  const s = Math.floor((ms || 0) / 1000);
+  return Math.floor(s / 60) + ':' + String(s % 60).padStart(2, '0');
+}
+
+async function showRecordings(baseUrl) {
+  const base = apiBase(baseUrl);
+  showModal('Recordings', '<div id="recordings-list" class="recordings-list"><span class="spinner"></span></div>',
+    '<button class="secondary" onclick="closeModal()">Close</button>', { wide: true });
+  const list = document.getElementById('recordings-list');
+  try {
+    const res = await fetch(base + '/recordings');
+    if (!res.ok) {
+      list.innerHTML = '<div class="empty-state">Recordings unavailable (' + res.status + ').</div>';
+      return;
+    }
+    const data = await res.json();
+    const recs = data.recordings || [];
+    if (recs.length === 0) {
+      list.innerHTML = '<div class="empty-state">No recordings yet. Use ● Rec on a live tile.</div>';
+      return;
+    }
+    list.innerHTML = recs.map((r) => `
+      <div class="recording-row">
+        <span class="rec-id">${esc(r.id)}</span>
+        <span class="rec-url">${esc(r.url || r.tabId)}</span>
+        <span class="rec-meta">${r.active ? 'recording…' : formatDuration(r.durationMs) + ' · ' + r.frameCount + ' frames · ' + ((r.size || 0) / 1048576).toFixed(1) + ' MB'}</span>
+        <span class="rec-actions">
+          ${r.active ? '' : `
+            <button data-play="${esc(r.id)}">Play</button>
+            <button class="secondary" data-download="${esc(r.id)}">AVI</button>
+            <button class="danger" data-delete="${esc(r.id)}">Delete</button>`}
+        </span>
+      </div>
+    `).join('');
+    list.querySelectorAll('[data-play]').forEach((b) => { b.onclick = () => playRecording(base, b.dataset.play); });
+    list.querySelectorAll('[data-download]').forEach((b) => {
+      b.onclick = () => { window.location.href = base + '/recordings/' + encodeURIComponent(b.dataset.download); };
+    });
+    list.querySelectorAll('[data-delete]').forEach((b) => {
+      b.onclick = async () => {
+        if (!await appConfirm('Delete recording ' + b.dataset.delete + '?', 'Delete recording')) return;
+        await fetch(base + '/recordings/' + encodeURIComponent(b.dataset.delete), { method: 'DELETE' });
+        showRecordings(baseUrl);
+      };
+    });
+  } catch (e) {
+    list.innerHTML = '<div class="empty-state">Failed to load recordings.</div>';
+  }
+}
+
+async function playRecording(base, id) {
+  const res = await fetch(base + '/recordings/' + encodeURIComponent(id) + '?format=json');
+  if (!res.ok) {
+    appAlert('Could not load recording ' + id, 'Recordings');
+    return;
+  }
+  const rec = await res.json();
+  const frames = rec.frames || [];
+  const total = frames.length ? frames[frames.length - 1].t : 0;
+
+  let idx = 0;
+  let timer = null;
+  const stopTimer = () => { if (timer) clearTimeout(timer); timer = null; };
+
+  showModal('Recording ' + id, `
+    <div class="recording-player">
+      <img id="player-frame" alt="">
+      <div class="player-controls">
+        <button id="player-toggle">Play</button>
+        <input type="range" id="player-seek" min="0" max="${Math.max(frames.length - 1, 0)}" value="0">
+        <span id="player-time" class="rec-meta">0:00 / ${formatDuration(total)}</span>
+      </div>
+      <div class="rec-meta">${esc(rec.url || '')} · ${new Date(rec.startedAt).toLocaleString()}${rec.stopReason ? ' · ' + esc(rec.stopReason) : ''}</div>
+    </div>
+  `, '<button class="secondary" id="player-back">Back</button><button class="secondary" onclick="closeModal()">Close</button>', {
+    wide: true,
+    onClose: stopTimer,
+  });
+
+  const img = document.getElementById('player-frame');
+  const seek = document.getElementById('player-seek');
+  const timeEl = document.getElementById('player-time');
+  const toggle = document.getElementById('player-toggle');
+  const frameURL = (n) => base + '/recordings/' + encodeURIComponent(id) + '/frames/' + n;
+
+  const show = (n) => {
+    if (!frames.length) return;
+    idx = Math.max(0, Math.min(n, frames.length - 1));
+    img.src = frameURL(idx);
+    seek.value = idx;
+    timeEl.textContent = formatDuration(frames[idx].t) + ' / ' + formatDuration(total);
+    if (idx + 1 < frames.length) new Image().src = frameURL(idx + 1);
+  };
+  const step = () => {
+    if (idx + 1 >= frames.length) {
+      stopTimer();
+      toggle.textContent = 'Play';
+      return;
+    }
+    const delay = frames[idx + 1].t - frames[idx].t;
+    timer = setTimeout(() => { show(idx + 1); step(); }, delay);
+  };
+
+  toggle.onclick = () => {
+    if (timer) {
+      stopTimer();
+      toggle.textContent = 'Play';
+      return;
+    }
+    if (idx + 1 >= frames.length) show(0);
+    toggle.textContent = 'Pause';
+    step();
+  };
+  seek.oninput = () => { stopTimer(); toggle.textContent = 'Play'; show(parseInt(seek.value, 10)); };
+  document.getElementById('player-back').onclick = () => showRecordings(base);
+  show(0);
 }
 
 function showLiveModal(title) {
@@ -109,6 +411,7 @@ function showLiveModal(title) {
     <div class="live-popup">
       <div class="live-toolbar">
         <button class="refresh-btn" id="live-refresh-btn">Refresh Tabs</button>
+        <button class="secondary" id="live-recordings-btn">Recordings</button>
         <span id="live-tab-count" class="live-count">Loading...</span>
       </div>
       <div id="screencast-grid" class="screencast-grid empty">
@@ -125,6 +428,8 @@ function showLiveModal(title) {
   });
   const refreshBtn = document.getElementById('live-refresh-btn');
   if (refreshBtn) refreshBtn.onclick = () => refreshLiveModal();
+  const recordingsBtn = document.getElementById('live-recordings-btn');
+  if (recordingsBtn) recordingsBtn.onclick = () => showRecordings(liveModalContext && liveModalContext.baseUrl);
 }
 
 async function refreshLiveModal() {
@@ -226,6 +531,7 @@ async function viewMainLive() {
 async function viewInstanceLive(id, port) {
   liveModalContext = {
     load: () => loadInstanceStreams(id, port),
+    baseUrl: 'http://localhost:' + port,
   };
   showLiveModal('LIVE: ' + id);
   await refreshLiveModal();
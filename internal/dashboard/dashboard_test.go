@@ -108,3 +108,56 @@ func TestDashboardProfileIntegration(t *testing.T) {
 	}
 	_ = profMgr
 }
+
+func TestDashboardDisconnectHandler(t *testing.T) {
+	d := NewDashboard(&DashboardConfig{
+		IdleTimeout:       5 * time.Millisecond,
+		DisconnectTimeout: 10 * time.Millisecond,
+		ReaperInterval:    5 * time.Millisecond,
+	})
+	defer d.Shutdown()
+
+	gone := make(chan string, 4)
+	d.SetDisconnectHandler(func(agentID string) { gone <- agentID })
+	d.RecordEvent(AgentEvent{AgentID: "agent1", Action: "GET /tabs", Timestamp: time.Now()})
+
+	select {
+	case id := <-gone:
+		if id != "agent1" {
+			t.Errorf("expected agent1, got %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("disconnect handler not called")
+	}
+
+	// A disconnected agent is reported once, not on every sweep.
+	select {
+	case id := <-gone:
+		t.Errorf("unexpected second disconnect for %s", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDashboardBroadcastEvent(t *testing.T) {
+	d := NewDashboard(nil)
+	defer d.Shutdown()
+
+	ch := make(chan AgentEvent, 1)
+	d.mu.Lock()
+	d.sseConns[ch] = struct{}{}
+	d.mu.Unlock()
+
+	d.BroadcastEvent(AgentEvent{AgentID: "pinchtab", Action: "evict tab", TabID: "t1"})
+
+	select {
+	case evt := <-ch:
+		if evt.Action != "evict tab" || evt.TabID != "t1" {
+			t.Errorf("unexpected event %+v", evt)
+		}
+	default:
+		t.Fatal("event not delivered")
+	}
+	if agents := d.GetAgents(); len(agents) != 0 {
+		t.Errorf("broadcast should not register agents, got %v", agents)
+	}
+}
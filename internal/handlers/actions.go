@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -12,18 +13,30 @@ import (
 	"github.com/pinchtab/pinchtab/internal/web"
 )
 
+// popupWait bounds how long a followPopup click waits for the new tab.
+const popupWait = 10 * time.Second
+
 func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 	var req bridge.ActionRequest
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
 		web.Error(w, 400, fmt.Errorf("decode: %w", err))
 		return
 	}
+	r = withCaller(r, req.Owner)
 
-	ctx, resolvedTabID, err := h.Bridge.TabContext(req.TabID)
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
 	}
+	if !h.checkTabLock(w, r, resolvedTabID, req.Owner) {
+		return
+	}
+	release, ok := h.queueTab(w, r, resolvedTabID)
+	if !ok {
+		return
+	}
+	defer release()
 
 	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
 	defer tCancel()
@@ -51,19 +64,49 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if req.FollowPopup && req.Kind != bridge.ActionClick {
+		web.Error(w, 400, fmt.Errorf("followPopup only applies to click"))
+		return
+	}
 
+	start := time.Now()
 	result, err := h.Bridge.ExecuteAction(tCtx, req.Kind, req)
+	recordAction(req.Kind, err)
 	if err != nil {
-		if strings.HasPrefix(err.Error(), "unknown action") {
+		if errors.Is(err, bridge.ErrUnknownAction) {
 			kinds := h.Bridge.AvailableActions()
 			web.JSON(w, 400, map[string]string{
 				"error": fmt.Sprintf("%s - valid values: %s", err.Error(), strings.Join(kinds, ", ")),
 			})
 			return
 		}
-		web.Error(w, 500, fmt.Errorf("action %s: %w", req.Kind, err))
+		// A click can open a popup and still time out waiting for the
+		// navigation it expected; the popup is real either way.
+		if popups := h.Bridge.PopupsSince(resolvedTabID, start); len(popups) > 0 && tabCrashErr(tCtx) == nil {
+			web.JSON(w, 500, map[string]any{"error": fmt.Sprintf("action %s: %v", req.Kind, err), "popups": popups})
+			return
+		}
+		tabFailed(w, tCtx, 500, fmt.Errorf("action %s: %w", req.Kind, err))
 		return
 	}
+	if result == nil {
+		result = map[string]any{}
+	}
+
+	if req.FollowPopup {
+		wCtx, wCancel := context.WithTimeout(r.Context(), popupWait)
+		popup, err := h.Bridge.WaitForPopup(wCtx, resolvedTabID, start)
+		wCancel()
+		if err != nil {
+			web.Error(w, 408, fmt.Errorf("click succeeded but %w", err))
+			return
+		}
+		h.makeCurrent(r, popup.TabID)
+		result["tabId"] = popup.TabID
+	}
+	if popups := h.Bridge.PopupsSince(resolvedTabID, start); len(popups) > 0 {
+		result["popups"] = popups
+	}
 
 	web.JSON(w, 200, result)
 }
@@ -72,6 +115,7 @@ type actionsRequest struct {
 	TabID       string                 `json:"tabId"`
 	Actions     []bridge.ActionRequest `json:"actions"`
 	StopOnError bool                   `json:"stopOnError"`
+	Owner       string                 `json:"owner"`
 }
 
 type actionResult struct {
@@ -79,6 +123,7 @@ type actionResult struct {
 	Success bool           `json:"success"`
 	Result  map[string]any `json:"result,omitempty"`
 	Error   string         `json:"error,omitempty"`
+	Code    string         `json:"code,omitempty"`
 }
 
 func (h *Handlers) HandleActions(w http.ResponseWriter, r *http.Request) {
@@ -87,17 +132,27 @@ func (h *Handlers) HandleActions(w http.ResponseWriter, r *http.Request) {
 		web.Error(w, 400, fmt.Errorf("decode: %w", err))
 		return
 	}
+	r = withCaller(r, req.Owner)
 
 	if len(req.Actions) == 0 {
 		web.Error(w, 400, fmt.Errorf("actions array is empty"))
 		return
 	}
 
-	ctx, resolvedTabID, err := h.Bridge.TabContext(req.TabID)
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
 	}
+	if !h.checkTabLock(w, r, resolvedTabID, req.Owner) {
+		return
+	}
+	// The batch holds its tab's turn throughout so nothing interleaves.
+	release, ok := h.queueTab(w, r, resolvedTabID)
+	if !ok {
+		return
+	}
+	defer func() { release() }()
 
 	results := make([]actionResult, 0, len(req.Actions))
 
@@ -105,7 +160,15 @@ func (h *Handlers) HandleActions(w http.ResponseWriter, r *http.Request) {
 		if action.TabID == "" {
 			action.TabID = resolvedTabID
 		} else if action.TabID != resolvedTabID {
-			ctx, resolvedTabID, err = h.Bridge.TabContext(action.TabID)
+			release()
+			release = func() {}
+			ctx, resolvedTabID, err = h.tabContext(r, action.TabID)
+			if err == nil {
+				release, err = h.acquireTab(r, resolvedTabID)
+				if err != nil {
+					release = func() {}
+				}
+			}
 			if err != nil {
 				results = append(results, actionResult{
 					Index: i, Success: false,
@@ -117,6 +180,20 @@ func (h *Handlers) HandleActions(w http.ResponseWriter, r *http.Request) {
 				continue
 			}
 		}
+		owner := action.Owner
+		if owner == "" {
+			owner = req.Owner
+		}
+		if lock := h.lockConflict(r, resolvedTabID, owner); lock != nil {
+			results = append(results, actionResult{
+				Index: i, Success: false,
+				Error: fmt.Sprintf("tab %s is locked by %s", resolvedTabID, lock.Owner),
+			})
+			if req.StopOnError {
+				break
+			}
+			continue
+		}
 
 		tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
 
@@ -151,14 +228,27 @@ func (h *Handlers) HandleActions(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		start := time.Now()
 		actionRes, err := h.Bridge.ExecuteAction(tCtx, action.Kind, action)
 		tCancel()
+		recordAction(action.Kind, err)
+		if popups := h.Bridge.PopupsSince(resolvedTabID, start); len(popups) > 0 {
+			if actionRes == nil {
+				actionRes = map[string]any{}
+			}
+			actionRes["popups"] = popups
+		}
 
 		if err != nil {
-			results = append(results, actionResult{
-				Index: i, Success: false,
+			res := actionResult{
+				Index: i, Success: false, Result: actionRes,
 				Error: fmt.Sprintf("action %s: %v", action.Kind, err),
-			})
+			}
+			if crashErr := tabCrashErr(tCtx); crashErr != nil {
+				res.Error = crashErr.Error()
+				res.Code = codeTabCrashed
+			}
+			results = append(results, res)
 			if req.StopOnError {
 				break
 			}
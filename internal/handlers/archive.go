@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/web"
+)
+
+const (
+	archiveFormatMHTML = "mhtml"
+	archiveFormatHTML  = "html"
+)
+
+// HandleArchive captures the full page as MHTML (Page.captureSnapshot) or as a
+// single self-contained HTML file with stylesheets and images inlined.
+//
+// GET /archive?format=mhtml|html[&tabId=<id>][&output=file&path=...][&raw=true]
+func (h *Handlers) HandleArchive(w http.ResponseWriter, r *http.Request) {
+	tabID := r.URL.Query().Get("tabId")
+	output := r.URL.Query().Get("output")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = archiveFormatMHTML
+	}
+	if format != archiveFormatMHTML && format != archiveFormatHTML {
+		web.Error(w, 400, fmt.Errorf("format must be 'mhtml' or 'html'"))
+		return
+	}
+
+	ctx, resolvedTabID, err := h.tabContext(r, tabID)
+	if err != nil {
+		web.Error(w, 404, err)
+		return
+	}
+	if !h.checkTabLock(w, r, resolvedTabID, "") {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	defer tCancel()
+	go web.CancelOnClientDone(r.Context(), tCancel)
+
+	var snapshot string
+	if err := chromedp.Run(tCtx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			snapshot, err = page.CaptureSnapshot().WithFormat(page.CaptureSnapshotFormatMhtml).Do(ctx)
+			return err
+		}),
+	); err != nil {
+		web.Error(w, 500, fmt.Errorf("capture snapshot: %w", err))
+		return
+	}
+
+	var url, title string
+	_ = chromedp.Run(tCtx,
+		chromedp.Location(&url),
+		chromedp.Title(&title),
+	)
+	capturedAt := time.Now().UTC()
+
+	buf := []byte(snapshot)
+	contentType := "multipart/related"
+	if format == archiveFormatHTML {
+		archive, err := bridge.ParseMHTML(buf)
+		if err != nil {
+			web.Error(w, 500, fmt.Errorf("parse snapshot: %w", err))
+			return
+		}
+		buf = archive.InlineHTML()
+		contentType = "text/html; charset=utf-8"
+	}
+
+	sum := sha256.Sum256(buf)
+	meta := map[string]any{
+		"url":        url,
+		"title":      title,
+		"format":     format,
+		"capturedAt": capturedAt.Format(time.RFC3339),
+		"sha256":     hex.EncodeToString(sum[:]),
+		"size":       len(buf),
+	}
+
+	if output == "file" {
+		savePath := r.URL.Query().Get("path")
+		if savePath == "" {
+			archiveDir := filepath.Join(h.Config.StateDir, "archives")
+			if err := os.MkdirAll(archiveDir, 0750); err != nil {
+				web.Error(w, 500, fmt.Errorf("create archive dir: %w", err))
+				return
+			}
+			savePath = filepath.Join(archiveDir, fmt.Sprintf("page-%s.%s", capturedAt.Format("20060102-150405"), format))
+		} else {
+			safe, err := web.SafePath(h.Config.StateDir, savePath)
+			if err != nil {
+				web.Error(w, 400, fmt.Errorf("invalid path: %w", err))
+				return
+			}
+			savePath = safe
+			if err := os.MkdirAll(filepath.Dir(savePath), 0750); err != nil {
+				web.Error(w, 500, fmt.Errorf("create dir: %w", err))
+				return
+			}
+		}
+
+		if err := os.WriteFile(savePath, buf, 0600); err != nil {
+			web.Error(w, 500, fmt.Errorf("write archive: %w", err))
+			return
+		}
+		// Keep provenance next to the archive so it survives on its own.
+		metaData, _ := json.MarshalIndent(meta, "", "  ")
+		if err := os.WriteFile(savePath+".json", metaData, 0600); err != nil {
+			slog.Warn("write archive metadata", "path", savePath, "err", err)
+		}
+
+		meta["path"] = savePath
+		web.JSON(w, 200, meta)
+		return
+	}
+
+	if r.URL.Query().Get("raw") == "true" {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Archive-Url", url)
+		w.Header().Set("X-Archive-Captured-At", meta["capturedAt"].(string))
+		w.Header().Set("X-Archive-Sha256", meta["sha256"].(string))
+		if _, err := w.Write(buf); err != nil {
+			slog.Error("archive write", "err", err)
+		}
+		return
+	}
+
+	meta["base64"] = base64.StdEncoding.EncodeToString(buf)
+	web.JSON(w, 200, meta)
+}
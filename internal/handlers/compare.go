@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/web"
+)
+
+// baselineNameRe leaves out '.' so a name can't collide with the .actual and
+// .diff files stored beside its baseline.
+var baselineNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,127}$`)
+
+type ignoreRegion struct {
+	Selector string  `json:"selector"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+}
+
+type compareRequest struct {
+	TabID          string         `json:"tabId"`
+	Name           string         `json:"name"`
+	Threshold      *float64       `json:"threshold"`
+	MaxDiffPercent float64        `json:"maxDiffPercent"`
+	Ignore         []ignoreRegion `json:"ignore"`
+	Output         string         `json:"output"`
+}
+
+// HandleScreenshotCompare captures the tab and diffs it against a stored
+// baseline. The first comparison for a name stores the capture as baseline.
+//
+// POST /screenshot/compare
+//
+//	{
+//	  "name": "pricing-page",
+//	  "threshold": 0.1,
+//	  "maxDiffPercent": 0.5,
+//	  "ignore": [{"selector": ".clock"}, {"x": 0, "y": 0, "width": 300, "height": 40}]
+//	}
+func (h *Handlers) HandleScreenshotCompare(w http.ResponseWriter, r *http.Request) {
+	var req compareRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		web.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if !baselineNameRe.MatchString(req.Name) {
+		web.Error(w, 400, fmt.Errorf("name required (letters, digits, '_' or '-')"))
+		return
+	}
+	threshold := bridge.DefaultDiffThreshold
+	if req.Threshold != nil {
+		threshold = *req.Threshold
+	}
+	if threshold < 0 || threshold > 1 {
+		web.Error(w, 400, fmt.Errorf("threshold must be between 0 and 1"))
+		return
+	}
+
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		web.Error(w, 404, err)
+		return
+	}
+	if !h.checkTabLock(w, r, resolvedTabID, "") {
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	defer tCancel()
+	go web.CancelOnClientDone(r.Context(), tCancel)
+
+	buf, err := capturePNG(tCtx)
+	if err != nil {
+		web.Error(w, 500, fmt.Errorf("screenshot: %w", err))
+		return
+	}
+
+	dir := h.baselineDir()
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		web.Error(w, 500, fmt.Errorf("create baseline dir: %w", err))
+		return
+	}
+	baselinePath := filepath.Join(dir, req.Name+".png")
+
+	baseData, err := os.ReadFile(baselinePath)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(baselinePath, buf, 0600); err != nil {
+			web.Error(w, 500, fmt.Errorf("write baseline: %w", err))
+			return
+		}
+		web.JSON(w, 200, map[string]any{
+			"name":         req.Name,
+			"created":      true,
+			"match":        true,
+			"baselinePath": baselinePath,
+		})
+		return
+	}
+	if err != nil {
+		web.Error(w, 500, fmt.Errorf("read baseline: %w", err))
+		return
+	}
+
+	baseImg, err := png.Decode(bytes.NewReader(baseData))
+	if err != nil {
+		web.Error(w, 500, fmt.Errorf("decode baseline: %w", err))
+		return
+	}
+	actualImg, err := png.Decode(bytes.NewReader(buf))
+	if err != nil {
+		web.Error(w, 500, fmt.Errorf("decode screenshot: %w", err))
+		return
+	}
+
+	ignore, err := resolveIgnoreRegions(tCtx, req.Ignore)
+	if err != nil {
+		web.Error(w, 400, fmt.Errorf("ignore: %w", err))
+		return
+	}
+
+	result, diffImg := bridge.DiffImages(baseImg, actualImg, threshold, ignore)
+
+	var diffBuf bytes.Buffer
+	if err := png.Encode(&diffBuf, diffImg); err != nil {
+		web.Error(w, 500, fmt.Errorf("encode diff: %w", err))
+		return
+	}
+
+	// Keep the latest capture next to the baseline so it can be accepted later.
+	actualPath := filepath.Join(dir, req.Name+".actual.png")
+	if err := os.WriteFile(actualPath, buf, 0600); err != nil {
+		web.Error(w, 500, fmt.Errorf("write actual: %w", err))
+		return
+	}
+
+	resp := map[string]any{
+		"name":            req.Name,
+		"match":           !result.SizeMismatch && result.MismatchPercent <= req.MaxDiffPercent,
+		"mismatchPercent": math.Round(result.MismatchPercent*1000) / 1000,
+		"mismatchPixels":  result.MismatchPixels,
+		"totalPixels":     result.TotalPixels,
+		"ignoredPixels":   result.IgnoredPixels,
+		"threshold":       threshold,
+		"baselinePath":    baselinePath,
+		"actualPath":      actualPath,
+	}
+	if result.SizeMismatch {
+		resp["sizeMismatch"] = true
+	}
+
+	if req.Output == "file" {
+		diffPath := filepath.Join(dir, req.Name+".diff.png")
+		if err := os.WriteFile(diffPath, diffBuf.Bytes(), 0600); err != nil {
+			web.Error(w, 500, fmt.Errorf("write diff: %w", err))
+			return
+		}
+		resp["diffPath"] = diffPath
+	} else {
+		resp["diff"] = base64.StdEncoding.EncodeToString(diffBuf.Bytes())
+	}
+
+	web.JSON(w, 200, resp)
+}
+
+// HandleBaselineList returns stored baselines.
+//
+// GET /screenshot/baselines
+func (h *Handlers) HandleBaselineList(w http.ResponseWriter, r *http.Request) {
+	entries, err := os.ReadDir(h.baselineDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		web.Error(w, 500, err)
+		return
+	}
+
+	type baselineInfo struct {
+		Name     string    `json:"name"`
+		Size     int64     `json:"size"`
+		Modified time.Time `json:"modified"`
+		Pending  bool      `json:"pending"`
+	}
+	pending := make(map[string]bool)
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".actual.png"); ok {
+			pending[name] = true
+		}
+	}
+
+	list := make([]baselineInfo, 0)
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".png")
+		if !ok || !baselineNameRe.MatchString(name) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		list = append(list, baselineInfo{
+			Name:     name,
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+			Pending:  pending[name],
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	web.JSON(w, 200, map[string]any{"baselines": list})
+}
+
+// HandleBaselineAccept promotes the last compared capture to be the baseline.
+//
+// POST /screenshot/baselines/{name}/accept
+func (h *Handlers) HandleBaselineAccept(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !baselineNameRe.MatchString(name) {
+		web.Error(w, 400, fmt.Errorf("invalid baseline name"))
+		return
+	}
+	dir := h.baselineDir()
+	actualPath := filepath.Join(dir, name+".actual.png")
+	baselinePath := filepath.Join(dir, name+".png")
+
+	if err := os.Rename(actualPath, baselinePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			web.Error(w, 404, fmt.Errorf("no pending capture for %q — run /screenshot/compare first", name))
+			return
+		}
+		web.Error(w, 500, fmt.Errorf("accept baseline: %w", err))
+		return
+	}
+	_ = os.Remove(filepath.Join(dir, name+".diff.png"))
+
+	web.JSON(w, 200, map[string]any{"name": name, "accepted": true, "baselinePath": baselinePath})
+}
+
+// HandleBaselineReplace stores a new baseline, either from an uploaded PNG
+// ("image", base64) or from a fresh capture of the tab.
+//
+// POST /screenshot/baselines/{name}
+func (h *Handlers) HandleBaselineReplace(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !baselineNameRe.MatchString(name) {
+		web.Error(w, 400, fmt.Errorf("invalid baseline name"))
+		return
+	}
+
+	var req struct {
+		TabID string `json:"tabId"`
+		Image string `json:"image"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 32*maxBodySize)).Decode(&req); err != nil {
+			web.Error(w, 400, fmt.Errorf("decode: %w", err))
+			return
+		}
+	}
+
+	var buf []byte
+	if req.Image != "" {
+		data, err := base64.StdEncoding.DecodeString(req.Image)
+		if err != nil {
+			web.Error(w, 400, fmt.Errorf("image: invalid base64: %w", err))
+			return
+		}
+		if _, err := png.DecodeConfig(bytes.NewReader(data)); err != nil {
+			web.Error(w, 400, fmt.Errorf("image must be a PNG: %w", err))
+			return
+		}
+		buf = data
+	} else {
+		ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+		if err != nil {
+			web.Error(w, 404, err)
+			return
+		}
+		if !h.checkTabLock(w, r, resolvedTabID, "") {
+			return
+		}
+		tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+		defer tCancel()
+		go web.CancelOnClientDone(r.Context(), tCancel)
+
+		buf, err = capturePNG(tCtx)
+		if err != nil {
+			web.Error(w, 500, fmt.Errorf("screenshot: %w", err))
+			return
+		}
+	}
+
+	dir := h.baselineDir()
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		web.Error(w, 500, fmt.Errorf("create baseline dir: %w", err))
+		return
+	}
+	baselinePath := filepath.Join(dir, name+".png")
+	if err := os.WriteFile(baselinePath, buf, 0600); err != nil {
+		web.Error(w, 500, fmt.Errorf("write baseline: %w", err))
+		return
+	}
+	_ = os.Remove(filepath.Join(dir, name+".actual.png"))
+	_ = os.Remove(filepath.Join(dir, name+".diff.png"))
+
+	web.JSON(w, 200, map[string]any{"name": name, "replaced": true, "baselinePath": baselinePath, "size": len(buf)})
+}
+
+// HandleBaselineDelete removes a baseline and any pending capture or diff.
+//
+// DELETE /screenshot/baselines/{name}
+func (h *Handlers) HandleBaselineDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !baselineNameRe.MatchString(name) {
+		web.Error(w, 400, fmt.Errorf("invalid baseline name"))
+		return
+	}
+	dir := h.baselineDir()
+	if err := os.Remove(filepath.Join(dir, name+".png")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			web.Error(w, 404, fmt.Errorf("baseline %q not found", name))
+			return
+		}
+		web.Error(w, 500, err)
+		return
+	}
+	_ = os.Remove(filepath.Join(dir, name+".actual.png"))
+	_ = os.Remove(filepath.Join(dir, name+".diff.png"))
+
+	web.JSON(w, 200, map[string]any{"name": name, "deleted": true})
+}
+
+func (h *Handlers) baselineDir() string {
+	return filepath.Join(h.Config.StateDir, "baselines")
+}
+
+// capturePNG takes a lossless viewport screenshot, so diffs aren't polluted
+// by JPEG artefacts.
+func capturePNG(ctx context.Context) ([]byte, error) {
+	var buf []byte
+	err := chromedp.Run(ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			buf, err = page.CaptureScreenshot().
+				WithFormat(page.CaptureScreenshotFormatPng).
+				Do(ctx)
+			return err
+		}),
+	)
+	return buf, err
+}
+
+// resolveIgnoreRegions converts ignore entries to image rectangles in device
+// pixels. Selector entries expand to every matching element.
+func resolveIgnoreRegions(ctx context.Context, regions []ignoreRegion) ([]image.Rectangle, error) {
+	if len(regions) == 0 {
+		return nil, nil
+	}
+
+	var dpr float64
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`window.devicePixelRatio`, &dpr)); err != nil || dpr <= 0 {
+		dpr = 1
+	}
+	toRect := func(x, y, w, h float64) image.Rectangle {
+		return image.Rect(
+			int(math.Floor(x*dpr)), int(math.Floor(y*dpr)),
+			int(math.Ceil((x+w)*dpr)), int(math.Ceil((y+h)*dpr)),
+		)
+	}
+
+	rects := make([]image.Rectangle, 0, len(regions))
+	for _, reg := range regions {
+		if reg.Selector == "" {
+			if reg.Width <= 0 || reg.Height <= 0 {
+				return nil, fmt.Errorf("region needs a selector or a positive width and height")
+			}
+			rects = append(rects, toRect(reg.X, reg.Y, reg.Width, reg.Height))
+			continue
+		}
+
+		sel, _ := json.Marshal(reg.Selector)
+		js := fmt.Sprintf(`Array.from(document.querySelectorAll(%s)).map(e => {
+			const r = e.getBoundingClientRect();
+			return {x: r.x, y: r.y, width: r.width, height: r.height};
+		})`, sel)
+		var boxes []bridge.Box
+		if err := chromedp.Run(ctx, chromedp.Evaluate(js, &boxes)); err != nil {
+			return nil, fmt.Errorf("selector %q: %w", reg.Selector, err)
+		}
+		for _, b := range boxes {
+			rects = append(rects, toRect(b.X, b.Y, b.Width, b.Height))
+		}
+	}
+	return rects, nil
+}
@@ -19,7 +19,7 @@ func (h *Handlers) HandleGetCookies(w http.ResponseWriter, r *http.Request) {
 	url := r.URL.Query().Get("url")
 	name := r.URL.Query().Get("name")
 
-	ctx, _, err := h.Bridge.TabContext(tabID)
+	ctx, _, err := h.tabContext(r, tabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
@@ -111,11 +111,19 @@ func (h *Handlers) HandleSetCookies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, _, err := h.Bridge.TabContext(req.TabID)
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
 	}
+	if !h.checkTabLock(w, r, resolvedTabID, "") {
+		return
+	}
+	release, ok := h.queueTab(w, r, resolvedTabID)
+	if !ok {
+		return
+	}
+	defer release()
 
 	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
 	defer tCancel()
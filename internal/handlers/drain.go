@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/web"
+)
+
+// Drainer turns new requests away once shutdown starts, so the ones already
+// running can finish before state is saved and Chrome goes down.
+type Drainer struct {
+	// RetryAfter is sent to turned-away clients; a restarted server is
+	// usually back by then.
+	RetryAfter time.Duration
+
+	draining atomic.Bool
+}
+
+// Start makes every later request fail with 503.
+func (d *Drainer) Start() { d.draining.Store(true) }
+
+// Draining reports whether shutdown has started.
+func (d *Drainer) Draining() bool { return d.draining.Load() }
+
+// DrainMiddleware answers 503 with Retry-After while d is draining.
+func DrainMiddleware(d *Drainer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.Draining() {
+			retry := max(int(d.RetryAfter/time.Second), 1)
+			w.Header().Set("Retry-After", strconv.Itoa(retry))
+			w.Header().Set("Connection", "close")
+			web.Error(w, 503, fmt.Errorf("shutting down"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -25,6 +25,12 @@ func (m *failMockBridge) ListTargets() ([]*target.Info, error) {
 	return nil, fmt.Errorf("list targets failed")
 }
 
+func (m *failMockBridge) CurrentTab(agentID string) string { return "" }
+
+func (m *failMockBridge) MapTabID(tabID string) string { return tabID }
+
+func (m *failMockBridge) RecoveryStatus() bridge.RecoveryStatus { return bridge.RecoveryStatus{} }
+
 func TestHandleActions_EmptyArray(t *testing.T) {
 	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
 	req := httptest.NewRequest("POST", "/actions", bytes.NewReader([]byte(`{"actions": []}`)))
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func tinyPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func baselineMux(h *Handlers) *http.ServeMux {
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux, nil)
+	return mux
+}
+
+func TestHandleScreenshotCompare_Validation(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+
+	cases := []string{
+		`{}`,
+		`{"name": "../escape"}`,
+		`{"name": "home.actual"}`,
+		`{"name": "ok", "threshold": 2}`,
+		`not json`,
+	}
+	for _, body := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/screenshot/compare", strings.NewReader(body))
+		h.HandleScreenshotCompare(w, r)
+		if w.Code != 400 {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestHandleScreenshotCompare_NoTab(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/screenshot/compare", strings.NewReader(`{"name":"home"}`))
+	h.HandleScreenshotCompare(w, r)
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestBaselineLifecycle(t *testing.T) {
+	stateDir := t.TempDir()
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: stateDir}, nil, nil, nil)
+	mux := baselineMux(h)
+
+	body, _ := json.Marshal(map[string]string{"image": base64.StdEncoding.EncodeToString(tinyPNG(t))})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/screenshot/baselines/home", bytes.NewReader(body)))
+	if w.Code != 200 {
+		t.Fatalf("replace: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	dir := filepath.Join(stateDir, "baselines")
+	if err := os.WriteFile(filepath.Join(dir, "home.actual.png"), tinyPNG(t), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/screenshot/baselines", nil))
+	var list struct {
+		Baselines []struct {
+			Name    string `json:"name"`
+			Pending bool   `json:"pending"`
+		} `json:"baselines"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Baselines) != 1 || list.Baselines[0].Name != "home" || !list.Baselines[0].Pending {
+		t.Fatalf("unexpected list: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/screenshot/baselines/home/accept", nil))
+	if w.Code != 200 {
+		t.Fatalf("accept: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "home.actual.png")); !os.IsNotExist(err) {
+		t.Error("expected pending capture to be consumed")
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/screenshot/baselines/home/accept", nil))
+	if w.Code != 404 {
+		t.Errorf("second accept: expected 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/screenshot/baselines/home", nil))
+	if w.Code != 200 {
+		t.Fatalf("delete: expected 200, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/screenshot/baselines/home", nil))
+	if w.Code != 404 {
+		t.Errorf("second delete: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleBaselineReplace_RejectsNonPNG(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	body, _ := json.Marshal(map[string]string{"image": base64.StdEncoding.EncodeToString([]byte("nope"))})
+	w := httptest.NewRecorder()
+	baselineMux(h).ServeHTTP(w, httptest.NewRequest("POST", "/screenshot/baselines/home", bytes.NewReader(body)))
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
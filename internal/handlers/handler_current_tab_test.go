@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// currentTabBridge remembers current tabs per agent and which tab was last
+// activated.
+type currentTabBridge struct {
+	mockBridge
+	current   map[string]string
+	activated string
+}
+
+func newCurrentTabBridge() *currentTabBridge {
+	return &currentTabBridge{current: map[string]string{}}
+}
+
+func (m *currentTabBridge) TabContext(tabID string) (context.Context, string, error) {
+	if tabID == "" {
+		tabID = "tab1"
+	}
+	if tabID == "gone" {
+		return nil, "", fmt.Errorf("tab %s not found", tabID)
+	}
+	ctx, _ := chromedp.NewContext(context.Background())
+	return ctx, tabID, nil
+}
+
+func (m *currentTabBridge) SetCurrentTab(agentID, tabID string) { m.current[agentID] = tabID }
+
+func (m *currentTabBridge) CurrentTab(agentID string) string { return m.current[agentID] }
+
+func (m *currentTabBridge) ActivateTab(tabID string) error {
+	m.activated = tabID
+	return nil
+}
+
+func TestTabContext_DefaultsToCurrentTab(t *testing.T) {
+	b := newCurrentTabBridge()
+	b.current["agent-a"] = "tab7"
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/snapshot", nil)
+	req.Header.Set("X-Agent-Id", "agent-a")
+	if _, id, err := h.tabContext(req, ""); err != nil || id != "tab7" {
+		t.Errorf("expected current tab7, got %q (%v)", id, err)
+	}
+
+	// Other agents keep the old first-target default.
+	req.Header.Set("X-Agent-Id", "agent-b")
+	if _, id, _ := h.tabContext(req, ""); id != "tab1" {
+		t.Errorf("expected fallback tab1, got %q", id)
+	}
+}
+
+func TestTabContext_StaleCurrentTabFallsBack(t *testing.T) {
+	b := newCurrentTabBridge()
+	b.current[""] = "gone"
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/snapshot", nil)
+	if _, id, err := h.tabContext(req, ""); err != nil || id != "tab1" {
+		t.Errorf("expected fallback tab1, got %q (%v)", id, err)
+	}
+}
+
+func TestHandleTabActivate(t *testing.T) {
+	b := newCurrentTabBridge()
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/tab/activate", bytes.NewReader([]byte(`{"tabId":"tab9"}`)))
+	req.Header.Set("X-Agent-Id", "agent-a")
+	w := httptest.NewRecorder()
+	h.HandleTabActivate(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if b.activated != "tab9" {
+		t.Errorf("expected tab9 activated, got %q", b.activated)
+	}
+	if b.current["agent-a"] != "tab9" {
+		t.Errorf("expected tab9 current for agent-a, got %q", b.current["agent-a"])
+	}
+}
+
+func TestHandleTabActivate_Errors(t *testing.T) {
+	h := New(newCurrentTabBridge(), &config.RuntimeConfig{}, nil, nil, nil)
+
+	for body, want := range map[string]int{`{}`: 400, `{"tabId":"gone"}`: 404, `{bad`: 400} {
+		req := httptest.NewRequest("POST", "/tab/activate", bytes.NewReader([]byte(body)))
+		w := httptest.NewRecorder()
+		h.HandleTabActivate(w, req)
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", body, want, w.Code)
+		}
+	}
+}
+
+func TestHandleTab_NewBecomesCurrent(t *testing.T) {
+	b := newCurrentTabBridge()
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`{"action":"new"}`)))
+	req.Header.Set("X-Agent-Id", "agent-a")
+	w := httptest.NewRecorder()
+	h.HandleTab(w, req)
+
+	if b.current["agent-a"] != "new-tab" {
+		t.Errorf("expected new-tab current, got %q", b.current["agent-a"])
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/dashboard"
+)
+
+func TestHandoff_Lifecycle(t *testing.T) {
+	b := newLockingBridge()
+	dash := dashboard.NewDashboard(nil)
+	defer dash.Shutdown()
+	h := New(b, &config.RuntimeConfig{}, nil, dash, nil)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux, nil)
+
+	// The agent holds its own lock; the handoff transfers it to the operator.
+	if err := b.Lock("tab1", "agent-1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/handoff", strings.NewReader(`{"tabId":"tab1","reason":"captcha"}`))
+	req.Header.Set("X-Agent-Id", "agent-1")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Fatalf("handoff: %d %s", w.Code, w.Body.String())
+	}
+	var ho handoff
+	_ = json.Unmarshal(w.Body.Bytes(), &ho)
+	if ho.Status != "waiting" || ho.AgentID != "agent-1" || ho.Reason != "captcha" {
+		t.Fatalf("unexpected handoff %+v", ho)
+	}
+	if info := b.TabLockInfo("tab1"); info == nil || !strings.HasPrefix(info.Owner, handoffLockPrefix) {
+		t.Fatalf("expected operator lock, got %+v", info)
+	}
+	if agents := dash.GetAgents(); len(agents) != 1 || agents[0].LastAction != "HANDOFF requested" {
+		t.Errorf("expected handoff dashboard event, got %+v", agents)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/handoff/"+ho.ID+"/wait?timeoutSec=0", nil))
+	if w.Code != 408 {
+		t.Errorf("expected 408 before resume, got %d", w.Code)
+	}
+
+	waited := make(chan *httptest.ResponseRecorder)
+	go func() {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/handoff/"+ho.ID+"/wait?timeoutSec=5", nil))
+		waited <- w
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/handoff/"+ho.ID+"/resume", strings.NewReader(`{"note":"solved"}`)))
+	if w.Code != 200 {
+		t.Fatalf("resume: %d %s", w.Code, w.Body.String())
+	}
+
+	select {
+	case w := <-waited:
+		var got handoff
+		_ = json.Unmarshal(w.Body.Bytes(), &got)
+		if w.Code != 200 || got.Status != "resumed" || got.Note != "solved" {
+			t.Errorf("wait returned %d %+v", w.Code, got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("wait did not return after resume")
+	}
+
+	if info := b.TabLockInfo("tab1"); info != nil {
+		t.Errorf("expected lock released after resume, got %+v", info)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/handoff/"+ho.ID+"/resume", nil))
+	if w.Code != 409 {
+		t.Errorf("second resume: expected 409, got %d", w.Code)
+	}
+}
+
+func TestHandoff_LockedByOtherAgent(t *testing.T) {
+	b := newLockingBridge()
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+	if err := b.Lock("tab1", "agent-2", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/handoff", strings.NewReader(`{"tabId":"tab1","reason":"2fa","agentId":"agent-1"}`))
+	w := httptest.NewRecorder()
+	h.HandleHandoff(w, req)
+	if w.Code != 409 {
+		t.Errorf("expected 409, got %d", w.Code)
+	}
+}
+
+func TestHandoff_Validation(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{}, nil, nil, nil)
+	for body, want := range map[string]int{
+		`{"tabId":"tab1"}`:                    400,
+		`{"tabId":"nope","reason":"captcha"}`: 404,
+	} {
+		w := httptest.NewRecorder()
+		h.HandleHandoff(w, httptest.NewRequest("POST", "/handoff", strings.NewReader(body)))
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", body, want, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/handoff/ho-missing/wait", nil)
+	req.SetPathValue("id", "ho-missing")
+	h.HandleHandoffWait(w, req)
+	if w.Code != 404 {
+		t.Errorf("unknown handoff: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandoff_ExpiresWithoutLock(t *testing.T) {
+	b := newLockingBridge()
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux, nil)
+
+	req := httptest.NewRequest("POST", "/handoff", strings.NewReader(`{"tabId":"tab1","reason":"captcha","agentId":"agent-1"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var ho handoff
+	_ = json.Unmarshal(w.Body.Bytes(), &ho)
+
+	// An operator breaking the lock ends the handoff: nobody will resume it.
+	_ = b.Unlock("tab1", handoffLockOwner(ho.ID))
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/handoff/"+ho.ID+"/wait?timeoutSec=5", nil))
+	var got handoff
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if w.Code != 200 || got.Status != "expired" {
+		t.Errorf("wait: expected expired, got %d %+v", w.Code, got)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/handoff/"+ho.ID+"/resume", nil))
+	if w.Code != 409 {
+		t.Errorf("resume after expiry: expected 409, got %d", w.Code)
+	}
+}
+
+func TestHandoff_PrunesEnded(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	h.handoffs.items["ho-old"] = &handoff{ID: "ho-old", Status: "expired", endedAt: time.Now().Add(-2 * handoffRetention)}
+	h.handoffs.items["ho-new"] = &handoff{ID: "ho-new", Status: "resumed", endedAt: time.Now()}
+
+	w := httptest.NewRecorder()
+	h.HandleHandoffList(w, httptest.NewRequest("GET", "/handoff", nil))
+	if strings.Contains(w.Body.String(), "ho-old") || !strings.Contains(w.Body.String(), "ho-new") {
+		t.Errorf("expected only the recent handoff, got %s", w.Body.String())
+	}
+}
+
+func TestHandoff_StrictScoping(t *testing.T) {
+	h := strictHandlers(newOwnedBridge())
+	for _, ho := range []*handoff{
+		{ID: "ho-a", TabID: "tab-a", AgentID: "agent-a", Status: "resumed"},
+		{ID: "ho-b", TabID: "tab-b", AgentID: "agent-b", Status: "resumed"},
+	} {
+		h.handoffs.items[ho.ID] = ho
+	}
+
+	req := httptest.NewRequest("GET", "/handoff", nil)
+	req.Header.Set("X-Agent-Id", "agent-a")
+	w := httptest.NewRecorder()
+	h.HandleHandoffList(w, req)
+	if !strings.Contains(w.Body.String(), "ho-a") || strings.Contains(w.Body.String(), "ho-b") {
+		t.Errorf("expected only agent-a's handoff, got %s", w.Body.String())
+	}
+
+	for id, want := range map[string]int{"ho-a": 200, "ho-b": 404} {
+		req := httptest.NewRequest("GET", "/handoff/"+id, nil)
+		req.SetPathValue("id", id)
+		req.Header.Set("X-Agent-Id", "agent-a")
+		w := httptest.NewRecorder()
+		h.HandleHandoffGet(w, req)
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", id, want, w.Code)
+		}
+	}
+}
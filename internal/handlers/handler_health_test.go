@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// lockedBridge reports a lock and a queued request on tab1.
+type lockedBridge struct {
+	mockBridge
+}
+
+func (m *lockedBridge) TabLockInfo(tabID string) *bridge.LockInfo {
+	if tabID != "tab1" {
+		return nil
+	}
+	return &bridge.LockInfo{Owner: "agent-a", ExpiresAt: time.Now().Add(time.Minute), Waiters: 1}
+}
+
+func (m *lockedBridge) TabQueueDepth(tabID string) int {
+	if tabID == "tab1" {
+		return 2
+	}
+	return 0
+}
+
+func TestHandleReady(t *testing.T) {
+	tests := []struct {
+		name string
+		b    bridge.BridgeAPI
+		code int
+	}{
+		{"connected", &mockBridge{}, 200},
+		{"disconnected", &failMockBridge{}, 503},
+		{"recovering", &recoveryBridge{status: bridge.RecoveryStatus{State: bridge.RecoveryInProgress}}, 503},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(tt.b, &config.RuntimeConfig{}, nil, nil, nil)
+			w := httptest.NewRecorder()
+			h.HandleReady(w, httptest.NewRequest("GET", "/health/ready", nil))
+			if w.Code != tt.code {
+				t.Errorf("expected %d, got %d", tt.code, w.Code)
+			}
+			var body map[string]any
+			_ = json.Unmarshal(w.Body.Bytes(), &body)
+			if len(body) != 1 || body["status"] == nil {
+				t.Errorf("probe should only carry a status, got %v", body)
+			}
+
+			w = httptest.NewRecorder()
+			h.HandleLive(w, httptest.NewRequest("GET", "/health/live", nil))
+			if w.Code != 200 {
+				t.Errorf("live: expected 200, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleHealth_DisconnectedStays200(t *testing.T) {
+	h := New(&failMockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleHealth(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != 200 {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestHandleHealth_Verbose(t *testing.T) {
+	h := New(&lockedBridge{}, &config.RuntimeConfig{StealthLevel: "full"}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleHealth(w, httptest.NewRequest("GET", "/health?verbose=true", nil))
+
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	browser, _ := resp["browser"].(map[string]any)
+	if browser["product"] != "HeadlessChrome/144.0.7559.133" {
+		t.Errorf("expected browser version, got %v", resp["browser"])
+	}
+	if resp["stealth"] != "full" {
+		t.Errorf("expected stealth level, got %v", resp["stealth"])
+	}
+	if resp["configDigest"] != h.Config.Digest() {
+		t.Errorf("expected config digest, got %v", resp["configDigest"])
+	}
+	if _, ok := resp["uptimeSec"]; !ok {
+		t.Error("expected uptimeSec")
+	}
+	locks, _ := resp["locks"].([]any)
+	if len(locks) != 1 || locks[0].(map[string]any)["owner"] != "agent-a" {
+		t.Errorf("expected tab1 lock, got %v", resp["locks"])
+	}
+	queues, _ := resp["queues"].(map[string]any)
+	if queues["tab1"] != float64(2) {
+		t.Errorf("expected tab1 queue depth, got %v", resp["queues"])
+	}
+
+	if _, ok := healthStatus(t, &lockedBridge{})["browser"]; ok {
+		t.Error("browser diagnostics should need verbose=true")
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestTabLock_EnforcedOnMutatingHandlers(t *testing.T) {
+	b := newLockingBridge()
+	if err := b.Lock("tab1", "agent-a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second, NavigateTimeout: time.Second}, nil, nil, nil)
+
+	cases := []struct {
+		name    string
+		handler func(w *httptest.ResponseRecorder, body string)
+		body    string
+	}{
+		{"navigate", func(w *httptest.ResponseRecorder, body string) {
+			h.HandleNavigate(w, httptest.NewRequest("POST", "/navigate", bytes.NewReader([]byte(body))))
+		}, `{"url":"https://example.com"}`},
+		{"evaluate", func(w *httptest.ResponseRecorder, body string) {
+			h.HandleEvaluate(w, httptest.NewRequest("POST", "/evaluate", bytes.NewReader([]byte(body))))
+		}, `{"expression":"1"}`},
+		{"action", func(w *httptest.ResponseRecorder, body string) {
+			h.HandleAction(w, httptest.NewRequest("POST", "/action", bytes.NewReader([]byte(body))))
+		}, `{"kind":"click","selector":"a"}`},
+		{"actions", func(w *httptest.ResponseRecorder, body string) {
+			h.HandleActions(w, httptest.NewRequest("POST", "/actions", bytes.NewReader([]byte(body))))
+		}, `{"actions":[{"kind":"click","selector":"a"}]}`},
+		{"upload", func(w *httptest.ResponseRecorder, body string) {
+			h.HandleUpload(w, httptest.NewRequest("POST", "/upload", bytes.NewReader([]byte(body))))
+		}, `{"files":["aGk="]}`},
+		{"tab close", func(w *httptest.ResponseRecorder, body string) {
+			h.HandleTab(w, httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(body))))
+		}, `{"action":"close","tabId":"tab1"}`},
+		{"cookies", func(w *httptest.ResponseRecorder, body string) {
+			h.HandleSetCookies(w, httptest.NewRequest("POST", "/cookies", bytes.NewReader([]byte(body))))
+		}, `{"url":"https://example.com","cookies":[{"name":"a","value":"b"}]}`},
+		{"fingerprint", func(w *httptest.ResponseRecorder, body string) {
+			h.HandleFingerprintRotate(w, httptest.NewRequest("POST", "/fingerprint/rotate", bytes.NewReader([]byte(body))))
+		}, `{}`},
+		{"pdf", func(w *httptest.ResponseRecorder, body string) {
+			h.HandlePDF(w, httptest.NewRequest("GET", "/pdf", nil))
+		}, ``},
+		{"compare", func(w *httptest.ResponseRecorder, body string) {
+			h.HandleScreenshotCompare(w, httptest.NewRequest("POST", "/screenshot/compare", bytes.NewReader([]byte(body))))
+		}, `{"name":"home"}`},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		tc.handler(w, tc.body)
+		if w.Code != 423 {
+			t.Errorf("%s: expected 423 for another caller, got %d", tc.name, w.Code)
+		}
+	}
+}
+
+func TestTabLock_OwnerAndOverride(t *testing.T) {
+	b := newLockingBridge()
+	if err := b.Lock("tab1", "agent-a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	close := func(body string, header map[string]string) int {
+		req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(body)))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.HandleTab(w, req)
+		return w.Code
+	}
+
+	if code := close(`{"action":"close","tabId":"tab1"}`, map[string]string{"X-Agent-Id": "agent-b"}); code != 423 {
+		t.Errorf("other agent: expected 423, got %d", code)
+	}
+	if code := close(`{"action":"close","tabId":"tab1"}`, map[string]string{"X-Agent-Id": "agent-a"}); code != 200 {
+		t.Errorf("lock holder via header: expected 200, got %d", code)
+	}
+	if code := close(`{"action":"close","tabId":"tab1","owner":"agent-a"}`, nil); code != 200 {
+		t.Errorf("lock holder via owner: expected 200, got %d", code)
+	}
+	if code := close(`{"action":"close","tabId":"tab1"}`, map[string]string{"X-Agent-Id": "agent-b", "X-Lock-Override": "true"}); code != 423 {
+		t.Errorf("override header: expected 423, got %d", code)
+	}
+}
+
+func TestTabUnlock_NoOverride(t *testing.T) {
+	b := newLockingBridge()
+	if err := b.Lock("tab1", "agent-a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	// Only the holder can unlock over HTTP; the operator takes over from the
+	// dashboard instead.
+	req := httptest.NewRequest("POST", "/tab/unlock", bytes.NewReader([]byte(`{"tabId":"tab1"}`)))
+	req.Header.Set("X-Agent-Id", "dashboard")
+	req.Header.Set("X-Lock-Override", "1")
+	w := httptest.NewRecorder()
+	h.HandleTabUnlock(w, req)
+	if w.Code != 409 {
+		t.Errorf("expected 409, got %d", w.Code)
+	}
+	if info := b.TabLockInfo("tab1"); info == nil || info.Owner != "agent-a" {
+		t.Errorf("lock should be kept, got %+v", info)
+	}
+}
+
+func TestHandleTabLock_Wait(t *testing.T) {
+	b := newLockingBridge()
+	_ = b.Lock("tab1", "agent-a", time.Minute)
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = b.Unlock("tab1", "agent-a")
+	}()
+	req := httptest.NewRequest("POST", "/tab/lock", bytes.NewReader([]byte(`{"tabId":"tab1","owner":"agent-b","waitSec":5}`)))
+	w := httptest.NewRecorder()
+	h.HandleTabLock(w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected 200 after waiting, got %d: %s", w.Code, w.Body.String())
+	}
+	if info := b.TabLockInfo("tab1"); info == nil || info.Owner != "agent-b" {
+		t.Errorf("expected agent-b to hold the lock, got %+v", info)
+	}
+
+	req = httptest.NewRequest("POST", "/tab/lock", bytes.NewReader([]byte(`{"tabId":"tab1","owner":"agent-c"}`)))
+	w = httptest.NewRecorder()
+	h.HandleTabLock(w, req)
+	if w.Code != 409 {
+		t.Errorf("expected 409 without waitSec, got %d", w.Code)
+	}
+}
+
+func TestHandleTabLockRenew(t *testing.T) {
+	b := newLockingBridge()
+	_ = b.Lock("tab1", "agent-a", time.Second)
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/tab/lock/renew", bytes.NewReader([]byte(`{"tabId":"tab1","owner":"agent-b"}`)))
+	w := httptest.NewRecorder()
+	h.HandleTabLockRenew(w, req)
+	if w.Code != 409 {
+		t.Errorf("non-holder: expected 409, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/tab/lock/renew", bytes.NewReader([]byte(`{"tabId":"tab1","timeoutSec":30}`)))
+	req.Header.Set("X-Agent-Id", "agent-a")
+	w = httptest.NewRecorder()
+	h.HandleTabLockRenew(w, req)
+	if w.Code != 200 {
+		t.Fatalf("holder: expected 200, got %d", w.Code)
+	}
+	if info := b.TabLockInfo("tab1"); time.Until(info.ExpiresAt) < 20*time.Second {
+		t.Errorf("lease not extended: %v", time.Until(info.ExpiresAt))
+	}
+}
+
+// remappedLockBridge maps the pre-relaunch ID "old1" to "tab1".
+type remappedLockBridge struct{ *lockingBridge }
+
+func (m remappedLockBridge) MapTabID(tabID string) string {
+	if tabID == "old1" {
+		return "tab1"
+	}
+	return tabID
+}
+
+func TestHandleTabLock_MapsTabID(t *testing.T) {
+	b := remappedLockBridge{newLockingBridge()}
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	h.HandleTabLock(w, httptest.NewRequest("POST", "/tab/lock", bytes.NewReader([]byte(`{"tabId":"old1","owner":"agent-a"}`))))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if info := b.locks.Get("tab1"); info == nil || info.Owner != "agent-a" {
+		t.Errorf("lock should be taken on the live tab ID, got %+v", info)
+	}
+}
@@ -58,3 +58,13 @@ func TestHandleText_RawMode(t *testing.T) {
 		t.Errorf("expected 404, got %d", w.Code)
 	}
 }
+
+func TestHandleScreenshot_Annotate_NoTab(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("GET", "/screenshot?annotate=true", nil)
+	w := httptest.NewRecorder()
+	h.HandleScreenshot(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func scrape(t *testing.T, h *Handlers) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.HandleMetrics(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	return w.Body.String()
+}
+
+func TestMetrics_RequestsByPattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics-test/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	})
+	handler := LoggingMiddleware(mux)
+	for _, id := range []string{"a", "b"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics-test/"+id, nil))
+	}
+
+	out := scrape(t, New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil))
+	for _, want := range []string{
+		`pinchtab_http_requests_total{method="GET",endpoint="/metrics-test/{id}",status="404"} 2`,
+		`pinchtab_http_request_duration_seconds_count{method="GET",endpoint="/metrics-test/{id}",status="404"} 2`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %q", want)
+		}
+	}
+}
+
+func TestMetrics_UnknownMethodIsOther(t *testing.T) {
+	handler := LoggingMiddleware(http.NewServeMux())
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("BREW", "/metrics-brew", nil))
+
+	out := scrape(t, New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil))
+	if strings.Contains(out, `method="BREW"`) {
+		t.Error("client-chosen method leaked into labels")
+	}
+	if !strings.Contains(out, `pinchtab_http_requests_total{method="OTHER",endpoint="unmatched",status="404"}`) {
+		t.Errorf("expected the request under OTHER, got:\n%s", out)
+	}
+}
+
+func TestMetrics_ActionsAndGauges(t *testing.T) {
+	recordAction("metrics-test-kind", nil)
+	recordAction("metrics-test-kind", errors.New("boom"))
+	recordAction("metrics-test-bogus", fmt.Errorf("%w: metrics-test-bogus", bridge.ErrUnknownAction))
+
+	out := scrape(t, New(&lockedBridge{}, &config.RuntimeConfig{}, nil, nil, nil))
+	for _, want := range []string{
+		`pinchtab_actions_total{kind="metrics-test-kind"} 2`,
+		`pinchtab_action_errors_total{kind="metrics-test-kind"} 1`,
+		`pinchtab_tabs_open 1`,
+		`pinchtab_locks_held 1`,
+		`pinchtab_chrome_up 1`,
+		`pinchtab_screencast_subscribers 0`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "metrics-test-bogus") {
+		t.Error("unknown action kinds must not become label values")
+	}
+
+	if out := scrape(t, New(&failMockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)); !strings.Contains(out, "pinchtab_chrome_up 0\n") {
+		t.Error("expected chrome_up 0 when Chrome is disconnected")
+	}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// ownedBridge tracks tab owners across three tabs: one per agent plus a
+// shared one.
+type ownedBridge struct {
+	mockBridge
+	owners map[string]string
+}
+
+func newOwnedBridge() *ownedBridge {
+	return &ownedBridge{owners: map[string]string{"tab-a": "agent-a", "tab-b": "agent-b"}}
+}
+
+func (m *ownedBridge) ListTargets() ([]*target.Info, error) {
+	return []*target.Info{
+		{TargetID: "tab-b", Type: "page"},
+		{TargetID: "tab-a", Type: "page"},
+		{TargetID: "tab-shared", Type: "page"},
+	}, nil
+}
+
+func (m *ownedBridge) TabContext(tabID string) (context.Context, string, error) {
+	if tabID == "" {
+		tabID = "tab-b"
+	}
+	if _, ok := m.owners[tabID]; !ok && tabID != "tab-shared" && tabID != "new-tab" {
+		return nil, "", fmt.Errorf("tab %s not found", tabID)
+	}
+	ctx, _ := chromedp.NewContext(context.Background())
+	return ctx, tabID, nil
+}
+
+// CreateTab tracks the new tab with its owner, like TabManager.
+func (m *ownedBridge) CreateTab(url, owner string) (string, context.Context, context.CancelFunc, error) {
+	m.owners["new-tab"] = owner
+	return m.mockBridge.CreateTab(url, owner)
+}
+
+func (m *ownedBridge) SetTabOwner(tabID, owner string) { m.owners[tabID] = owner }
+
+func (m *ownedBridge) TabOwner(tabID string) string { return m.owners[tabID] }
+
+func strictHandlers(b *ownedBridge) *Handlers {
+	return New(b, &config.RuntimeConfig{TabOwnership: "strict"}, nil, nil, nil)
+}
+
+func listTabIDs(t *testing.T, h *Handlers, agent string) []string {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/tabs", nil)
+	if agent != "" {
+		req.Header.Set("X-Agent-Id", agent)
+	}
+	w := httptest.NewRecorder()
+	h.HandleTabs(w, req)
+	var resp struct {
+		Tabs []struct {
+			ID string `json:"id"`
+		} `json:"tabs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, tab := range resp.Tabs {
+		ids = append(ids, tab.ID)
+	}
+	return ids
+}
+
+func TestHandleTabs_StrictOwnershipFilters(t *testing.T) {
+	h := strictHandlers(newOwnedBridge())
+
+	got := fmt.Sprint(listTabIDs(t, h, "agent-a"))
+	if got != "[tab-a tab-shared]" {
+		t.Errorf("agent-a sees %s", got)
+	}
+	if got := fmt.Sprint(listTabIDs(t, h, "")); got != "[tab-shared]" {
+		t.Errorf("anonymous caller should only see shared tabs, got %s", got)
+	}
+}
+
+func TestHandleTab_StrictScopesBodyOwner(t *testing.T) {
+	h := strictHandlers(newOwnedBridge())
+	// An agent naming itself only in the body is scoped like one sending
+	// X-Agent-Id: tab-b belongs to someone else.
+	req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`{"action":"close","tabId":"tab-b","owner":"agent-a"}`)))
+	w := httptest.NewRecorder()
+	h.HandleTab(w, req)
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleTabs_DefaultOwnershipShowsAll(t *testing.T) {
+	h := New(newOwnedBridge(), &config.RuntimeConfig{}, nil, nil, nil)
+	if got := listTabIDs(t, h, "agent-a"); len(got) != 3 {
+		t.Errorf("expected all tabs outside strict mode, got %v", got)
+	}
+}
+
+func TestHandleTabs_ReportsCreator(t *testing.T) {
+	h := New(newOwnedBridge(), &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("GET", "/tabs", nil)
+	w := httptest.NewRecorder()
+	h.HandleTabs(w, req)
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"createdBy":"agent-a"`)) {
+		t.Errorf("expected createdBy in %s", w.Body.String())
+	}
+}
+
+func TestTabContext_StrictHidesOtherAgentsTabs(t *testing.T) {
+	h := strictHandlers(newOwnedBridge())
+	req := httptest.NewRequest("GET", "/snapshot", nil)
+	req.Header.Set("X-Agent-Id", "agent-a")
+
+	if _, _, err := h.tabContext(req, "tab-b"); err == nil {
+		t.Error("expected agent-a to be refused tab-b")
+	}
+	if _, id, err := h.tabContext(req, "tab-shared"); err != nil || id != "tab-shared" {
+		t.Errorf("shared tab: id=%q err=%v", id, err)
+	}
+	// The default tab is the caller's first visible one, not tab-b.
+	if _, id, err := h.tabContext(req, ""); err != nil || id != "tab-a" {
+		t.Errorf("default tab: id=%q err=%v", id, err)
+	}
+
+	req.Header.Set("X-Lock-Override", "true")
+	if _, _, err := h.tabContext(req, "tab-b"); err == nil {
+		t.Error("the override header must not widen visibility")
+	}
+}
+
+func TestHandleTab_NewClaimsOwnership(t *testing.T) {
+	b := newOwnedBridge()
+	h := strictHandlers(b)
+
+	req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`{"action":"new"}`)))
+	req.Header.Set("X-Agent-Id", "agent-c")
+	w := httptest.NewRecorder()
+	h.HandleTab(w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if b.owners["new-tab"] != "agent-c" {
+		t.Errorf("expected new-tab owned by agent-c, got %q", b.owners["new-tab"])
+	}
+}
+
+func TestHandleTab_NewShared(t *testing.T) {
+	b := newOwnedBridge()
+	h := strictHandlers(b)
+
+	req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`{"action":"new","shared":true}`)))
+	req.Header.Set("X-Agent-Id", "agent-c")
+	w := httptest.NewRecorder()
+	h.HandleTab(w, req)
+	if owner := b.owners["new-tab"]; owner != "" {
+		t.Errorf("shared tab should have no owner, got %q", owner)
+	}
+}
+
+func TestHandleTab_CloseOtherAgentsTab(t *testing.T) {
+	h := strictHandlers(newOwnedBridge())
+
+	req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`{"action":"close","tabId":"tab-b"}`)))
+	req.Header.Set("X-Agent-Id", "agent-a")
+	w := httptest.NewRecorder()
+	h.HandleTab(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/pinchtab/pinchtab/internal/config"
@@ -27,3 +28,62 @@ func TestHandlePDF_NoTab_WithTabId(t *testing.T) {
 		t.Errorf("expected 404, got %d", w.Code)
 	}
 }
+
+func TestHandlePDF_InvalidOptions(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	for _, q := range []string{"paperFormat=B7", "margin=-1", "paperWidth=wide", "pageRanges=5-2", "pageRanges=a", "pageRanges=1,,2", "pageRanges=0", "pageRanges=-"} {
+		req := httptest.NewRequest("GET", "/pdf?"+q, nil)
+		w := httptest.NewRecorder()
+		h.HandlePDF(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+}
+
+func TestPDFParams(t *testing.T) {
+	q := url.Values{}
+	q.Set("paperFormat", "Letter")
+	q.Set("margin", "0.4")
+	q.Set("marginTop", "1")
+	q.Set("pageRanges", "2-4")
+	q.Set("footerTemplate", "<span class=pageNumber></span>")
+	q.Set("preferCSSPageSize", "true")
+	q.Set("generateTaggedPDF", "true")
+
+	p, err := pdfParams(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.PaperWidth != 8.5 || p.PaperHeight != 11 {
+		t.Errorf("expected Letter size, got %vx%v", p.PaperWidth, p.PaperHeight)
+	}
+	if p.MarginTop != 1 || p.MarginBottom != 0.4 || p.MarginLeft != 0.4 {
+		t.Errorf("unexpected margins: %+v", p)
+	}
+	if p.PageRanges != "2-4" {
+		t.Errorf("expected page ranges, got %q", p.PageRanges)
+	}
+	if !p.DisplayHeaderFooter {
+		t.Error("expected displayHeaderFooter to follow a footer template")
+	}
+	if !p.PreferCSSPageSize || !p.GenerateTaggedPDF {
+		t.Error("expected CSS page size and tagged PDF flags")
+	}
+	if !p.PrintBackground || p.Scale != 1 {
+		t.Errorf("expected defaults preserved, got background=%v scale=%v", p.PrintBackground, p.Scale)
+	}
+}
+
+func TestCheckPageRanges(t *testing.T) {
+	for _, ok := range []string{"1", "1-3", "1-3, 5", "8-", "-4", "2-2"} {
+		if err := checkPageRanges(ok); err != nil {
+			t.Errorf("%q: %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"3-1", "x", "1-2-3", "", " , ", "1;2", "-"} {
+		if err := checkPageRanges(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestHandlePerf_NoTab(t *testing.T) {
+	h := New(&failMockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandlePerf(w, httptest.NewRequest("GET", "/perf?tabId=missing", nil))
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// popupBridge opens a popup from tab1 whenever a click runs, unless noPopup
+// is set.
+type popupBridge struct {
+	currentTabBridge
+	noPopup bool
+	fail    bool // the click opens its popup and then fails
+	popups  []bridge.PopupInfo
+}
+
+func (m *popupBridge) ExecuteAction(ctx context.Context, kind string, req bridge.ActionRequest) (map[string]any, error) {
+	if kind == bridge.ActionClick && !m.noPopup {
+		m.popups = append(m.popups, bridge.PopupInfo{TabID: "popup1", OpenerID: "tab1", OpenedAt: time.Now()})
+	}
+	if m.fail {
+		return nil, fmt.Errorf("wait for navigation: %w", context.DeadlineExceeded)
+	}
+	return map[string]any{"success": true}, nil
+}
+
+func (m *popupBridge) PopupsSince(openerID string, since time.Time) []bridge.PopupInfo {
+	var out []bridge.PopupInfo
+	for _, p := range m.popups {
+		if p.OpenerID == openerID && !p.OpenedAt.Before(since) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (m *popupBridge) WaitForPopup(ctx context.Context, openerID string, since time.Time) (bridge.PopupInfo, error) {
+	if p := m.PopupsSince(openerID, since); len(p) > 0 {
+		return p[0], nil
+	}
+	return bridge.PopupInfo{}, fmt.Errorf("no popup opened by tab %s", openerID)
+}
+
+func newPopupBridge() *popupBridge {
+	return &popupBridge{currentTabBridge: *newCurrentTabBridge()}
+}
+
+func postAction(h *Handlers, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/action", bytes.NewReader([]byte(body)))
+	req.Header.Set("X-Agent-Id", "agent-a")
+	w := httptest.NewRecorder()
+	h.HandleAction(w, req)
+	return w
+}
+
+func TestHandleAction_ReportsPopups(t *testing.T) {
+	h := New(newPopupBridge(), &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+
+	w := postAction(h, `{"kind":"click","selector":"a"}`)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		TabID  string             `json:"tabId"`
+		Popups []bridge.PopupInfo `json:"popups"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Popups) != 1 || resp.Popups[0].TabID != "popup1" {
+		t.Errorf("expected popup1 reported, got %s", w.Body.String())
+	}
+	if resp.TabID != "" {
+		t.Errorf("should not switch tabs without followPopup, got %q", resp.TabID)
+	}
+}
+
+func TestHandleAction_FollowPopup(t *testing.T) {
+	b := newPopupBridge()
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+
+	w := postAction(h, `{"kind":"click","selector":"a","followPopup":true}`)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"tabId":"popup1"`)) {
+		t.Errorf("expected tabId popup1 in %s", w.Body.String())
+	}
+	if b.current["agent-a"] != "popup1" {
+		t.Errorf("expected popup1 to become current, got %q", b.current["agent-a"])
+	}
+}
+
+func TestHandleAction_FollowPopupNoneOpened(t *testing.T) {
+	b := newPopupBridge()
+	b.noPopup = true
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+
+	if w := postAction(h, `{"kind":"click","selector":"a","followPopup":true}`); w.Code != 408 {
+		t.Errorf("expected 408, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleAction_FollowPopupRequiresClick(t *testing.T) {
+	h := New(newPopupBridge(), &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+
+	if w := postAction(h, `{"kind":"type","selector":"input","text":"x","followPopup":true}`); w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleAction_FailedClickReportsPopups(t *testing.T) {
+	b := newPopupBridge()
+	b.fail = true
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+
+	w := postAction(h, `{"kind":"click","selector":"a"}`)
+	if w.Code != 500 {
+		t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"popups":[{"tabId":"popup1"`)) {
+		t.Errorf("expected popup1 reported, got %s", w.Body.String())
+	}
+}
+
+func TestHandleActions_FailedClickReportsPopups(t *testing.T) {
+	b := newPopupBridge()
+	b.fail = true
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/actions", bytes.NewReader([]byte(`{"actions":[{"kind":"click","selector":"a"}]}`)))
+	w := httptest.NewRecorder()
+	h.HandleActions(w, req)
+	var resp struct {
+		Results []actionResult `json:"results"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Results) != 1 || resp.Results[0].Success || resp.Results[0].Result["popups"] == nil {
+		t.Errorf("expected a failed result carrying popups, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// writeTestRecording stores a finished two-frame recording in stateDir.
+func writeTestRecording(t *testing.T, stateDir, id string) {
+	t.Helper()
+	dir := filepath.Join(stateDir, "recordings")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	var frame bytes.Buffer
+	if err := jpeg.Encode(&frame, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(dir, id+".avi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	avi, err := bridge.NewAVIWriter(f, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := recording{ID: id, TabID: "tab1", FPS: 5, StartedAt: time.Now().UTC()}
+	for i := 0; i < 2; i++ {
+		off, err := avi.WriteFrame(frame.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec.Frames = append(rec.Frames, recordedFrame{T: int64(i * 200), Offset: off, Size: frame.Len()})
+	}
+	if err := avi.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	stopped := time.Now().UTC()
+	rec.StoppedAt = &stopped
+	rec.FrameCount = len(rec.Frames)
+	meta, _ := json.Marshal(&rec)
+	if err := os.WriteFile(filepath.Join(dir, id+".json"), meta, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandleRecording_BadAction(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/recordings", strings.NewReader(`{"action":"pause"}`))
+	w := httptest.NewRecorder()
+	h.HandleRecording(w, req)
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleRecording_StartNoTab(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/recordings", strings.NewReader(`{"action":"start","tabId":"nope"}`))
+	w := httptest.NewRecorder()
+	h.HandleRecording(w, req)
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleRecording_StopNotRecording(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/recordings", strings.NewReader(`{"action":"stop","id":"rec-abc"}`))
+	w := httptest.NewRecorder()
+	h.HandleRecording(w, req)
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleRecordings_ListGetFrameDelete(t *testing.T) {
+	stateDir := t.TempDir()
+	writeTestRecording(t, stateDir, "rec-abc")
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: stateDir}, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux, nil)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/recordings", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"rec-abc"`) {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/recordings/rec-abc", nil))
+	if w.Code != 200 || w.Header().Get("Content-Type") != "video/x-msvideo" || !bytes.HasPrefix(w.Body.Bytes(), []byte("RIFF")) {
+		t.Fatalf("download: %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/recordings/rec-abc?format=json", nil))
+	var meta recording
+	if err := json.Unmarshal(w.Body.Bytes(), &meta); err != nil || len(meta.Frames) != 2 {
+		t.Fatalf("index: %v %s", err, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/recordings/rec-abc/frames/1", nil))
+	if w.Code != 200 {
+		t.Fatalf("frame: %d %s", w.Code, w.Body.String())
+	}
+	if _, err := jpeg.Decode(w.Body); err != nil {
+		t.Errorf("frame is not a jpeg: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/recordings/rec-abc/frames/2", nil))
+	if w.Code != 404 {
+		t.Errorf("out of range frame: expected 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/recordings/rec-abc", nil))
+	if w.Code != 200 {
+		t.Fatalf("delete: %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/recordings/rec-abc", nil))
+	if w.Code != 404 {
+		t.Errorf("after delete: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleRecordingGet_InvalidID(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux, nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/recordings/Rec_1.json", nil))
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestRecordingManager_ReserveOncePerTab(t *testing.T) {
+	m := newRecordingManager()
+	first := &recording{ID: "rec-1", TabID: "tab1", done: make(chan struct{})}
+	second := &recording{ID: "rec-2", TabID: "tab1", done: make(chan struct{})}
+
+	if got := m.reserve(first); got != nil {
+		t.Fatalf("first reserve should succeed, got %s", got.ID)
+	}
+	if got := m.reserve(second); got != first {
+		t.Fatalf("second reserve should return the first recording, got %v", got)
+	}
+
+	m.abort(first)
+	<-first.done
+	if got := m.reserve(second); got != nil {
+		t.Errorf("tab should be free after abort, got %s", got.ID)
+	}
+}
+
+func TestStopRecordings_FinalizesOnShutdown(t *testing.T) {
+	stateDir := t.TempDir()
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: stateDir}, nil, nil, nil)
+	if err := os.MkdirAll(h.recordingDir(), 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	// A recording as HandleRecording leaves it once the screencast runs.
+	sub := &screencastSub{Frames: make(chan screencastFrame, 1), Done: make(chan struct{})}
+	sub.stream = &tabStream{subs: map[*screencastSub]struct{}{sub: {}}}
+	rec := &recording{ID: "rec-shutdown", TabID: "tab1", FPS: 5, StartedAt: time.Now().UTC(),
+		sub: sub, stop: make(chan string, 1), done: make(chan struct{})}
+	if existing := h.recordings.reserve(rec); existing != nil {
+		t.Fatal("tab already recorded")
+	}
+	f, err := os.Create(filepath.Join(h.recordingDir(), rec.ID+".avi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	avi, err := bridge.NewAVIWriter(f, rec.FPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frame bytes.Buffer
+	if err := jpeg.Encode(&frame, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	sub.Frames <- screencastFrame{Data: frame.Bytes(), At: time.Now()}
+	go h.runRecording(rec, f, avi, time.Minute)
+	for {
+		rec.mu.Lock()
+		n := len(rec.Frames)
+		rec.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	h.StopRecordings()
+
+	meta, err := os.ReadFile(filepath.Join(h.recordingDir(), rec.ID+".json"))
+	if err != nil {
+		t.Fatalf("metadata not written: %v", err)
+	}
+	if !strings.Contains(string(meta), `"stopReason": "shutdown"`) {
+		t.Errorf("expected shutdown stop reason, got %s", meta)
+	}
+	data, err := os.ReadFile(filepath.Join(h.recordingDir(), rec.ID+".avi"))
+	if err != nil || !bytes.Contains(data, []byte("idx1")) {
+		t.Errorf("AVI should be finalized with an index (err %v)", err)
+	}
+	if h.recordings.get(rec.ID) != nil {
+		t.Error("recording should no longer be active")
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// recoveryBridge reports a crash recovery and maps "old1" to "new1".
+type recoveryBridge struct {
+	mockBridge
+	status bridge.RecoveryStatus
+}
+
+func (m *recoveryBridge) RecoveryStatus() bridge.RecoveryStatus { return m.status }
+
+func (m *recoveryBridge) MapTabID(tabID string) string {
+	if id, ok := m.status.TabMap[tabID]; ok {
+		return id
+	}
+	return tabID
+}
+
+func (m *recoveryBridge) TabContext(tabID string) (context.Context, string, error) {
+	ctx, _ := chromedp.NewContext(context.Background())
+	return ctx, tabID, nil
+}
+
+func healthStatus(t *testing.T, b bridge.BridgeAPI) map[string]any {
+	t.Helper()
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleHealth(w, httptest.NewRequest("GET", "/health", nil))
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestHandleHealth_Recovering(t *testing.T) {
+	resp := healthStatus(t, &recoveryBridge{status: bridge.RecoveryStatus{
+		State: bridge.RecoveryInProgress, Crashes: 1, Attempts: 2, Error: "chrome not found",
+	}})
+	if resp["status"] != "recovering" {
+		t.Errorf("expected recovering, got %v", resp["status"])
+	}
+	rec, _ := resp["recovery"].(map[string]any)
+	if rec["attempts"] != float64(2) {
+		t.Errorf("expected attempts in recovery, got %v", resp["recovery"])
+	}
+}
+
+func TestHandleHealth_AfterRecovery(t *testing.T) {
+	resp := healthStatus(t, &recoveryBridge{status: bridge.RecoveryStatus{
+		State: bridge.RecoveryOK, Crashes: 1, TabMap: map[string]string{"old1": "new1"},
+	}})
+	if resp["status"] != "ok" {
+		t.Errorf("expected ok, got %v", resp["status"])
+	}
+	rec, _ := resp["recovery"].(map[string]any)
+	tabMap, _ := rec["tabMap"].(map[string]any)
+	if tabMap["old1"] != "new1" {
+		t.Errorf("expected tabMap in health, got %v", resp["recovery"])
+	}
+
+	if _, ok := healthStatus(t, &mockBridge{})["recovery"]; ok {
+		t.Error("recovery should be omitted when Chrome never crashed")
+	}
+}
+
+func TestTabContext_MapsRecoveredIDs(t *testing.T) {
+	b := &recoveryBridge{status: bridge.RecoveryStatus{TabMap: map[string]string{"old1": "new1"}}}
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	_, id, err := h.tabContext(httptest.NewRequest("GET", "/snapshot", nil), "old1")
+	if err != nil || id != "new1" {
+		t.Errorf("expected new1, got %q (%v)", id, err)
+	}
+}
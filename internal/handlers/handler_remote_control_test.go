@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+// lockingBridge is a mockBridge backed by a real LockManager.
+type lockingBridge struct {
+	mockBridge
+	locks *bridge.LockManager
+}
+
+func newLockingBridge() *lockingBridge {
+	return &lockingBridge{locks: bridge.NewLockManager()}
+}
+
+func (m *lockingBridge) Lock(tabID, owner string, ttl time.Duration) error {
+	return m.locks.TryLock(tabID, owner, ttl)
+}
+
+func (m *lockingBridge) LockWait(ctx context.Context, tabID, owner string, ttl time.Duration) error {
+	return m.locks.Acquire(ctx, tabID, owner, ttl)
+}
+
+func (m *lockingBridge) RenewLock(tabID, owner string, ttl time.Duration) (time.Time, error) {
+	return m.locks.Renew(tabID, owner, ttl)
+}
+
+func (m *lockingBridge) Unlock(tabID, owner string) error {
+	return m.locks.Unlock(tabID, owner)
+}
+
+func (m *lockingBridge) TabLockInfo(tabID string) *bridge.LockInfo {
+	return m.locks.Get(tabID)
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFrameGeometry_ToViewport(t *testing.T) {
+	g := &frameGeometry{}
+	if x, y := g.toViewport(10, 20); x != 10 || y != 20 {
+		t.Errorf("expected passthrough before first frame, got %v,%v", x, y)
+	}
+
+	g.update(testJPEG(t, 800, 600), &page.ScreencastFrameMetadata{DeviceWidth: 1600, DeviceHeight: 1200})
+	if x, y := g.toViewport(400, 300); x != 800 || y != 600 {
+		t.Errorf("expected 800,600, got %v,%v", x, y)
+	}
+}
+
+func TestRemoteControl_RequiresLock(t *testing.T) {
+	b := newLockingBridge()
+	rc := &remoteControl{bridge: b, tabID: "tab1", owner: "alice", geo: &frameGeometry{}}
+	ctx := context.Background()
+
+	reply := rc.handle(ctx, []byte(`{"type":"mouse","event":"mousePressed","x":1,"y":1}`))
+	if reply == nil || reply["type"] != "error" {
+		t.Fatalf("expected input to be rejected without the lock, got %v", reply)
+	}
+
+	if err := b.Lock("tab1", "agent-1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	reply = rc.handle(ctx, []byte(`{"type":"takeover"}`))
+	if reply["granted"] != false {
+		t.Fatalf("expected takeover to fail while an agent holds the lock, got %v", reply)
+	}
+	reply = rc.handle(ctx, []byte(`{"type":"key","event":"keyDown","key":"a","text":"a"}`))
+	if reply == nil || reply["error"] != "tab is locked by agent-1" {
+		t.Fatalf("expected lock owner in error, got %v", reply)
+	}
+	_ = b.Unlock("tab1", "agent-1")
+
+	reply = rc.handle(ctx, []byte(`{"type":"takeover"}`))
+	if reply["granted"] != true {
+		t.Fatalf("expected takeover, got %v", reply)
+	}
+	if info := b.TabLockInfo("tab1"); info == nil || info.Owner != "alice" {
+		t.Fatalf("expected alice to hold the lock, got %+v", info)
+	}
+
+	rc.release()
+	if info := b.TabLockInfo("tab1"); info != nil {
+		t.Errorf("expected lock released, got %+v", info)
+	}
+}
+
+func TestRemoteControl_InvalidMessages(t *testing.T) {
+	rc := &remoteControl{bridge: newLockingBridge(), tabID: "tab1", owner: "alice", geo: &frameGeometry{}}
+	for _, raw := range []string{`not json`, `{"type":"teleport"}`} {
+		if reply := rc.handle(context.Background(), []byte(raw)); reply == nil || reply["type"] != "error" {
+			t.Errorf("%s: expected error reply, got %v", raw, reply)
+		}
+	}
+}
+
+func TestRemoteControl_ForceTakeover(t *testing.T) {
+	b := newLockingBridge()
+	if err := b.Lock("tab1", "agent-a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	rc := &remoteControl{bridge: b, tabID: "tab1", owner: "dashboard", geo: &frameGeometry{}}
+
+	reply := rc.handle(context.Background(), []byte(`{"type":"takeover"}`))
+	if reply["granted"] != false || reply["lockedBy"] != "agent-a" {
+		t.Fatalf("expected denial naming the holder, got %v", reply)
+	}
+	reply = rc.handle(context.Background(), []byte(`{"type":"takeover","force":true}`))
+	if reply["granted"] != true {
+		t.Fatalf("expected forced takeover, got %v", reply)
+	}
+	if info := b.TabLockInfo("tab1"); info == nil || info.Owner != "dashboard" {
+		t.Errorf("expected dashboard to hold the lock, got %+v", info)
+	}
+}
+
+func TestRemoteControl_ReleaseOnlyOwnLock(t *testing.T) {
+	b := newLockingBridge()
+	if err := b.Lock("tab1", "agent-a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	// A viewer claiming the agent's name never took the lock, so closing it
+	// must leave the lock alone.
+	rc := &remoteControl{bridge: b, tabID: "tab1", owner: "agent-a", geo: &frameGeometry{}}
+	if reply := rc.handle(context.Background(), []byte(`{"type":"mouse","event":"mouseMoved","x":1,"y":1}`)); reply == nil || reply["type"] != "error" {
+		t.Fatalf("expected input to be rejected, got %v", reply)
+	}
+	if reply := rc.handle(context.Background(), []byte(`{"type":"takeover"}`)); reply["granted"] != false {
+		t.Fatalf("expected takeover of the same-named lock to be refused, got %v", reply)
+	}
+	rc.release()
+	if info := b.TabLockInfo("tab1"); info == nil || info.Owner != "agent-a" {
+		t.Errorf("expected agent-a to keep the lock, got %+v", info)
+	}
+}
+
+func TestRemoteControl_HandoffKeepsLock(t *testing.T) {
+	b := newLockingBridge()
+	owner := handoffLockOwner("ho-1")
+	if err := b.Lock("tab1", owner, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	rc := &remoteControl{bridge: b, tabID: "tab1", owner: "dashboard", geo: &frameGeometry{}}
+	reply := rc.handle(context.Background(), []byte(`{"type":"takeover"}`))
+	if reply["granted"] != true || !rc.holds() {
+		t.Fatalf("expected control during the handoff, got %v", reply)
+	}
+	// Closing the viewer must not end the handoff's hold on the tab.
+	rc.release()
+	if info := b.TabLockInfo("tab1"); info == nil || info.Owner != owner {
+		t.Errorf("expected the handoff to keep the lock, got %+v", info)
+	}
+}
+
+// queuedLockingBridge counts the queue slots remote control input takes.
+type queuedLockingBridge struct {
+	*lockingBridge
+	acquired int
+}
+
+func (b *queuedLockingBridge) AcquireTab(ctx context.Context, tabID string) (func(), error) {
+	b.acquired++
+	return func() {}, nil
+}
+
+func TestRemoteControl_InputQueues(t *testing.T) {
+	b := &queuedLockingBridge{lockingBridge: newLockingBridge()}
+	rc := &remoteControl{bridge: b, tabID: "tab1", owner: "alice", geo: &frameGeometry{}}
+	ctx := context.Background()
+
+	if reply := rc.handle(ctx, []byte(`{"type":"takeover"}`)); reply["granted"] != true {
+		t.Fatalf("expected takeover, got %v", reply)
+	}
+	if b.acquired != 0 {
+		t.Errorf("takeover should not queue, acquired %d", b.acquired)
+	}
+	rc.handle(ctx, []byte(`{"type":"key","event":"keyDown","key":"a","text":"a"}`))
+	if b.acquired != 1 {
+		t.Errorf("input should take a queue slot, acquired %d", b.acquired)
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+type fakeScreencast struct {
+	mu     sync.Mutex
+	starts []screencastOptions
+	stops  int
+	fail   bool
+}
+
+func newTestHub(fc *fakeScreencast) *screencastHub {
+	hub := newScreencastHub()
+	hub.start = func(ctx context.Context, opts screencastOptions) error {
+		fc.mu.Lock()
+		defer fc.mu.Unlock()
+		if fc.fail {
+			return fmt.Errorf("start failed")
+		}
+		fc.starts = append(fc.starts, opts)
+		return nil
+	}
+	hub.stop = func(ctx context.Context) error {
+		fc.mu.Lock()
+		defer fc.mu.Unlock()
+		fc.stops++
+		return nil
+	}
+	return hub
+}
+
+func TestScreencastHub_SharesOneCDPScreencast(t *testing.T) {
+	fc := &fakeScreencast{}
+	hub := newTestHub(fc)
+	ctx, cancel := chromedp.NewContext(context.Background())
+	defer cancel()
+
+	a, err := hub.subscribe(ctx, "tab1", screencastOptions{Quality: 30, MaxWidth: 800, EveryNth: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := hub.subscribe(ctx, "tab1", screencastOptions{Quality: 60, MaxWidth: 1280, EveryNth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hub.viewers("tab1") != 2 {
+		t.Fatalf("expected 2 viewers, got %d", hub.viewers("tab1"))
+	}
+	if len(fc.starts) != 2 {
+		t.Fatalf("expected a restart with upgraded options, got %d starts", len(fc.starts))
+	}
+	if got := fc.starts[1]; got.Quality != 60 || got.MaxWidth != 1280 || got.EveryNth != 1 {
+		t.Errorf("expected combined options, got %+v", got)
+	}
+
+	hub.unsubscribe(b)
+	if fc.stops != 0 {
+		t.Fatal("screencast stopped while a viewer remains")
+	}
+	if got := fc.starts[len(fc.starts)-1]; got.Quality != 30 || got.EveryNth != 4 {
+		t.Errorf("expected options to drop back to the remaining viewer, got %+v", got)
+	}
+	select {
+	case <-b.Done:
+	default:
+		t.Error("unsubscribed viewer should be done")
+	}
+
+	hub.unsubscribe(a)
+	if fc.stops != 1 {
+		t.Errorf("expected one stop after the last viewer left, got %d", fc.stops)
+	}
+	if hub.viewers("tab1") != 0 {
+		t.Error("stream should be gone")
+	}
+}
+
+func TestScreencastHub_FanOutWithRateLimits(t *testing.T) {
+	hub := newTestHub(&fakeScreencast{})
+	ctx, cancel := chromedp.NewContext(context.Background())
+	defer cancel()
+
+	fast, _ := hub.subscribe(ctx, "tab1", screencastOptions{Quality: 30, MaxWidth: 800})
+	slow, _ := hub.subscribe(ctx, "tab1", screencastOptions{Quality: 30, MaxWidth: 800, MinInterval: time.Second})
+	st := hub.streams["tab1"]
+
+	base := time.Now()
+	for i := 0; i < 3; i++ {
+		hub.dispatch(st, screencastFrame{Data: []byte{byte(i)}, At: base.Add(time.Duration(i) * 100 * time.Millisecond)})
+	}
+	if len(fast.Frames) != 3 {
+		t.Errorf("fast viewer: expected 3 frames, got %d", len(fast.Frames))
+	}
+	if len(slow.Frames) != 1 {
+		t.Errorf("slow viewer: expected 1 frame, got %d", len(slow.Frames))
+	}
+
+	// A full buffer drops frames instead of blocking the others.
+	hub.dispatch(st, screencastFrame{Data: []byte{9}, At: base.Add(2 * time.Second)})
+	if len(slow.Frames) != 2 {
+		t.Errorf("slow viewer: expected 2 frames, got %d", len(slow.Frames))
+	}
+}
+
+func TestScreencastHub_TabClosedEndsStream(t *testing.T) {
+	hub := newTestHub(&fakeScreencast{})
+	ctx, cancel := chromedp.NewContext(context.Background())
+
+	sub, err := hub.subscribe(ctx, "tab1", screencastOptions{Quality: 30, MaxWidth: 800})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	select {
+	case <-sub.Done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscriber not released when the tab context ended")
+	}
+	hub.unsubscribe(sub) // must be safe after the stream ended
+}
+
+func TestScreencastHub_StartFailure(t *testing.T) {
+	hub := newTestHub(&fakeScreencast{fail: true})
+	ctx, cancel := chromedp.NewContext(context.Background())
+	defer cancel()
+
+	if _, err := hub.subscribe(ctx, "tab1", screencastOptions{Quality: 30, MaxWidth: 800}); err == nil {
+		t.Fatal("expected start error")
+	}
+	if hub.viewers("tab1") != 0 {
+		t.Error("failed subscription should not linger")
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+type scriptBridge struct {
+	mockBridge
+	scripts map[string]bridge.UserScript
+	saveErr error
+}
+
+func (b *scriptBridge) UserScripts() []bridge.UserScript {
+	out := make([]bridge.UserScript, 0, len(b.scripts))
+	for _, s := range b.scripts {
+		out = append(out, s)
+	}
+	return out
+}
+
+func (b *scriptBridge) AddUserScript(s bridge.UserScript) (bridge.UserScript, error) {
+	if s.Name == "bad name" {
+		return bridge.UserScript{}, fmt.Errorf("%w: bad name %q", bridge.ErrInvalidScript, s.Name)
+	}
+	if b.saveErr != nil {
+		return bridge.UserScript{}, b.saveErr
+	}
+	b.scripts[s.Name] = s
+	return s, nil
+}
+
+func (b *scriptBridge) RemoveUserScript(name string) error {
+	if _, ok := b.scripts[name]; !ok {
+		return bridge.ErrScriptNotFound
+	}
+	delete(b.scripts, name)
+	return nil
+}
+
+func TestHandleScripts_Lifecycle(t *testing.T) {
+	b := &scriptBridge{scripts: map[string]bridge.UserScript{}}
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux, nil)
+
+	body := `{"name":"banner","source":"document.querySelector('#cookie')?.remove()","match":["https://*.example.com/*"]}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/scripts", bytes.NewReader([]byte(body))))
+	if w.Code != 200 {
+		t.Fatalf("add: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/scripts", nil))
+	var list struct {
+		Scripts []bridge.UserScript `json:"scripts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Scripts) != 1 || list.Scripts[0].Match[0] != "https://*.example.com/*" {
+		t.Errorf("unexpected list: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/scripts/banner", nil))
+	if w.Code != 200 {
+		t.Errorf("delete: expected 200, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/scripts/banner", nil))
+	if w.Code != 404 {
+		t.Errorf("second delete: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleScriptAdd_MissingSource(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("POST", "/scripts", bytes.NewReader([]byte(`{"name":"x"}`)))
+	w := httptest.NewRecorder()
+	h.HandleScriptAdd(w, req)
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleScriptAdd_ErrorCodes(t *testing.T) {
+	b := &scriptBridge{scripts: map[string]bridge.UserScript{}}
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+	add := func(body string) int {
+		w := httptest.NewRecorder()
+		h.HandleScriptAdd(w, httptest.NewRequest("POST", "/scripts", bytes.NewReader([]byte(body))))
+		return w.Code
+	}
+
+	if code := add(`{"name":"bad name","source":"1"}`); code != 400 {
+		t.Errorf("invalid script: expected 400, got %d", code)
+	}
+	b.saveErr = fmt.Errorf("save scripts: %w", errors.New("disk full"))
+	if code := add(`{"name":"ok","source":"1"}`); code != 500 {
+		t.Errorf("save failure: expected 500, got %d", code)
+	}
+}
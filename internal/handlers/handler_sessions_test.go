@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+// sessionBridge records what the session handlers ask for.
+type sessionBridge struct {
+	ownedBridge
+	savedTabs []string
+	saved     bridge.SessionOptions
+	stored    map[string]bridge.SessionState
+}
+
+func (m *sessionBridge) LoadSession(name string) (bridge.SessionState, error) {
+	state, ok := m.stored[name]
+	if !ok {
+		return bridge.SessionState{}, fmt.Errorf("%w: %s", bridge.ErrSessionNotFound, name)
+	}
+	return state, nil
+}
+
+func (m *sessionBridge) ListSessions() ([]bridge.SessionInfo, error) {
+	var out []bridge.SessionInfo
+	for _, name := range slices.Sorted(maps.Keys(m.stored)) {
+		out = append(out, bridge.SessionInfo{Name: name, Owner: m.stored[name].Owner})
+	}
+	return out, nil
+}
+
+func (m *sessionBridge) DeleteSession(name string) error {
+	if _, ok := m.stored[name]; !ok {
+		return fmt.Errorf("%w: %s", bridge.ErrSessionNotFound, name)
+	}
+	delete(m.stored, name)
+	return nil
+}
+
+func (m *sessionBridge) SaveSession(name string, tabIDs []string, opts bridge.SessionOptions) (bridge.SessionState, error) {
+	if name == "../x" {
+		return bridge.SessionState{}, bridge.ErrInvalidSessionName
+	}
+	m.savedTabs, m.saved = tabIDs, opts
+	tabs := make([]bridge.TabState, len(tabIDs))
+	return bridge.SessionState{Name: name, Owner: opts.Owner, Tabs: tabs, SavedAt: "2026-01-01T00:00:00Z"}, nil
+}
+
+func (m *sessionBridge) RestoreSession(name string) ([]bridge.RestoredTab, error) {
+	if name != "research" {
+		return nil, fmt.Errorf("%w: %s", bridge.ErrSessionNotFound, name)
+	}
+	return []bridge.RestoredTab{{TabID: "r1", URL: "https://a.test"}, {TabID: "r2", URL: "https://b.test"}}, nil
+}
+
+func sessionRequest(h *Handlers, handler http.HandlerFunc, method, name, body, agent string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/sessions/"+name, bytes.NewReader([]byte(body)))
+	req.SetPathValue("name", name)
+	if agent != "" {
+		req.Header.Set("X-Agent-Id", agent)
+	}
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestHandleSessionSave_StrictSavesOwnTabs(t *testing.T) {
+	b := &sessionBridge{ownedBridge: *newOwnedBridge()}
+	h := strictHandlers(&b.ownedBridge)
+	h.Bridge = b
+
+	w := sessionRequest(h, h.HandleSessionSave, "POST", "research", `{"cookies":true}`, "agent-a")
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if fmt.Sprint(b.savedTabs) != "[tab-a tab-shared]" {
+		t.Errorf("expected agent-a's visible tabs, got %v", b.savedTabs)
+	}
+	if !b.saved.Cookies || b.saved.Storage {
+		t.Errorf("options not passed through: %+v", b.saved)
+	}
+	if !b.saved.TabCookies {
+		t.Error("expected strict mode to limit cookies to the saved tabs")
+	}
+	if b.saved.Owner != "agent-a" {
+		t.Errorf("expected the session to belong to agent-a, got %q", b.saved.Owner)
+	}
+}
+
+func TestHandleSessionSave_EmptyBody(t *testing.T) {
+	b := &sessionBridge{ownedBridge: *newOwnedBridge()}
+	h := New(b, nil, nil, nil, nil)
+
+	w := sessionRequest(h, h.HandleSessionSave, "POST", "research", "", "")
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if b.savedTabs != nil {
+		t.Errorf("expected all tabs (nil), got %v", b.savedTabs)
+	}
+	if b.saved.TabCookies {
+		t.Error("cookies should only be limited to the saved tabs in strict mode")
+	}
+}
+
+func TestHandleSessionSave_Errors(t *testing.T) {
+	b := &sessionBridge{ownedBridge: *newOwnedBridge()}
+	h := strictHandlers(&b.ownedBridge)
+	h.Bridge = b
+
+	if w := sessionRequest(h, h.HandleSessionSave, "POST", "../x", "", ""); w.Code != 400 {
+		t.Errorf("invalid name: expected 400, got %d", w.Code)
+	}
+	if w := sessionRequest(h, h.HandleSessionSave, "POST", "s", `{"tabIds":["tab-b"]}`, "agent-a"); w.Code != 404 {
+		t.Errorf("other agent's tab: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleSessionRestore(t *testing.T) {
+	b := &sessionBridge{ownedBridge: *newOwnedBridge(), stored: map[string]bridge.SessionState{
+		"research": {Name: "research"},
+	}}
+	h := strictHandlers(&b.ownedBridge)
+	h.Bridge = b
+
+	w := sessionRequest(h, h.HandleSessionRestore, "POST", "research", "", "agent-a")
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Restored int `json:"restored"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Restored != 2 {
+		t.Errorf("expected 2 restored tabs, got %s", w.Body.String())
+	}
+	if b.owners["r1"] != "agent-a" || b.owners["r2"] != "agent-a" {
+		t.Errorf("restored tabs should belong to the caller, got %v", b.owners)
+	}
+
+	if w := sessionRequest(h, h.HandleSessionRestore, "POST", "missing", "", "agent-a"); w.Code != 404 {
+		t.Errorf("missing session: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleSessionGet_RedactsStorage(t *testing.T) {
+	b := &sessionBridge{ownedBridge: *newOwnedBridge(), stored: map[string]bridge.SessionState{
+		"research": {Name: "research", Tabs: []bridge.TabState{{
+			ID:             "t1",
+			URL:            "https://a.test",
+			LocalStorage:   map[string]string{"token": "secret-1", "theme": "dark"},
+			SessionStorage: map[string]string{"csrf": "secret-2"},
+		}}},
+	}}
+	h := New(b, nil, nil, nil, nil)
+
+	w := sessionRequest(h, h.HandleSessionGet, "GET", "research", "", "")
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "secret") {
+		t.Errorf("storage values leaked: %s", w.Body.String())
+	}
+	var resp struct {
+		Tabs []struct {
+			LocalStorage   []string `json:"localStorage"`
+			SessionStorage []string `json:"sessionStorage"`
+		} `json:"tabs"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Tabs) != 1 || fmt.Sprint(resp.Tabs[0].LocalStorage) != "[theme token]" || fmt.Sprint(resp.Tabs[0].SessionStorage) != "[csrf]" {
+		t.Errorf("expected storage keys, got %s", w.Body.String())
+	}
+}
+
+func TestHandleSessions_StrictScopesByOwner(t *testing.T) {
+	b := &sessionBridge{ownedBridge: *newOwnedBridge(), stored: map[string]bridge.SessionState{
+		"mine":     {Name: "mine", Owner: "agent-a"},
+		"theirs":   {Name: "theirs", Owner: "agent-b"},
+		"autosave": {Name: "autosave"},
+	}}
+	h := strictHandlers(&b.ownedBridge)
+	h.Bridge = b
+
+	w := sessionRequest(h, h.HandleSessionList, "GET", "", "", "agent-a")
+	var list struct {
+		Sessions []bridge.SessionInfo `json:"sessions"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	var names []string
+	for _, s := range list.Sessions {
+		names = append(names, s.Name)
+	}
+	if fmt.Sprint(names) != "[autosave mine]" {
+		t.Errorf("expected own and unowned sessions, got %v", names)
+	}
+
+	if w := sessionRequest(h, h.HandleSessionGet, "GET", "theirs", "", "agent-a"); w.Code != 404 {
+		t.Errorf("get: expected 404, got %d", w.Code)
+	}
+	if w := sessionRequest(h, h.HandleSessionRestore, "POST", "theirs", "", "agent-a"); w.Code != 404 {
+		t.Errorf("restore: expected 404, got %d", w.Code)
+	}
+	if w := sessionRequest(h, h.HandleSessionDelete, "DELETE", "theirs", "", "agent-a"); w.Code != 404 {
+		t.Errorf("delete: expected 404, got %d", w.Code)
+	}
+	if w := sessionRequest(h, h.HandleSessionSave, "POST", "theirs", "", "agent-a"); w.Code != 409 {
+		t.Errorf("overwrite: expected 409, got %d", w.Code)
+	}
+	if _, ok := b.stored["theirs"]; !ok {
+		t.Error("agent-b's session should survive")
+	}
+	if w := sessionRequest(h, h.HandleSessionGet, "GET", "theirs", "", "agent-b"); w.Code != 200 {
+		t.Errorf("owner get: expected 200, got %d", w.Code)
+	}
+	if w := sessionRequest(h, h.HandleSessionDelete, "DELETE", "mine", "", "agent-a"); w.Code != 200 {
+		t.Errorf("owner delete: expected 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// crashedBridge hands out a tab context its renderer crash has already
+// cancelled, and reports tab1 as crashed.
+type crashedBridge struct {
+	mockBridge
+}
+
+func (m *crashedBridge) TabContext(tabID string) (context.Context, string, error) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(fmt.Errorf("%w (%s)", bridge.ErrTabCrashed, bridge.CrashRenderer))
+	return ctx, "tab1", nil
+}
+
+func (m *crashedBridge) ExecuteAction(ctx context.Context, kind string, req bridge.ActionRequest) (map[string]any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m *crashedBridge) ListTargets() ([]*target.Info, error) {
+	return []*target.Info{{TargetID: "tab1", Type: "page", URL: "https://example.com"}}, nil
+}
+
+func (m *crashedBridge) TabCrash(tabID string) *bridge.TabCrash {
+	return &bridge.TabCrash{TabID: tabID, Reason: bridge.CrashRenderer, URL: "https://example.com", At: time.Now()}
+}
+
+func TestHandleAction_TabCrashed(t *testing.T) {
+	h := New(&crashedBridge{}, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+	body := `{"tabId":"tab1","kind":"click","selector":"#go"}`
+	w := httptest.NewRecorder()
+	h.HandleAction(w, httptest.NewRequest("POST", "/action", bytes.NewReader([]byte(body))))
+
+	if w.Code != 503 {
+		t.Fatalf("expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]string
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["code"] != "tab_crashed" {
+		t.Errorf("expected code tab_crashed, got %v", resp)
+	}
+}
+
+func TestHandleActions_TabCrashed(t *testing.T) {
+	h := New(&crashedBridge{}, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+	body := `{"tabId":"tab1","actions":[{"kind":"click","selector":"#go"}]}`
+	w := httptest.NewRecorder()
+	h.HandleActions(w, httptest.NewRequest("POST", "/actions", bytes.NewReader([]byte(body))))
+
+	var resp struct {
+		Results []actionResult `json:"results"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Results) != 1 || resp.Results[0].Code != "tab_crashed" {
+		t.Errorf("expected a tab_crashed result, got %s", w.Body.String())
+	}
+}
+
+func TestHandleTabs_ShowsCrash(t *testing.T) {
+	h := New(&crashedBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleTabs(w, httptest.NewRequest("GET", "/tabs", nil))
+
+	var resp struct {
+		Tabs []map[string]any `json:"tabs"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Tabs) != 1 || resp.Tabs[0]["crashed"] != true {
+		t.Fatalf("expected tab1 marked crashed, got %s", w.Body.String())
+	}
+	crash, _ := resp.Tabs[0]["crash"].(map[string]any)
+	if crash["reason"] != bridge.CrashRenderer {
+		t.Errorf("expected crash reason, got %v", resp.Tabs[0]["crash"])
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/chromedp/cdproto/target"
 	"github.com/chromedp/chromedp"
@@ -41,7 +42,7 @@ func (m *mockBridge) ExecuteAction(ctx context.Context, kind string, req bridge.
 	return map[string]any{"success": true}, nil
 }
 
-func (m *mockBridge) CreateTab(url string) (string, context.Context, context.CancelFunc, error) {
+func (m *mockBridge) CreateTab(url, owner string) (string, context.Context, context.CancelFunc, error) {
 	ctx, cancel := chromedp.NewContext(context.Background())
 	return "new-tab", ctx, cancel, nil
 }
@@ -53,10 +54,41 @@ func (m *mockBridge) CloseTab(tabID string) error {
 	return nil
 }
 
+func (m *mockBridge) AcquireTab(ctx context.Context, tabID string) (func(), error) {
+	return func() {}, nil
+}
+
+func (m *mockBridge) TabQueueDepth(tabID string) int { return 0 }
+
+func (m *mockBridge) SetTabOwner(tabID, owner string) {}
+
+func (m *mockBridge) TabOwner(tabID string) string { return "" }
+
+func (m *mockBridge) CurrentTab(agentID string) string { return "" }
+
+func (m *mockBridge) MapTabID(tabID string) string { return tabID }
+
+func (m *mockBridge) RecoveryStatus() bridge.RecoveryStatus {
+	return bridge.RecoveryStatus{State: bridge.RecoveryOK}
+}
+
+func (m *mockBridge) SetCurrentTab(agentID, tabID string) {}
+
+func (m *mockBridge) PopupsSince(openerID string, since time.Time) []bridge.PopupInfo { return nil }
+
+func (m *mockBridge) GetRefCache(tabID string) *bridge.RefCache { return nil }
+
 func (m *mockBridge) DeleteRefCache(tabID string) {}
 
 func (m *mockBridge) TabLockInfo(tabID string) *bridge.LockInfo { return nil }
 
+func (m *mockBridge) TabCrash(tabID string) *bridge.TabCrash { return nil }
+func (m *mockBridge) BrowserInfo(ctx context.Context) (bridge.BrowserInfo, error) {
+	return bridge.BrowserInfo{Product: "HeadlessChrome/144.0.7559.133"}, nil
+}
+
+func (m *mockBridge) ClearTabCrash(tabID string) {}
+
 func TestHandlers(t *testing.T) {
 	h := New(&mockBridge{}, nil, nil, nil, nil)
 	mux := http.NewServeMux()
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestHandleTraceStart_NoTab(t *testing.T) {
+	h := New(&failMockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleTraceStart(w, httptest.NewRequest("POST", "/trace/start", strings.NewReader(`{"tabId":"missing"}`)))
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleTraceStart_BadJSON(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleTraceStart(w, httptest.NewRequest("POST", "/trace/start", strings.NewReader(`{`)))
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleTraceStart_AlreadyTracing(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+	h.traces.active["tab1"] = &traceSession{TabID: "tab1", StartedAt: time.Now()}
+	w := httptest.NewRecorder()
+	h.HandleTraceStart(w, httptest.NewRequest("POST", "/trace/start", strings.NewReader(`{"tabId":"tab1"}`)))
+	if w.Code != 409 {
+		t.Errorf("expected 409, got %d", w.Code)
+	}
+}
+
+func TestHandleTraceStop_NotTracing(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleTraceStop(w, httptest.NewRequest("POST", "/trace/stop", strings.NewReader(`{"tabId":"tab1"}`)))
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleTraceStop_PathOutsideStateDir(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	h.traces.active["tab1"] = &traceSession{TabID: "tab1", StartedAt: time.Now()}
+	w := httptest.NewRecorder()
+	h.HandleTraceStop(w, httptest.NewRequest("POST", "/trace/stop",
+		strings.NewReader(`{"tabId":"tab1","output":"file","path":"../escape.json"}`)))
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+	if h.traces.active["tab1"] == nil {
+		t.Error("a rejected stop should leave the trace running")
+	}
+}
+
+func TestHandleTraceStop_TabGoneDropsTrace(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{}, nil, nil, nil)
+	h.traces.active["gone"] = &traceSession{TabID: "gone", StartedAt: time.Now()}
+	w := httptest.NewRecorder()
+	h.HandleTraceStop(w, httptest.NewRequest("POST", "/trace/stop", strings.NewReader(`{"tabId":"gone"}`)))
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+	if len(h.traces.active) != 0 {
+		t.Error("trace of a closed tab should be dropped")
+	}
+}
+
+func TestHandleTraceStop_HiddenTabKeepsTrace(t *testing.T) {
+	h := strictHandlers(newOwnedBridge())
+	h.traces.active["tab-b"] = &traceSession{TabID: "tab-b", StartedAt: time.Now()}
+	req := httptest.NewRequest("POST", "/trace/stop", strings.NewReader(`{"tabId":"tab-b"}`))
+	req.Header.Set("X-Agent-Id", "agent-a")
+	w := httptest.NewRecorder()
+	h.HandleTraceStop(w, req)
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+	if h.traces.active["tab-b"] == nil {
+		t.Error("another agent must not be able to drop the trace")
+	}
+}
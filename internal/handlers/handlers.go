@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/pinchtab/pinchtab/internal/assets"
 	"github.com/pinchtab/pinchtab/internal/bridge"
@@ -16,6 +17,12 @@ type Handlers struct {
 	Profiles     bridge.ProfileService
 	Dashboard    *dashboard.Dashboard
 	Orchestrator bridge.OrchestratorService
+
+	started     time.Time
+	recordings  *recordingManager
+	handoffs    *handoffManager
+	screencasts *screencastHub
+	traces      *traceManager
 }
 
 func New(b bridge.BridgeAPI, cfg *config.RuntimeConfig, p bridge.ProfileService, d *dashboard.Dashboard, o bridge.OrchestratorService) *Handlers {
@@ -25,23 +32,56 @@ func New(b bridge.BridgeAPI, cfg *config.RuntimeConfig, p bridge.ProfileService,
 		Profiles:     p,
 		Dashboard:    d,
 		Orchestrator: o,
+		started:      time.Now(),
+		recordings:   newRecordingManager(),
+		handoffs:     newHandoffManager(),
+		screencasts:  newScreencastHub(),
+		traces:       newTraceManager(),
 	}
 }
 
 func (h *Handlers) RegisterRoutes(mux *http.ServeMux, doShutdown func()) {
 	mux.HandleFunc("GET /health", h.HandleHealth)
+	mux.HandleFunc("GET /health/live", h.HandleLive)
+	mux.HandleFunc("GET /health/ready", h.HandleReady)
+	mux.HandleFunc("GET /metrics", h.HandleMetrics)
 	mux.HandleFunc("GET /tabs", h.HandleTabs)
 	mux.HandleFunc("GET /snapshot", h.HandleSnapshot)
 	mux.HandleFunc("GET /screenshot", h.HandleScreenshot)
+	mux.HandleFunc("POST /screenshot/compare", h.HandleScreenshotCompare)
+	mux.HandleFunc("GET /screenshot/baselines", h.HandleBaselineList)
+	mux.HandleFunc("POST /screenshot/baselines/{name}", h.HandleBaselineReplace)
+	mux.HandleFunc("POST /screenshot/baselines/{name}/accept", h.HandleBaselineAccept)
+	mux.HandleFunc("DELETE /screenshot/baselines/{name}", h.HandleBaselineDelete)
 	mux.HandleFunc("GET /pdf", h.HandlePDF)
+	mux.HandleFunc("GET /archive", h.HandleArchive)
 	mux.HandleFunc("GET /text", h.HandleText)
+	mux.HandleFunc("GET /perf", h.HandlePerf)
+	mux.HandleFunc("POST /trace/start", h.HandleTraceStart)
+	mux.HandleFunc("POST /trace/stop", h.HandleTraceStop)
 	mux.HandleFunc("POST /navigate", h.HandleNavigate)
 	mux.HandleFunc("POST /action", h.HandleAction)
 	mux.HandleFunc("POST /actions", h.HandleActions)
 	mux.HandleFunc("POST /evaluate", h.HandleEvaluate)
+	mux.HandleFunc("POST /evaluate/release", h.HandleEvaluateRelease)
 	mux.HandleFunc("POST /tab", h.HandleTab)
+	mux.HandleFunc("POST /tab/activate", h.HandleTabActivate)
 	mux.HandleFunc("POST /tab/lock", h.HandleTabLock)
+	mux.HandleFunc("POST /tab/lock/renew", h.HandleTabLockRenew)
 	mux.HandleFunc("POST /tab/unlock", h.HandleTabUnlock)
+	mux.HandleFunc("POST /handoff", h.HandleHandoff)
+	mux.HandleFunc("GET /handoff", h.HandleHandoffList)
+	mux.HandleFunc("GET /handoff/{id}", h.HandleHandoffGet)
+	mux.HandleFunc("GET /handoff/{id}/wait", h.HandleHandoffWait)
+	mux.HandleFunc("POST /handoff/{id}/resume", h.HandleHandoffResume)
+	mux.HandleFunc("POST /scripts", h.HandleScriptAdd)
+	mux.HandleFunc("GET /scripts", h.HandleScriptList)
+	mux.HandleFunc("DELETE /scripts/{name}", h.HandleScriptDelete)
+	mux.HandleFunc("GET /sessions", h.HandleSessionList)
+	mux.HandleFunc("GET /sessions/{name}", h.HandleSessionGet)
+	mux.HandleFunc("POST /sessions/{name}", h.HandleSessionSave)
+	mux.HandleFunc("POST /sessions/{name}/restore", h.HandleSessionRestore)
+	mux.HandleFunc("DELETE /sessions/{name}", h.HandleSessionDelete)
 	mux.HandleFunc("GET /cookies", h.HandleGetCookies)
 	mux.HandleFunc("POST /cookies", h.HandleSetCookies)
 	mux.HandleFunc("GET /stealth/status", h.HandleStealthStatus)
@@ -50,6 +90,11 @@ func (h *Handlers) RegisterRoutes(mux *http.ServeMux, doShutdown func()) {
 	mux.HandleFunc("POST /upload", h.HandleUpload)
 	mux.HandleFunc("GET /screencast", h.HandleScreencast)
 	mux.HandleFunc("GET /screencast/tabs", h.HandleScreencastAll)
+	mux.HandleFunc("POST /recordings", h.HandleRecording)
+	mux.HandleFunc("GET /recordings", h.HandleRecordingList)
+	mux.HandleFunc("GET /recordings/{id}", h.HandleRecordingGet)
+	mux.HandleFunc("GET /recordings/{id}/frames/{n}", h.HandleRecordingFrame)
+	mux.HandleFunc("DELETE /recordings/{id}", h.HandleRecordingDelete)
 	mux.HandleFunc("GET /welcome", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		_, _ = w.Write([]byte(assets.WelcomeHTML))
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"bytes"
+	"context"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -42,6 +45,39 @@ func TestHandleEvaluate_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestHandleEvaluate_InvalidWorld(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	body := `{"expression":"1+1","world":"sandbox"}`
+	req := httptest.NewRequest("POST", "/evaluate", bytes.NewReader([]byte(body)))
+	w := httptest.NewRecorder()
+	h.HandleEvaluate(w, req)
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleEvaluate_UnknownRef(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	body := `{"expression":"el => el.value","ref":"e9"}`
+	req := httptest.NewRequest("POST", "/evaluate", bytes.NewReader([]byte(body)))
+	w := httptest.NewRecorder()
+	h.HandleEvaluate(w, req)
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleEvaluate_NoTab(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{}, nil, nil, nil)
+	body := `{"expression":"(a, b) => a + b","args":[1,2]}`
+	req := httptest.NewRequest("POST", "/evaluate", bytes.NewReader([]byte(body)))
+	w := httptest.NewRecorder()
+	h.HandleEvaluate(w, req)
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
 func TestHandleTab_InvalidJSON(t *testing.T) {
 	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
 	req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`not json`)))
@@ -101,3 +137,121 @@ func TestHandleShutdown_CallsFunc(t *testing.T) {
 		t.Error("expected doShutdown to be called within 500ms")
 	}
 }
+
+type queuedBridge struct {
+	mockBridge
+	acquired int
+}
+
+func (b *queuedBridge) AcquireTab(ctx context.Context, tabID string) (func(), error) {
+	b.acquired++
+	return func() {}, nil
+}
+
+func (b *queuedBridge) TabQueueDepth(tabID string) int { return 2 }
+
+func TestHandleTabs_QueueDepth(t *testing.T) {
+	h := New(&queuedBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleTabs(w, httptest.NewRequest("GET", "/tabs", nil))
+	if !strings.Contains(w.Body.String(), `"queueDepth":2`) {
+		t.Errorf("expected queueDepth in /tabs, got %s", w.Body.String())
+	}
+}
+
+func TestHandleSnapshot_ReadOnlySkipsQueue(t *testing.T) {
+	b := &queuedBridge{}
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+	h.HandleSnapshot(httptest.NewRecorder(), httptest.NewRequest("GET", "/snapshot?readOnly=true", nil))
+	if b.acquired != 0 {
+		t.Errorf("read-only snapshot should not queue, acquired %d", b.acquired)
+	}
+	h.HandleSnapshot(httptest.NewRecorder(), httptest.NewRequest("GET", "/snapshot", nil))
+	if b.acquired != 1 {
+		t.Errorf("snapshot should queue, acquired %d", b.acquired)
+	}
+}
+
+// busyBridge never gives up a tab's turn.
+type busyBridge struct{ mockBridge }
+
+func (b *busyBridge) AcquireTab(ctx context.Context, tabID string) (func(), error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestQueueTab_WaitIsBounded(t *testing.T) {
+	h := New(&busyBridge{}, &config.RuntimeConfig{ActionTimeout: 20 * time.Millisecond}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	if _, ok := h.queueTab(w, httptest.NewRequest("POST", "/action", nil), "tab1"); ok {
+		t.Fatal("expected the wait to give up")
+	}
+	if w.Code != 503 {
+		t.Errorf("expected 503, got %d", w.Code)
+	}
+}
+
+func TestHandleScreenshot_AnnotateQueues(t *testing.T) {
+	b := &queuedBridge{}
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+	h.HandleScreenshot(httptest.NewRecorder(), httptest.NewRequest("GET", "/screenshot", nil))
+	if b.acquired != 0 {
+		t.Errorf("plain screenshot should not queue, acquired %d", b.acquired)
+	}
+	h.HandleScreenshot(httptest.NewRecorder(), httptest.NewRequest("GET", "/screenshot?annotate=true", nil))
+	if b.acquired != 1 {
+		t.Errorf("annotated screenshot should queue, acquired %d", b.acquired)
+	}
+}
+
+func TestTabMutations_Queue(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler func(*Handlers) http.HandlerFunc
+		path    string
+		body    string
+	}{
+		{"cookies", func(h *Handlers) http.HandlerFunc { return h.HandleSetCookies }, "/cookies", `{"url":"https://example.com","cookies":[{"name":"a","value":"b"}]}`},
+		{"fingerprint", func(h *Handlers) http.HandlerFunc { return h.HandleFingerprintRotate }, "/fingerprint/rotate", `{}`},
+		{"trace start", func(h *Handlers) http.HandlerFunc { return h.HandleTraceStart }, "/trace/start", `{}`},
+		{"trace stop", func(h *Handlers) http.HandlerFunc { return h.HandleTraceStop }, "/trace/stop", `{}`},
+	}
+	for _, tc := range cases {
+		b := &queuedBridge{}
+		h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+		tc.handler(h)(httptest.NewRecorder(), httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body)))
+		if b.acquired != 1 {
+			t.Errorf("%s: expected one queue slot, acquired %d", tc.name, b.acquired)
+		}
+	}
+}
+
+func TestHandleEvaluate_RefAndHandle(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	body := `{"expression":"el => el.value","ref":"e1","handle":"42.1"}`
+	w := httptest.NewRecorder()
+	h.HandleEvaluate(w, httptest.NewRequest("POST", "/evaluate", strings.NewReader(body)))
+	if w.Code != 400 {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleEvaluateRelease_NoTab(t *testing.T) {
+	h := New(&mockBridge{failTab: true}, &config.RuntimeConfig{}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleEvaluateRelease(w, httptest.NewRequest("POST", "/evaluate/release", strings.NewReader(`{"handle":"42.1"}`)))
+	if w.Code != 404 {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestEvalHandleGroup_PerCaller(t *testing.T) {
+	req := httptest.NewRequest("POST", "/evaluate", nil)
+	if got := evalHandleGroup(req); got != "pinchtab-handles" {
+		t.Errorf("anonymous group %q", got)
+	}
+	req.Header.Set("X-Agent-Id", "agent-a")
+	if got := evalHandleGroup(req); got != "pinchtab-handles:agent-a" {
+		t.Errorf("agent group %q", got)
+	}
+}
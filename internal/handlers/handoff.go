@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/dashboard"
+	"github.com/pinchtab/pinchtab/internal/web"
+)
+
+const (
+	// handoffLockPrefix marks the lock a handoff holds while a human works
+	// on the tab. Each handoff locks under its own name, so no viewer or
+	// agent can release it by accident; remote control accepts input under
+	// it without taking the lock over.
+	handoffLockPrefix = "handoff:"
+
+	defaultHandoffLock = 30 * time.Minute
+	defaultHandoffWait = 5 * time.Minute
+	maxHandoffWait     = time.Hour
+	handoffRetention   = time.Hour
+
+	// handoffCheckInterval is how often a waiting agent checks that the
+	// handoff still holds its lock.
+	handoffCheckInterval = time.Second
+)
+
+type handoff struct {
+	ID        string     `json:"id"`
+	TabID     string     `json:"tabId"`
+	AgentID   string     `json:"agentId"`
+	Reason    string     `json:"reason"`
+	URL       string     `json:"url,omitempty"`
+	Status    string     `json:"status"` // waiting, resumed, expired
+	Note      string     `json:"note,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ResumedAt *time.Time `json:"resumedAt,omitempty"`
+
+	done    chan struct{}
+	endedAt time.Time
+}
+
+func handoffLockOwner(id string) string { return handoffLockPrefix + id }
+
+type handoffManager struct {
+	mu    sync.Mutex
+	items map[string]*handoff
+}
+
+func newHandoffManager() *handoffManager {
+	return &handoffManager{items: make(map[string]*handoff)}
+}
+
+func (m *handoffManager) get(id string) *handoff {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.items[id]
+}
+
+// endLocked moves a waiting handoff to its final status and wakes waiters.
+func (m *handoffManager) endLocked(ho *handoff, status string) {
+	ho.Status = status
+	ho.endedAt = time.Now()
+	close(ho.done)
+}
+
+// pruneLocked forgets handoffs that ended more than handoffRetention ago.
+func (m *handoffManager) pruneLocked() {
+	for id, ho := range m.items {
+		if !ho.endedAt.IsZero() && time.Since(ho.endedAt) > handoffRetention {
+			delete(m.items, id)
+		}
+	}
+}
+
+// snapshot returns a copy of the handoff that is safe to serialise.
+func (m *handoffManager) snapshot(ho *handoff) handoff {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return handoff{
+		ID: ho.ID, TabID: ho.TabID, AgentID: ho.AgentID, Reason: ho.Reason, URL: ho.URL,
+		Status: ho.Status, Note: ho.Note, CreatedAt: ho.CreatedAt, ResumedAt: ho.ResumedAt,
+	}
+}
+
+// checkHandoff expires a waiting handoff whose lock is gone: it ran out,
+// an operator broke it or the tab closed. Nobody will resume it.
+func (h *Handlers) checkHandoff(ho *handoff) {
+	h.handoffs.mu.Lock()
+	waiting := ho.Status == "waiting"
+	h.handoffs.mu.Unlock()
+	if !waiting {
+		return
+	}
+	if info := h.Bridge.TabLockInfo(ho.TabID); info != nil && info.Owner == handoffLockOwner(ho.ID) {
+		return
+	}
+
+	h.handoffs.mu.Lock()
+	if ho.Status != "waiting" {
+		h.handoffs.mu.Unlock()
+		return
+	}
+	h.handoffs.endLocked(ho, "expired")
+	h.handoffs.mu.Unlock()
+
+	slog.Info("handoff expired", "id", ho.ID, "tab", ho.TabID)
+	h.recordHandoffEvent(h.handoffs.snapshot(ho), "HANDOFF expired", "")
+}
+
+// handoffVisible reports whether the caller may see a handoff. In strict
+// mode that is the agent that asked for it or anyone who can see its tab.
+func (h *Handlers) handoffVisible(r *http.Request, ho *handoff) bool {
+	return !h.strictOwnership() || ho.AgentID == callerID(r, "") || h.canSeeTab(r, ho.TabID)
+}
+
+// lookupHandoff returns the handoff named in the path, up to date, or nil
+// if it doesn't exist or the caller may not see it.
+func (h *Handlers) lookupHandoff(r *http.Request) *handoff {
+	ho := h.handoffs.get(r.PathValue("id"))
+	if ho == nil || !h.handoffVisible(r, ho) {
+		return nil
+	}
+	h.checkHandoff(ho)
+	return ho
+}
+
+func (h *Handlers) recordHandoffEvent(ho handoff, action, detail string) {
+	if h.Dashboard == nil {
+		return
+	}
+	h.Dashboard.RecordEvent(dashboard.AgentEvent{
+		AgentID:   ho.AgentID,
+		Action:    action,
+		URL:       ho.URL,
+		TabID:     ho.TabID,
+		Detail:    detail,
+		Status:    200,
+		Timestamp: time.Now(),
+	})
+}
+
+// HandleHandoff pauses a tab for a human: it takes the tab lock on the
+// operator's behalf and surfaces the tab in the dashboard.
+//
+// The handoff belongs to the calling agent (agentId or X-Agent-Id).
+//
+// POST /handoff {"tabId":"...","reason":"CAPTCHA on checkout","agentId":"...","lockSec":1800}
+func (h *Handlers) HandleHandoff(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TabID   string `json:"tabId"`
+		Reason  string `json:"reason"`
+		AgentID string `json:"agentId"`
+		LockSec int    `json:"lockSec"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		web.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.Reason == "" {
+		web.Error(w, 400, fmt.Errorf("reason required"))
+		return
+	}
+	req.AgentID = callerID(r, req.AgentID)
+	r = withCaller(r, req.AgentID)
+	if req.AgentID == "" {
+		req.AgentID = "anonymous"
+	}
+	ttl := defaultHandoffLock
+	if req.LockSec > 0 {
+		ttl = time.Duration(req.LockSec) * time.Second
+	}
+
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		web.Error(w, 404, err)
+		return
+	}
+
+	id := "ho-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	lockOwner := handoffLockOwner(id)
+
+	// An agent handing off its own locked tab passes the lock to the human.
+	if info := h.Bridge.TabLockInfo(resolvedTabID); info != nil {
+		if info.Owner != req.AgentID {
+			web.Error(w, 409, fmt.Errorf("tab is locked by %s", info.Owner))
+			return
+		}
+		if err := h.Bridge.Unlock(resolvedTabID, req.AgentID); err != nil {
+			web.Error(w, 409, err)
+			return
+		}
+	}
+	if err := h.Bridge.Lock(resolvedTabID, lockOwner, ttl); err != nil {
+		web.Error(w, 409, err)
+		return
+	}
+
+	ho := &handoff{
+		ID:        id,
+		TabID:     resolvedTabID,
+		AgentID:   req.AgentID,
+		Reason:    req.Reason,
+		Status:    "waiting",
+		CreatedAt: time.Now().UTC(),
+		done:      make(chan struct{}),
+	}
+	lctx, lcancel := context.WithTimeout(ctx, 2*time.Second)
+	_ = chromedp.Run(lctx, chromedp.Location(&ho.URL))
+	lcancel()
+
+	h.handoffs.mu.Lock()
+	h.handoffs.pruneLocked()
+	h.handoffs.items[ho.ID] = ho
+	h.handoffs.mu.Unlock()
+
+	slog.Info("handoff requested", "id", ho.ID, "tab", resolvedTabID, "agent", req.AgentID, "reason", req.Reason)
+	snap := h.handoffs.snapshot(ho)
+	h.recordHandoffEvent(snap, "HANDOFF requested", req.Reason)
+	web.JSON(w, 200, snap)
+}
+
+// HandleHandoffList returns the handoffs the caller may see, waiting ones
+// first. Ended handoffs are kept for handoffRetention.
+func (h *Handlers) HandleHandoffList(w http.ResponseWriter, r *http.Request) {
+	h.handoffs.mu.Lock()
+	h.handoffs.pruneLocked()
+	list := make([]*handoff, 0, len(h.handoffs.items))
+	for _, ho := range h.handoffs.items {
+		list = append(list, ho)
+	}
+	h.handoffs.mu.Unlock()
+
+	out := make([]handoff, 0, len(list))
+	for _, ho := range list {
+		if !h.handoffVisible(r, ho) {
+			continue
+		}
+		h.checkHandoff(ho)
+		out = append(out, h.handoffs.snapshot(ho))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if (out[i].Status == "waiting") != (out[j].Status == "waiting") {
+			return out[i].Status == "waiting"
+		}
+		return out[i].CreatedAt.After(out[j].CreatedAt)
+	})
+	web.JSON(w, 200, map[string]any{"handoffs": out})
+}
+
+// HandleHandoffGet returns the current state of one handoff.
+func (h *Handlers) HandleHandoffGet(w http.ResponseWriter, r *http.Request) {
+	ho := h.lookupHandoff(r)
+	if ho == nil {
+		web.Error(w, 404, fmt.Errorf("handoff not found"))
+		return
+	}
+	web.JSON(w, 200, h.handoffs.snapshot(ho))
+}
+
+// HandleHandoffWait blocks until the operator resumes the handoff, its lock
+// goes away (status expired), the timeout passes (408, the agent should call
+// again) or the client goes away.
+//
+// GET /handoff/{id}/wait?timeoutSec=300
+func (h *Handlers) HandleHandoffWait(w http.ResponseWriter, r *http.Request) {
+	ho := h.lookupHandoff(r)
+	if ho == nil {
+		web.Error(w, 404, fmt.Errorf("handoff not found"))
+		return
+	}
+
+	wait := defaultHandoffWait
+	if s := r.URL.Query().Get("timeoutSec"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			web.Error(w, 400, fmt.Errorf("invalid timeoutSec"))
+			return
+		}
+		wait = min(time.Duration(n)*time.Second, maxHandoffWait)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	check := time.NewTicker(handoffCheckInterval)
+	defer check.Stop()
+	for {
+		select {
+		case <-ho.done:
+			web.JSON(w, 200, h.handoffs.snapshot(ho))
+			return
+		case <-check.C:
+			h.checkHandoff(ho)
+		case <-timer.C:
+			web.JSON(w, 408, h.handoffs.snapshot(ho))
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// HandleHandoffResume is called by the operator when the human part is done.
+// It releases the tab lock and wakes any waiting agent with the note.
+//
+// POST /handoff/{id}/resume {"note":"solved the captcha, you're logged in"}
+func (h *Handlers) HandleHandoffResume(w http.ResponseWriter, r *http.Request) {
+	ho := h.handoffs.get(r.PathValue("id"))
+	if ho == nil {
+		web.Error(w, 404, fmt.Errorf("handoff not found"))
+		return
+	}
+	var req struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+			web.Error(w, 400, fmt.Errorf("decode: %w", err))
+			return
+		}
+	}
+
+	h.checkHandoff(ho)
+	h.handoffs.mu.Lock()
+	if ho.Status != "waiting" {
+		h.handoffs.mu.Unlock()
+		web.Error(w, 409, fmt.Errorf("handoff already %s", ho.Status))
+		return
+	}
+	now := time.Now().UTC()
+	ho.Note = req.Note
+	ho.ResumedAt = &now
+	h.handoffs.endLocked(ho, "resumed")
+	h.handoffs.mu.Unlock()
+
+	if err := h.Bridge.Unlock(ho.TabID, handoffLockOwner(ho.ID)); err != nil {
+		slog.Warn("handoff unlock", "id", ho.ID, "err", err)
+	}
+
+	slog.Info("handoff resumed", "id", ho.ID, "tab", ho.TabID)
+	snap := h.handoffs.snapshot(ho)
+	h.recordHandoffEvent(snap, "HANDOFF resumed", req.Note)
+	web.JSON(w, 200, snap)
+}
@@ -4,16 +4,97 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/chromedp/cdproto/target"
+	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/web"
 )
 
+// HandleHealth reports the bridge status. It answers 200 even while Chrome
+// is down so existing monitors keep parsing it; /health/ready carries the
+// status code. ?verbose=true adds browser and runtime diagnostics.
+//
+// GET /health
 func (h *Handlers) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	resp, targets, _ := h.healthStatus()
+	if r.URL.Query().Get("verbose") == "true" {
+		h.addDiagnostics(r, resp, targets)
+	}
+	web.JSON(w, 200, resp)
+}
+
+// HandleLive answers as long as the HTTP server is up, whatever Chrome is
+// doing. Supervisors restart the process when it stops answering.
+//
+// GET /health/live
+func (h *Handlers) HandleLive(w http.ResponseWriter, r *http.Request) {
+	web.JSON(w, 200, map[string]any{"status": "ok"})
+}
+
+// HandleReady answers 503 while Chrome is disconnected or being recovered,
+// so load balancers and the orchestrator only send work to a usable bridge.
+// Probes skip the auth token, so only the status goes out; the details are
+// on /health.
+//
+// GET /health/ready
+func (h *Handlers) HandleReady(w http.ResponseWriter, r *http.Request) {
+	resp, _, ready := h.healthStatus()
+	code := 200
+	if !ready {
+		code = 503
+	}
+	web.JSON(w, code, map[string]any{"status": resp["status"]})
+}
+
+func (h *Handlers) healthStatus() (map[string]any, []*target.Info, bool) {
+	rec := h.Bridge.RecoveryStatus()
+	if rec.State == bridge.RecoveryInProgress {
+		return map[string]any{"status": "recovering", "recovery": rec, "cdp": h.Config.CdpURL}, nil, false
+	}
 	targets, err := h.Bridge.ListTargets()
 	if err != nil {
-		web.JSON(w, 200, map[string]any{"status": "disconnected", "error": err.Error(), "cdp": h.Config.CdpURL})
-		return
+		return map[string]any{"status": "disconnected", "error": err.Error(), "cdp": h.Config.CdpURL}, nil, false
 	}
-	web.JSON(w, 200, map[string]any{"status": "ok", "tabs": len(targets), "cdp": h.Config.CdpURL})
+	resp := map[string]any{"status": "ok", "tabs": len(targets), "cdp": h.Config.CdpURL}
+	if rec.Crashes > 0 {
+		resp["recovery"] = rec
+	}
+	return resp, targets, true
+}
+
+// addDiagnostics fills in the verbose health fields. Locks and queues only
+// cover tabs the caller can see.
+func (h *Handlers) addDiagnostics(r *http.Request, resp map[string]any, targets []*target.Info) {
+	if info, err := h.Bridge.BrowserInfo(r.Context()); err != nil {
+		resp["browserError"] = err.Error()
+	} else {
+		resp["browser"] = info
+	}
+	resp["startedAt"] = h.started.UTC().Format(time.RFC3339)
+	resp["uptimeSec"] = int64(time.Since(h.started).Seconds())
+	resp["stealth"] = h.Config.StealthLevel
+	resp["configDigest"] = h.Config.Digest()
+
+	locks := []map[string]any{}
+	queues := map[string]int{}
+	for _, t := range targets {
+		id := string(t.TargetID)
+		if !h.canSeeTab(r, id) {
+			continue
+		}
+		if depth := h.Bridge.TabQueueDepth(id); depth > 0 {
+			queues[id] = depth
+		}
+		if lock := h.Bridge.TabLockInfo(id); lock != nil {
+			locks = append(locks, map[string]any{
+				"tabId":     id,
+				"owner":     lock.Owner,
+				"expiresAt": lock.ExpiresAt.Format(time.RFC3339),
+				"waiters":   lock.Waiters,
+			})
+		}
+	}
+	resp["locks"] = locks
+	resp["queues"] = queues
 }
 
 func (h *Handlers) HandleTabs(w http.ResponseWriter, r *http.Request) {
@@ -25,15 +106,34 @@ func (h *Handlers) HandleTabs(w http.ResponseWriter, r *http.Request) {
 
 	tabs := make([]map[string]any, 0, len(targets))
 	for _, t := range targets {
+		if !h.canSeeTab(r, string(t.TargetID)) {
+			continue
+		}
 		entry := map[string]any{
 			"id":    string(t.TargetID),
 			"url":   t.URL,
 			"title": t.Title,
 			"type":  t.Type,
 		}
+		if t.OpenerID != "" {
+			entry["openerId"] = string(t.OpenerID)
+		}
+		if agent := h.Bridge.TabOwner(string(t.TargetID)); agent != "" {
+			entry["createdBy"] = agent
+		}
+		if depth := h.Bridge.TabQueueDepth(string(t.TargetID)); depth > 0 {
+			entry["queueDepth"] = depth
+		}
+		if crash := h.Bridge.TabCrash(string(t.TargetID)); crash != nil {
+			entry["crashed"] = true
+			entry["crash"] = crash
+		}
 		if lock := h.Bridge.TabLockInfo(string(t.TargetID)); lock != nil {
 			entry["owner"] = lock.Owner
 			entry["lockedUntil"] = lock.ExpiresAt.Format(time.RFC3339)
+			if lock.Waiters > 0 {
+				entry["lockWaiters"] = lock.Waiters
+			}
 		}
 		tabs = append(tabs, entry)
 	}
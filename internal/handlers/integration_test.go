@@ -37,6 +37,25 @@ func (m *integrationMockBridge) TabLockInfo(tabID string) *bridge.LockInfo {
 	return nil
 }
 
+func (m *integrationMockBridge) TabQueueDepth(tabID string) int { return 0 }
+
+func (m *integrationMockBridge) TabOwner(tabID string) string { return "" }
+
+func (m *integrationMockBridge) CurrentTab(agentID string) string { return "" }
+
+func (m *integrationMockBridge) MapTabID(tabID string) string { return tabID }
+
+func (m *integrationMockBridge) TabCrash(tabID string) *bridge.TabCrash { return nil }
+func (m *integrationMockBridge) BrowserInfo(ctx context.Context) (bridge.BrowserInfo, error) {
+	return bridge.BrowserInfo{}, nil
+}
+
+func (m *integrationMockBridge) ClearTabCrash(tabID string) {}
+
+func (m *integrationMockBridge) RecoveryStatus() bridge.RecoveryStatus {
+	return bridge.RecoveryStatus{State: bridge.RecoveryOK}
+}
+
 func TestIntegration_RoutesRegistration(t *testing.T) {
 	b := &integrationMockBridge{tabs: make(map[string]context.Context)}
 	cfg := &config.RuntimeConfig{}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -11,27 +12,120 @@ import (
 	"github.com/pinchtab/pinchtab/internal/web"
 )
 
+type callerKey struct{}
+
+// withCaller records the owner field of a request body, so everything that
+// later asks who the caller is (tab visibility, the current tab, ownership of
+// new tabs) gets the same answer as the lock checks.
+func withCaller(r *http.Request, owner string) *http.Request {
+	if owner == "" {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), callerKey{}, owner))
+}
+
+// callerID identifies who is making a request. An explicit owner field wins
+// over the X-Agent-Id header; anonymous callers get "".
+func callerID(r *http.Request, owner string) string {
+	if owner != "" {
+		return owner
+	}
+	if owner, _ := r.Context().Value(callerKey{}).(string); owner != "" {
+		return owner
+	}
+	return r.Header.Get("X-Agent-Id")
+}
+
+// lockConflict returns the lock blocking the caller from mutating tabID, or
+// nil if the tab is free or held by the caller. Operators who need a locked
+// tab take it over from the dashboard's live view.
+func (h *Handlers) lockConflict(r *http.Request, tabID, owner string) *bridge.LockInfo {
+	info := h.Bridge.TabLockInfo(tabID)
+	if info == nil || callerID(r, owner) == info.Owner {
+		return nil
+	}
+	return info
+}
+
+// checkTabLock writes a 423 and returns false when another owner holds the
+// tab lock.
+func (h *Handlers) checkTabLock(w http.ResponseWriter, r *http.Request, tabID, owner string) bool {
+	info := h.lockConflict(r, tabID, owner)
+	if info == nil {
+		return true
+	}
+	web.JSON(w, 423, map[string]any{
+		"error":     fmt.Sprintf("tab %s is locked by %s", tabID, info.Owner),
+		"owner":     info.Owner,
+		"expiresAt": info.ExpiresAt.Format(time.RFC3339),
+	})
+	return false
+}
+
+// acquireTab waits for the tab's turn, for at most ActionTimeout.
+func (h *Handlers) acquireTab(r *http.Request, tabID string) (release func(), err error) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.Config.ActionTimeout)
+	defer cancel()
+	return h.Bridge.AcquireTab(ctx, tabID)
+}
+
+// queueTab waits for the tab's turn so CDP-mutating work doesn't interleave
+// with other requests on it. On failure it writes the response itself.
+func (h *Handlers) queueTab(w http.ResponseWriter, r *http.Request, tabID string) (release func(), ok bool) {
+	release, err := h.acquireTab(r, tabID)
+	if err != nil {
+		web.Error(w, 503, fmt.Errorf("waiting for tab %s: %w", tabID, err))
+		return nil, false
+	}
+	return release, true
+}
+
+// maxLockWait caps how long POST /tab/lock may queue for a busy tab.
+const maxLockWait = 5 * time.Minute
+
+// HandleTabLock takes a tab lock. With waitSec the caller queues FIFO behind
+// the current holder instead of failing straight away.
+//
+// POST /tab/lock {"tabId":"...","owner":"agent-1","timeoutSec":30,"waitSec":60}
 func (h *Handlers) HandleTabLock(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		TabID      string `json:"tabId"`
 		Owner      string `json:"owner"`
 		TimeoutSec int    `json:"timeoutSec"`
+		WaitSec    int    `json:"waitSec"`
 	}
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
 		web.Error(w, 400, fmt.Errorf("decode: %w", err))
 		return
 	}
+	req.Owner = callerID(r, req.Owner)
+	r = withCaller(r, req.Owner)
 	if req.TabID == "" || req.Owner == "" {
 		web.Error(w, 400, fmt.Errorf("tabId and owner required"))
 		return
 	}
-
+	// Locks are keyed by the live tab ID, so IDs from before a relaunch
+	// match the tab they now refer to.
+	req.TabID = h.Bridge.MapTabID(req.TabID)
+	if !h.canSeeTab(r, req.TabID) {
+		web.Error(w, 404, fmt.Errorf("tab %s not found", req.TabID))
+		return
+	}
 	timeout := bridge.DefaultLockTimeout
 	if req.TimeoutSec > 0 {
 		timeout = time.Duration(req.TimeoutSec) * time.Second
 	}
 
-	if err := h.Bridge.Lock(req.TabID, req.Owner, timeout); err != nil {
+	start := time.Now()
+	if req.WaitSec > 0 {
+		wait := min(time.Duration(req.WaitSec)*time.Second, maxLockWait)
+		ctx, cancel := context.WithTimeout(r.Context(), wait)
+		defer cancel()
+		if err := h.Bridge.LockWait(ctx, req.TabID, req.Owner, timeout); err != nil {
+			web.Error(w, 409, err)
+			return
+		}
+	} else if err := h.Bridge.Lock(req.TabID, req.Owner, timeout); err != nil {
 		web.Error(w, 409, err)
 		return
 	}
@@ -41,6 +135,41 @@ func (h *Handlers) HandleTabLock(w http.ResponseWriter, r *http.Request) {
 		"locked":    true,
 		"owner":     lock.Owner,
 		"expiresAt": lock.ExpiresAt.Format(time.RFC3339),
+		"waitedMs":  time.Since(start).Milliseconds(),
+	})
+}
+
+// HandleTabLockRenew is the heartbeat for short lock leases: agents renew
+// every few seconds, and a crashed agent's lock lapses after one lease.
+//
+// POST /tab/lock/renew {"tabId":"...","owner":"agent-1","timeoutSec":15}
+func (h *Handlers) HandleTabLockRenew(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TabID      string `json:"tabId"`
+		Owner      string `json:"owner"`
+		TimeoutSec int    `json:"timeoutSec"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		web.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	req.Owner = callerID(r, req.Owner)
+	r = withCaller(r, req.Owner)
+	if req.TabID == "" || req.Owner == "" {
+		web.Error(w, 400, fmt.Errorf("tabId and owner required"))
+		return
+	}
+	req.TabID = h.Bridge.MapTabID(req.TabID)
+
+	expires, err := h.Bridge.RenewLock(req.TabID, req.Owner, time.Duration(req.TimeoutSec)*time.Second)
+	if err != nil {
+		web.Error(w, 409, err)
+		return
+	}
+	web.JSON(w, 200, map[string]any{
+		"locked":    true,
+		"owner":     req.Owner,
+		"expiresAt": expires.Format(time.RFC3339),
 	})
 }
 
@@ -53,11 +182,13 @@ func (h *Handlers) HandleTabUnlock(w http.ResponseWriter, r *http.Request) {
 		web.Error(w, 400, fmt.Errorf("decode: %w", err))
 		return
 	}
+	req.Owner = callerID(r, req.Owner)
+	r = withCaller(r, req.Owner)
 	if req.TabID == "" || req.Owner == "" {
 		web.Error(w, 400, fmt.Errorf("tabId and owner required"))
 		return
 	}
-
+	req.TabID = h.Bridge.MapTabID(req.TabID)
 	if err := h.Bridge.Unlock(req.TabID, req.Owner); err != nil {
 		web.Error(w, 409, err)
 		return
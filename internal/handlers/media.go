@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/chromedp/cdproto/page"
@@ -22,13 +24,23 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 	tabID := r.URL.Query().Get("tabId")
 	output := r.URL.Query().Get("output")
 	reqNoAnim := r.URL.Query().Get("noAnimations") == "true"
+	annotate := r.URL.Query().Get("annotate") == "true"
 
-	ctx, _, err := h.Bridge.TabContext(tabID)
+	ctx, resolvedTabID, err := h.tabContext(r, tabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
 	}
 
+	if annotate {
+		// Annotating may snapshot the page and replace its ref cache.
+		release, ok := h.queueTab(w, r, resolvedTabID)
+		if !ok {
+			return
+		}
+		defer release()
+	}
+
 	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
 	defer tCancel()
 	go web.CancelOnClientDone(r.Context(), tCancel)
@@ -37,6 +49,16 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 		bridge.DisableAnimationsOnce(tCtx)
 	}
 
+	var marked []bridge.A11yNode
+	var boxes map[string]bridge.Box
+	if annotate {
+		marked, boxes, err = h.annotationBoxes(tCtx, resolvedTabID)
+		if err != nil {
+			web.Error(w, 500, fmt.Errorf("annotate: %w", err))
+			return
+		}
+	}
+
 	var buf []byte
 	quality := 80
 	if q := r.URL.Query().Get("quality"); q != "" {
@@ -55,10 +77,18 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 			return err
 		}),
 	); err != nil {
-		web.Error(w, 500, fmt.Errorf("screenshot: %w", err))
+		tabFailed(w, tCtx, 500, fmt.Errorf("screenshot: %w", err))
 		return
 	}
 
+	if annotate {
+		buf, err = bridge.AnnotateImage(buf, boxes, quality)
+		if err != nil {
+			web.Error(w, 500, fmt.Errorf("annotate: %w", err))
+			return
+		}
+	}
+
 	if output == "file" {
 		screenshotDir := filepath.Join(h.Config.StateDir, "screenshots")
 		if err := os.MkdirAll(screenshotDir, 0750); err != nil {
@@ -75,12 +105,17 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		web.JSON(w, 200, map[string]any{
+		resp := map[string]any{
 			"path":      filePath,
 			"size":      len(buf),
 			"format":    "jpeg",
 			"timestamp": timestamp,
-		})
+		}
+		if annotate {
+			resp["boxes"] = boxes
+			resp["nodes"] = marked
+		}
+		web.JSON(w, 200, resp)
 		return
 	}
 
@@ -92,43 +127,89 @@ func (h *Handlers) HandleScreenshot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	web.JSON(w, 200, map[string]any{
+	resp := map[string]any{
 		"format": "jpeg",
 		"base64": base64.StdEncoding.EncodeToString(buf),
-	})
+	}
+	if annotate {
+		resp["boxes"] = boxes
+		resp["nodes"] = marked
+	}
+	web.JSON(w, 200, resp)
+}
+
+// annotationBoxes resolves on-screen boxes for the tab's cached refs, taking a
+// fresh interactive snapshot when no cache exists. Only nodes that are visible
+// in the viewport are returned, so the node list and the marks match 1:1.
+func (h *Handlers) annotationBoxes(ctx context.Context, tabID string) ([]bridge.A11yNode, map[string]bridge.Box, error) {
+	cache := h.Bridge.GetRefCache(tabID)
+	if cache == nil || len(cache.Nodes) == 0 {
+		nodes, err := fetchAXTree(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		flat, refs := bridge.BuildSnapshot(nodes, bridge.FilterInteractive, -1)
+		cache = &bridge.RefCache{Refs: refs, Nodes: flat}
+		h.Bridge.SetRefCache(tabID, cache)
+	}
+
+	var dpr float64
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`window.devicePixelRatio`, &dpr)); err != nil || dpr <= 0 {
+		dpr = 1
+	}
+	var vw, vh float64
+	_ = chromedp.Run(ctx,
+		chromedp.Evaluate(`window.innerWidth`, &vw),
+		chromedp.Evaluate(`window.innerHeight`, &vh),
+	)
+
+	marked := make([]bridge.A11yNode, 0, len(cache.Nodes))
+	boxes := make(map[string]bridge.Box, len(cache.Nodes))
+	for _, n := range cache.Nodes {
+		if n.NodeID == 0 {
+			continue
+		}
+		b, err := bridge.NodeBox(ctx, n.NodeID)
+		if err != nil || b.Width <= 0 || b.Height <= 0 {
+			continue
+		}
+		if vw > 0 && vh > 0 && (b.X+b.Width <= 0 || b.Y+b.Height <= 0 || b.X >= vw || b.Y >= vh) {
+			continue
+		}
+		boxes[n.Ref] = bridge.Box{X: b.X * dpr, Y: b.Y * dpr, Width: b.Width * dpr, Height: b.Height * dpr}
+		marked = append(marked, n)
+	}
+	return marked, boxes, nil
 }
 
 func (h *Handlers) HandlePDF(w http.ResponseWriter, r *http.Request) {
 	tabID := r.URL.Query().Get("tabId")
 	output := r.URL.Query().Get("output")
 
-	ctx, _, err := h.Bridge.TabContext(tabID)
+	params, err := pdfParams(r.URL.Query())
+	if err != nil {
+		web.Error(w, 400, err)
+		return
+	}
+
+	ctx, resolvedTabID, err := h.tabContext(r, tabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
 	}
+	if !h.checkTabLock(w, r, resolvedTabID, "") {
+		return
+	}
 
 	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
 	defer tCancel()
 	go web.CancelOnClientDone(r.Context(), tCancel)
 
-	landscape := r.URL.Query().Get("landscape") == "true"
-	scale := 1.0
-	if s := r.URL.Query().Get("scale"); s != "" {
-		if sn, err := strconv.ParseFloat(s, 64); err == nil && sn > 0 {
-			scale = sn
-		}
-	}
-
 	var buf []byte
 	if err := chromedp.Run(tCtx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			var err error
-			p := page.PrintToPDF().
-				WithPrintBackground(true).
-				WithScale(scale).
-				WithLandscape(landscape)
-			buf, _, err = p.Do(ctx)
+			buf, _, err = params.Do(ctx)
 			return err
 		}),
 	); err != nil {
@@ -185,11 +266,147 @@ func (h *Handlers) HandlePDF(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// paperSizes maps paper format names to width x height in inches.
+var paperSizes = map[string][2]float64{
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+	"ledger":  {17, 11},
+	"a3":      {11.69, 16.54},
+	"a4":      {8.27, 11.69},
+	"a5":      {5.83, 8.27},
+	"a6":      {4.13, 5.83},
+}
+
+// checkPageRanges validates a printToPDF page range list such as "1-3,5,8-":
+// comma-separated pages or ranges, 1-based, either end of a range optional.
+// Ranges past the last page are left to Chrome.
+func checkPageRanges(s string) error {
+	for part := range strings.SplitSeq(s, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, err := pageNumber(from, isRange)
+		if err == nil && isRange {
+			var last int
+			if last, err = pageNumber(to, true); err == nil && first > 0 && last > 0 && first > last {
+				err = fmt.Errorf("starts after it ends")
+			}
+		}
+		if err != nil || (isRange && from == "" && to == "") {
+			return fmt.Errorf("invalid pageRanges %q: use pages and ranges like 1-3,5,8-", s)
+		}
+	}
+	return nil
+}
+
+// pageNumber parses one end of a page range; 0 stands for an omitted end.
+func pageNumber(s string, optional bool) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" && optional {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("bad page %q", s)
+	}
+	return n, nil
+}
+
+// pdfParams builds Page.printToPDF parameters from /pdf query params.
+// Sizes and margins are in inches.
+func pdfParams(q url.Values) (*page.PrintToPDFParams, error) {
+	p := page.PrintToPDF().
+		WithPrintBackground(true).
+		WithLandscape(q.Get("landscape") == "true")
+
+	scale := 1.0
+	if s := q.Get("scale"); s != "" {
+		if sn, err := strconv.ParseFloat(s, 64); err == nil && sn > 0 {
+			scale = sn
+		}
+	}
+	p = p.WithScale(scale)
+
+	inches := func(key string) (float64, bool, error) {
+		v := q.Get(key)
+		if v == "" {
+			return 0, false, nil
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 {
+			return 0, false, fmt.Errorf("%s must be a non-negative number of inches", key)
+		}
+		return n, true, nil
+	}
+
+	if f := q.Get("paperFormat"); f != "" {
+		size, ok := paperSizes[strings.ToLower(f)]
+		if !ok {
+			return nil, fmt.Errorf("unknown paperFormat %q (use A3, A4, A5, A6, Letter, Legal, Tabloid, Ledger or paperWidth/paperHeight)", f)
+		}
+		p = p.WithPaperWidth(size[0]).WithPaperHeight(size[1])
+	}
+	for _, key := range []string{"paperWidth", "paperHeight", "margin", "marginTop", "marginBottom", "marginLeft", "marginRight"} {
+		v, ok, err := inches(key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		switch key {
+		case "paperWidth":
+			p = p.WithPaperWidth(v)
+		case "paperHeight":
+			p = p.WithPaperHeight(v)
+		case "margin":
+			p = p.WithMarginTop(v).WithMarginBottom(v).WithMarginLeft(v).WithMarginRight(v)
+		case "marginTop":
+			p = p.WithMarginTop(v)
+		case "marginBottom":
+			p = p.WithMarginBottom(v)
+		case "marginLeft":
+			p = p.WithMarginLeft(v)
+		case "marginRight":
+			p = p.WithMarginRight(v)
+		}
+	}
+
+	if pr := q.Get("pageRanges"); pr != "" {
+		if err := checkPageRanges(pr); err != nil {
+			return nil, err
+		}
+		p = p.WithPageRanges(pr)
+	}
+
+	header, footer := q.Get("headerTemplate"), q.Get("footerTemplate")
+	if header != "" {
+		p = p.WithHeaderTemplate(header)
+	}
+	if footer != "" {
+		p = p.WithFooterTemplate(footer)
+	}
+	if q.Get("displayHeaderFooter") == "true" || (q.Get("displayHeaderFooter") == "" && (header != "" || footer != "")) {
+		p = p.WithDisplayHeaderFooter(true)
+	}
+
+	if q.Get("preferCSSPageSize") == "true" {
+		p = p.WithPreferCSSPageSize(true)
+	}
+	if q.Get("generateTaggedPDF") == "true" {
+		p = p.WithGenerateTaggedPDF(true)
+	}
+	if q.Get("generateDocumentOutline") == "true" {
+		p = p.WithGenerateDocumentOutline(true)
+	}
+
+	return p, nil
+}
+
 func (h *Handlers) HandleText(w http.ResponseWriter, r *http.Request) {
 	tabID := r.URL.Query().Get("tabId")
 	mode := r.URL.Query().Get("mode")
 
-	ctx, _, err := h.Bridge.TabContext(tabID)
+	ctx, _, err := h.tabContext(r, tabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
@@ -204,14 +421,14 @@ func (h *Handlers) HandleText(w http.ResponseWriter, r *http.Request) {
 		if err := chromedp.Run(tCtx,
 			chromedp.Evaluate(`document.body.innerText`, &text),
 		); err != nil {
-			web.Error(w, 500, fmt.Errorf("text extract: %w", err))
+			tabFailed(w, tCtx, 500, fmt.Errorf("text extract: %w", err))
 			return
 		}
 	} else {
 		if err := chromedp.Run(tCtx,
 			chromedp.Evaluate(assets.ReadabilityJS, &text),
 		); err != nil {
-			web.Error(w, 500, fmt.Errorf("text extract: %w", err))
+			tabFailed(w, tCtx, 500, fmt.Errorf("text extract: %w", err))
 			return
 		}
 	}
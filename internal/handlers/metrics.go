@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/metrics"
+)
+
+// Metrics recorded by this process. Request metrics come from
+// LoggingMiddleware, so the dashboard process records its own traffic too.
+var (
+	metricsRegistry = metrics.NewRegistry()
+
+	httpRequests = metricsRegistry.Counter("pinchtab_http_requests_total",
+		"HTTP requests served, by route pattern and status.", "method", "endpoint", "status")
+	httpDuration = metricsRegistry.Histogram("pinchtab_http_request_duration_seconds",
+		"HTTP request latency, by route pattern and status.", metrics.DefBuckets, "method", "endpoint", "status")
+	actionsTotal = metricsRegistry.Counter("pinchtab_actions_total",
+		"Actions executed, by kind.", "kind")
+	actionErrors = metricsRegistry.Counter("pinchtab_action_errors_total",
+		"Actions that failed, by kind.", "kind")
+	navDuration = metricsRegistry.Histogram("pinchtab_navigation_duration_seconds",
+		"Time to load a page in /navigate.", []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60}, "result")
+
+	tabsOpen = metricsRegistry.Gauge("pinchtab_tabs_open",
+		"Open page tabs.")
+	locksHeld = metricsRegistry.Gauge("pinchtab_locks_held",
+		"Tabs locked by an agent.")
+	screencastSubscribers = metricsRegistry.Gauge("pinchtab_screencast_subscribers",
+		"Clients watching a tab screencast.")
+	chromeUp = metricsRegistry.Gauge("pinchtab_chrome_up",
+		"1 when Chrome is connected, 0 while it is down or recovering.")
+	chromeRSS = metricsRegistry.Gauge("pinchtab_chrome_resident_memory_bytes",
+		"Resident memory of the Chrome process tree.")
+	chromeCPU = metricsRegistry.ObservedCounter("pinchtab_chrome_cpu_seconds_total",
+		"CPU time used by the Chrome process tree.")
+)
+
+// recordRequest counts a served request. The route pattern keeps the label
+// set small: /sessions/{name} rather than one series per name.
+func recordRequest(r *http.Request, status int, elapsed time.Duration) {
+	endpoint := r.Pattern
+	if _, path, ok := strings.Cut(endpoint, " "); ok {
+		endpoint = path
+	}
+	if endpoint == "" {
+		endpoint = "unmatched"
+	}
+	code := strconv.Itoa(status)
+	method := metricMethod(r.Method)
+	httpRequests.Inc(method, endpoint, code)
+	httpDuration.Observe(elapsed.Seconds(), method, endpoint, code)
+}
+
+// metricMethod folds non-standard methods into "OTHER" so clients can't mint
+// label values.
+func metricMethod(m string) string {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return m
+	}
+	return "OTHER"
+}
+
+// recordAction counts an executed action. Unknown kinds are left out; they
+// never ran and would let callers mint label values.
+func recordAction(kind string, err error) {
+	if errors.Is(err, bridge.ErrUnknownAction) {
+		return
+	}
+	actionsTotal.Inc(kind)
+	if err != nil {
+		actionErrors.Inc(kind)
+	}
+}
+
+func recordNavigation(elapsed time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	navDuration.Observe(elapsed.Seconds(), result)
+}
+
+// HandleMetrics serves Prometheus metrics for this bridge, followed by those
+// of every instance the orchestrator runs, labelled with instance="<id>".
+//
+// GET /metrics
+func (h *Handlers) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	h.sampleGauges(r.Context())
+	serveMetrics(w, r, h.Orchestrator)
+}
+
+// HandleDashboardMetrics serves the dashboard process's own request metrics
+// together with its instances' metrics.
+func HandleDashboardMetrics(o bridge.OrchestratorService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serveMetrics(w, r, o)
+	}
+}
+
+// sampleGauges reads the point-in-time values right before a scrape.
+func (h *Handlers) sampleGauges(ctx context.Context) {
+	screencastSubscribers.Set(float64(h.screencasts.subscribers()))
+
+	targets, err := h.Bridge.ListTargets()
+	if err != nil || h.Bridge.RecoveryStatus().State == bridge.RecoveryInProgress {
+		chromeUp.Set(0)
+		tabsOpen.Set(0)
+		locksHeld.Set(0)
+		return
+	}
+	chromeUp.Set(1)
+	locks := 0
+	for _, t := range targets {
+		if h.Bridge.TabLockInfo(string(t.TargetID)) != nil {
+			locks++
+		}
+	}
+	tabsOpen.Set(float64(len(targets)))
+	locksHeld.Set(float64(locks))
+
+	if info, err := h.Bridge.BrowserInfo(ctx); err == nil && info.PID > 0 {
+		chromeRSS.Set(float64(info.MemoryBytes))
+		chromeCPU.Set(info.CPUSeconds)
+	}
+}
+
+func serveMetrics(w http.ResponseWriter, r *http.Request, o bridge.OrchestratorService) {
+	var local bytes.Buffer
+	_ = metricsRegistry.WriteText(&local)
+
+	agg := metrics.NewAggregator()
+	_ = agg.Add(&local, "", "")
+	if o != nil {
+		scraped := o.InstanceMetrics(r.Context())
+		for _, id := range slices.Sorted(maps.Keys(scraped)) {
+			if err := agg.Add(bytes.NewReader(scraped[id]), "instance", id); err != nil {
+				slog.Debug("instance metrics", "instance", id, "err", err)
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = agg.WriteText(w)
+}
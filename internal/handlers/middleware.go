@@ -15,6 +15,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		start := time.Now()
 		sw := &web.StatusWriter{ResponseWriter: w, Code: 200}
 		next.ServeHTTP(sw, r)
+		recordRequest(r, sw.Code, time.Since(start))
 		slog.Info("request",
 			"method", r.Method,
 			"path", r.URL.Path,
@@ -26,7 +27,8 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 
 func AuthMiddleware(cfg *config.RuntimeConfig, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if cfg.Token != "" {
+		// Probes carry no credentials; they only answer with a status.
+		if cfg.Token != "" && !isProbePath(r.URL.Path) {
 			auth := r.Header.Get("Authorization")
 			if auth != "Bearer "+cfg.Token {
 				web.Error(w, 401, fmt.Errorf("unauthorized"))
@@ -37,11 +39,15 @@ func AuthMiddleware(cfg *config.RuntimeConfig, next http.Handler) http.Handler {
 	})
 }
 
+func isProbePath(path string) bool {
+	return path == "/health/live" || path == "/health/ready"
+}
+
 func CorsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Agent-Id")
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(204)
 			return
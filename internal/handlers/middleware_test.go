@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/web"
@@ -46,6 +47,27 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 	}
 }
 
+func TestAuthMiddleware_ProbesSkipToken(t *testing.T) {
+	cfg := &config.RuntimeConfig{Token: "secret123"}
+	handler := AuthMiddleware(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+
+	for _, path := range []string{"/health/live", "/health/ready"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != 200 {
+			t.Errorf("%s: expected 200 without token, got %d", path, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != 401 {
+		t.Errorf("/health: expected 401 without token, got %d", w.Code)
+	}
+}
+
 func TestAuthMiddleware_InvalidToken(t *testing.T) {
 	cfg := &config.RuntimeConfig{Token: "secret123"}
 
@@ -116,3 +138,31 @@ func TestStatusWriter(t *testing.T) {
 		t.Errorf("expected 404, got %d", sw.Code)
 	}
 }
+
+func TestDrainMiddleware(t *testing.T) {
+	d := &Drainer{RetryAfter: 10 * time.Second}
+	called := 0
+	handler := DrainMiddleware(d, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		w.WriteHeader(200)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/tabs", nil))
+	if w.Code != 200 || called != 1 {
+		t.Fatalf("expected request served before drain, got %d", w.Code)
+	}
+
+	d.Start()
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/action", nil))
+	if w.Code != 503 {
+		t.Errorf("expected 503 while draining, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "10" {
+		t.Errorf("expected Retry-After 10, got %q", w.Header().Get("Retry-After"))
+	}
+	if called != 1 {
+		t.Error("handler must not run while draining")
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -24,11 +25,15 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 		Timeout     float64 `json:"timeout"`
 		BlockImages *bool   `json:"blockImages"`
 		BlockMedia  *bool   `json:"blockMedia"`
+		Owner       string  `json:"owner"`
+		Shared      bool    `json:"shared"`
+		Perf        bool    `json:"perf"`
 	}
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
 		web.Error(w, 400, fmt.Errorf("decode: %w", err))
 		return
 	}
+	r = withCaller(r, req.Owner)
 	if req.URL == "" {
 		web.Error(w, 400, fmt.Errorf("url required"))
 		return
@@ -64,11 +69,12 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.NewTab {
-		newTargetID, newCtx, _, err := h.Bridge.CreateTab(req.URL)
+		newTargetID, newCtx, _, err := h.Bridge.CreateTab(req.URL, callerID(r, req.Owner))
 		if err != nil {
 			web.Error(w, 500, fmt.Errorf("new tab: %w", err))
 			return
 		}
+		h.claimTab(r, newTargetID, req.Owner, req.Shared)
 
 		tCtx, tCancel := context.WithTimeout(newCtx, navTimeout)
 		defer tCancel()
@@ -89,15 +95,34 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 			targetID = newTargetID
 		}
 
+		h.makeCurrent(r, targetID)
 		web.JSON(w, 200, map[string]any{"tabId": targetID, "url": url, "title": title})
 		return
 	}
 
-	ctx, resolvedTabID, err := h.Bridge.TabContext(req.TabID)
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
 	}
+	if !h.checkTabLock(w, r, resolvedTabID, req.Owner) {
+		return
+	}
+	release, ok := h.queueTab(w, r, resolvedTabID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	// Navigating is how a crashed tab is recovered, so it runs where other
+	// requests are refused.
+	if h.Bridge.TabCrash(resolvedTabID) != nil {
+		h.Bridge.ClearTabCrash(resolvedTabID)
+		if ctx, _, err = h.Bridge.TabContext(resolvedTabID); err != nil {
+			web.Error(w, 404, err)
+			return
+		}
+	}
 
 	tCtx, tCancel := context.WithTimeout(ctx, navTimeout)
 	defer tCancel()
@@ -109,56 +134,178 @@ func (h *Handlers) HandleNavigate(w http.ResponseWriter, r *http.Request) {
 		_ = bridge.SetResourceBlocking(tCtx, nil)
 	}
 
-	if err := bridge.NavigatePage(tCtx, req.URL); err != nil {
+	navStart := time.Now()
+	err = bridge.NavigatePage(tCtx, req.URL)
+	recordNavigation(time.Since(navStart), err)
+	if err != nil {
 		code := 500
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "invalid URL") || strings.Contains(errMsg, "Cannot navigate to invalid URL") || strings.Contains(errMsg, "ERR_INVALID_URL") {
 			code = 400
 		}
-		web.Error(w, code, fmt.Errorf("navigate: %w", err))
+		tabFailed(w, tCtx, code, fmt.Errorf("navigate: %w", err))
 		return
 	}
 
 	h.Bridge.DeleteRefCache(resolvedTabID)
+	h.makeCurrent(r, resolvedTabID)
 
 	var url string
 	_ = chromedp.Run(tCtx, chromedp.Location(&url))
 	title := bridge.WaitForTitle(tCtx, titleWait)
 
-	web.JSON(w, 200, map[string]any{"url": url, "title": title})
+	resp := map[string]any{"tabId": resolvedTabID, "url": url, "title": title}
+	if req.Perf {
+		if perf, err := bridge.PagePerf(tCtx); err == nil {
+			resp["perf"] = perf
+		} else {
+			resp["perfError"] = err.Error()
+		}
+	}
+	web.JSON(w, 200, resp)
 }
 
+// HandleEvaluate runs JavaScript in a tab. Plain expressions work as before;
+// with args, a ref or a handle the expression must be a function, called with
+// the ref's element or the handle's object (if any) followed by the args.
+// With returnByValue:false the result is kept in the page as a handle until
+// POST /evaluate/release or the page navigates.
+//
+// POST /evaluate {"expression":"(el, n) => el.children.length > n","ref":"e5","args":[3],"world":"isolated"}
 func (h *Handlers) HandleEvaluate(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		TabID      string `json:"tabId"`
-		Expression string `json:"expression"`
+		TabID         string            `json:"tabId"`
+		Expression    string            `json:"expression"`
+		Args          []json.RawMessage `json:"args"`
+		Ref           string            `json:"ref"`
+		Handle        string            `json:"handle"`
+		AwaitPromise  bool              `json:"awaitPromise"`
+		ReturnByValue *bool             `json:"returnByValue"`
+		Timeout       float64           `json:"timeout"`
+		World         string            `json:"world"`
+		Owner         string            `json:"owner"`
 	}
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
 		web.Error(w, 400, fmt.Errorf("decode: %w", err))
 		return
 	}
+	r = withCaller(r, req.Owner)
 	if req.Expression == "" {
 		web.Error(w, 400, fmt.Errorf("expression required"))
 		return
 	}
+	if req.World != "" && req.World != "main" && req.World != "isolated" {
+		web.Error(w, 400, fmt.Errorf("world must be 'main' or 'isolated'"))
+		return
+	}
+	if req.Ref != "" && req.Handle != "" {
+		web.Error(w, 400, fmt.Errorf("ref and handle are mutually exclusive"))
+		return
+	}
+
+	timeout := h.Config.ActionTimeout
+	if req.Timeout > 0 {
+		if req.Timeout > 120 {
+			req.Timeout = 120
+		}
+		timeout = time.Duration(req.Timeout * float64(time.Second))
+	}
 
-	ctx, _, err := h.Bridge.TabContext(req.TabID)
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
 	}
+	if !h.checkTabLock(w, r, resolvedTabID, req.Owner) {
+		return
+	}
+	release, ok := h.queueTab(w, r, resolvedTabID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	opts := bridge.EvalOptions{
+		Expression:    req.Expression,
+		Args:          req.Args,
+		ObjectID:      req.Handle,
+		AwaitPromise:  req.AwaitPromise,
+		ReturnByValue: req.ReturnByValue == nil || *req.ReturnByValue,
+		HandleGroup:   evalHandleGroup(r),
+		Isolated:      req.World == "isolated",
+		Timeout:       timeout,
+	}
+	if req.Ref != "" {
+		if cache := h.Bridge.GetRefCache(resolvedTabID); cache != nil {
+			opts.NodeID = cache.Refs[req.Ref]
+		}
+		if opts.NodeID == 0 {
+			web.Error(w, 400, fmt.Errorf("ref %s not found - take a /snapshot first", req.Ref))
+			return
+		}
+	}
 
-	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	tCtx, tCancel := context.WithTimeout(ctx, timeout)
 	defer tCancel()
 	go web.CancelOnClientDone(r.Context(), tCancel)
 
-	var result any
-	if err := chromedp.Run(tCtx, chromedp.Evaluate(req.Expression, &result)); err != nil {
+	res, err := bridge.Evaluate(tCtx, opts)
+	if err != nil {
+		if tabCrashErr(tCtx) != nil {
+			tabFailed(w, tCtx, 500, err)
+			return
+		}
+		if errors.Is(tCtx.Err(), context.DeadlineExceeded) {
+			web.Error(w, 408, fmt.Errorf("evaluate: timed out after %s", timeout))
+			return
+		}
 		web.Error(w, 500, fmt.Errorf("evaluate: %w", err))
 		return
 	}
 
-	web.JSON(w, 200, map[string]any{"result": result})
+	if res.Handle != nil {
+		web.JSON(w, 200, map[string]any{"handle": res.Handle})
+		return
+	}
+	web.JSON(w, 200, map[string]any{"result": res.Value})
+}
+
+// evalHandleGroup names the object group that keeps the caller's handles.
+func evalHandleGroup(r *http.Request) string {
+	if caller := callerID(r, ""); caller != "" {
+		return "pinchtab-handles:" + caller
+	}
+	return "pinchtab-handles"
+}
+
+// HandleEvaluateRelease frees a handle kept by /evaluate, or without one
+// every handle the caller holds in the tab.
+//
+// POST /evaluate/release {"tabId":"...","handle":"..."}
+func (h *Handlers) HandleEvaluateRelease(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TabID  string `json:"tabId"`
+		Handle string `json:"handle"`
+		Owner  string `json:"owner"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		web.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	r = withCaller(r, req.Owner)
+
+	ctx, _, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		web.Error(w, 404, err)
+		return
+	}
+	tCtx, tCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer tCancel()
+	if err := bridge.ReleaseHandles(tCtx, evalHandleGroup(r), req.Handle); err != nil {
+		web.Error(w, 500, fmt.Errorf("release: %w", err))
+		return
+	}
+	web.JSON(w, 200, map[string]any{"released": true})
 }
 
 const (
@@ -171,22 +318,27 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 		Action string `json:"action"`
 		TabID  string `json:"tabId"`
 		URL    string `json:"url"`
+		Owner  string `json:"owner"`
+		Shared bool   `json:"shared"`
 	}
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
 		web.Error(w, 400, fmt.Errorf("decode: %w", err))
 		return
 	}
+	r = withCaller(r, req.Owner)
 
 	switch req.Action {
 	case tabActionNew:
-		newTargetID, ctx, _, err := h.Bridge.CreateTab(req.URL)
+		newTargetID, ctx, _, err := h.Bridge.CreateTab(req.URL, callerID(r, req.Owner))
 		if err != nil {
 			web.Error(w, 500, err)
 			return
 		}
+		h.claimTab(r, newTargetID, req.Owner, req.Shared)
 
 		var curURL, title string
 		_ = chromedp.Run(ctx, chromedp.Location(&curURL), chromedp.Title(&title))
+		h.makeCurrent(r, newTargetID)
 		web.JSON(w, 200, map[string]any{"tabId": newTargetID, "url": curURL, "title": title})
 
 	case tabActionClose:
@@ -194,6 +346,14 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 			web.Error(w, 400, fmt.Errorf("tabId required"))
 			return
 		}
+		req.TabID = h.Bridge.MapTabID(req.TabID)
+		if !h.canSeeTab(r, req.TabID) {
+			web.Error(w, 404, fmt.Errorf("tab %s not found", req.TabID))
+			return
+		}
+		if !h.checkTabLock(w, r, req.TabID, req.Owner) {
+			return
+		}
 
 		if err := h.Bridge.CloseTab(req.TabID); err != nil {
 			web.Error(w, 500, err)
@@ -205,3 +365,38 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 		web.Error(w, 400, fmt.Errorf("action must be 'new' or 'close'"))
 	}
 }
+
+// HandleTabActivate brings a tab to the front and makes it the caller's
+// current tab, used whenever later requests omit tabId.
+//
+// POST /tab/activate {"tabId":"..."}
+func (h *Handlers) HandleTabActivate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TabID string `json:"tabId"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		web.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.TabID == "" {
+		web.Error(w, 400, fmt.Errorf("tabId required"))
+		return
+	}
+
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		web.Error(w, 404, err)
+		return
+	}
+	if err := h.Bridge.ActivateTab(resolvedTabID); err != nil {
+		web.Error(w, 500, err)
+		return
+	}
+	h.makeCurrent(r, resolvedTabID)
+
+	var curURL, title string
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	defer tCancel()
+	_ = chromedp.Run(tCtx, chromedp.Location(&curURL), chromedp.Title(&title))
+	web.JSON(w, 200, map[string]any{"tabId": resolvedTabID, "url": curURL, "title": title, "active": true})
+}
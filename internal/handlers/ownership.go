@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// tabOwnershipStrict scopes agents to the tabs they created plus shared ones.
+const tabOwnershipStrict = "strict"
+
+func (h *Handlers) strictOwnership() bool {
+	return h.Config != nil && h.Config.TabOwnership == tabOwnershipStrict
+}
+
+// canSeeTab reports whether the caller may see and act on tabID. Outside
+// strict mode every tab is visible; in it only shared tabs and the caller's
+// own, so anonymous callers see just the shared ones.
+func (h *Handlers) canSeeTab(r *http.Request, tabID string) bool {
+	if !h.strictOwnership() {
+		return true
+	}
+	owner := h.Bridge.TabOwner(tabID)
+	return owner == "" || owner == callerID(r, "")
+}
+
+// tabContext resolves a tab like Bridge.TabContext. An empty tabID means the
+// caller's current tab, falling back to the first visible one; IDs of tabs
+// lost in a browser crash resolve to their replacements. In strict mode other
+// agents' tabs look like they don't exist.
+func (h *Handlers) tabContext(r *http.Request, tabID string) (context.Context, string, error) {
+	if tabID != "" {
+		tabID = h.Bridge.MapTabID(tabID)
+	}
+	if tabID == "" {
+		if cur := h.Bridge.CurrentTab(callerID(r, "")); cur != "" && h.canSeeTab(r, cur) {
+			if ctx, id, err := h.Bridge.TabContext(cur); err == nil {
+				return ctx, id, nil
+			}
+		}
+	}
+	if tabID == "" && h.strictOwnership() {
+		targets, err := h.Bridge.ListTargets()
+		if err != nil {
+			return nil, "", fmt.Errorf("list targets: %w", err)
+		}
+		for _, t := range targets {
+			if id := string(t.TargetID); h.canSeeTab(r, id) {
+				tabID = id
+				break
+			}
+		}
+		if tabID == "" {
+			return nil, "", fmt.Errorf("no tabs open")
+		}
+	}
+	if !h.canSeeTab(r, tabID) {
+		return nil, "", fmt.Errorf("tab %s not found", tabID)
+	}
+	return h.Bridge.TabContext(tabID)
+}
+
+// claimTab records the calling agent as owner of a tab it just created, or
+// marks it shared if the caller asked for that.
+func (h *Handlers) claimTab(r *http.Request, tabID, owner string, shared bool) {
+	if shared {
+		h.Bridge.SetTabOwner(tabID, "")
+		return
+	}
+	if caller := callerID(r, owner); caller != "" {
+		h.Bridge.SetTabOwner(tabID, caller)
+	}
+}
+
+// makeCurrent records tabID as the caller's default tab.
+func (h *Handlers) makeCurrent(r *http.Request, tabID string) {
+	h.Bridge.SetCurrentTab(callerID(r, ""), tabID)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/web"
+)
+
+// HandlePerf returns Chrome's performance counters and the Web Vitals of the
+// page in a tab.
+//
+// GET /perf?tabId=
+func (h *Handlers) HandlePerf(w http.ResponseWriter, r *http.Request) {
+	ctx, resolvedTabID, err := h.tabContext(r, r.URL.Query().Get("tabId"))
+	if err != nil {
+		web.Error(w, 404, err)
+		return
+	}
+
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	defer tCancel()
+	go web.CancelOnClientDone(r.Context(), tCancel)
+
+	report, err := bridge.PagePerf(tCtx)
+	if err != nil {
+		tabFailed(w, tCtx, 500, err)
+		return
+	}
+	web.JSON(w, 200, map[string]any{
+		"tabId":   resolvedTabID,
+		"metrics": report.Metrics,
+		"vitals":  report.Vitals,
+	})
+}
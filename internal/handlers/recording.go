@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/web"
+)
+
+const (
+	defaultRecordingFPS    = 5
+	defaultRecordingMaxSec = 30 * 60
+	// AVI 1.0 offsets are 32-bit; stop well before that.
+	maxRecordingBytes = 1 << 30
+)
+
+var recordingIDRe = regexp.MustCompile(`^rec-[a-z0-9]+$`)
+
+type recordedFrame struct {
+	T      int64 `json:"t"` // ms since the first frame
+	Offset int64 `json:"offset"`
+	Size   int   `json:"size"`
+}
+
+// recording is both the live state of an in-progress capture and the JSON
+// metadata written next to the finished AVI.
+type recording struct {
+	ID         string          `json:"id"`
+	TabID      string          `json:"tabId"`
+	URL        string          `json:"url,omitempty"`
+	FPS        int             `json:"fps"`
+	StartedAt  time.Time       `json:"startedAt"`
+	StoppedAt  *time.Time      `json:"stoppedAt,omitempty"`
+	StopReason string          `json:"stopReason,omitempty"`
+	DurationMs int64           `json:"durationMs"`
+	Size       int64           `json:"size"`
+	FrameCount int             `json:"frameCount"`
+	Frames     []recordedFrame `json:"frames,omitempty"`
+
+	sub  *screencastSub
+	stop chan string
+	done chan struct{}
+	mu   sync.Mutex
+}
+
+type recordingManager struct {
+	mu     sync.Mutex
+	active map[string]*recording
+}
+
+func newRecordingManager() *recordingManager {
+	return &recordingManager{active: make(map[string]*recording)}
+}
+
+func (m *recordingManager) byTab(tabID string) *recording {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, rec := range m.active {
+		if rec.TabID == tabID {
+			return rec
+		}
+	}
+	return nil
+}
+
+// reserve registers rec as the tab's recording before it starts. If the tab
+// is already being recorded nothing changes and that recording is returned.
+func (m *recordingManager) reserve(rec *recording) *recording {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.active {
+		if existing.TabID == rec.TabID {
+			return existing
+		}
+	}
+	m.active[rec.ID] = rec
+	return nil
+}
+
+// abort drops a reserved recording that failed to start.
+func (m *recordingManager) abort(rec *recording) {
+	m.mu.Lock()
+	delete(m.active, rec.ID)
+	m.mu.Unlock()
+	close(rec.done)
+}
+
+func (m *recordingManager) get(id string) *recording {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active[id]
+}
+
+func (h *Handlers) recordingDir() string {
+	return filepath.Join(h.Config.StateDir, "recordings")
+}
+
+// HandleRecording starts or stops a screencast recording for a tab.
+//
+// POST /recordings {"action":"start","tabId":"...","fps":5,"quality":60,"maxWidth":1280,"maxSec":1800}
+// POST /recordings {"action":"stop","id":"rec-..."} (or "tabId")
+func (h *Handlers) HandleRecording(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Action   string `json:"action"`
+		TabID    string `json:"tabId"`
+		ID       string `json:"id"`
+		FPS      int    `json:"fps"`
+		Quality  int    `json:"quality"`
+		MaxWidth int    `json:"maxWidth"`
+		MaxSec   int    `json:"maxSec"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		web.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+
+	switch req.Action {
+	case "start":
+	case "stop":
+		rec := h.recordings.get(req.ID)
+		if rec == nil && req.ID == "" && req.TabID != "" {
+			if _, resolved, err := h.tabContext(r, req.TabID); err == nil {
+				rec = h.recordings.byTab(resolved)
+			}
+		}
+		if rec == nil {
+			web.Error(w, 404, fmt.Errorf("no active recording"))
+			return
+		}
+		select {
+		case rec.stop <- "stopped":
+		case <-rec.done:
+		}
+		<-rec.done
+		web.JSON(w, 200, rec.summary())
+		return
+	default:
+		web.Error(w, 400, fmt.Errorf("action must be 'start' or 'stop'"))
+		return
+	}
+
+	if req.FPS <= 0 {
+		req.FPS = defaultRecordingFPS
+	}
+	if req.FPS > 30 {
+		req.FPS = 30
+	}
+	if req.Quality <= 0 || req.Quality > 100 {
+		req.Quality = 60
+	}
+	if req.MaxWidth <= 0 {
+		req.MaxWidth = 1280
+	}
+	if req.MaxSec <= 0 {
+		req.MaxSec = defaultRecordingMaxSec
+	}
+
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		web.Error(w, 404, err)
+		return
+	}
+
+	var url string
+	_ = chromedp.Run(ctx, chromedp.Location(&url))
+	rec := &recording{
+		ID:        "rec-" + strconv.FormatInt(time.Now().UnixNano(), 36),
+		TabID:     resolvedTabID,
+		URL:       url,
+		FPS:       req.FPS,
+		StartedAt: time.Now().UTC(),
+		stop:      make(chan string, 1),
+		done:      make(chan struct{}),
+	}
+	// Claim the tab before starting anything so concurrent starts can't
+	// both win.
+	if existing := h.recordings.reserve(rec); existing != nil {
+		web.JSON(w, 409, map[string]any{"error": "tab is already being recorded", "id": existing.ID})
+		return
+	}
+
+	if err := os.MkdirAll(h.recordingDir(), 0750); err != nil {
+		h.recordings.abort(rec)
+		web.Error(w, 500, fmt.Errorf("create recording dir: %w", err))
+		return
+	}
+	f, err := os.OpenFile(filepath.Join(h.recordingDir(), rec.ID+".avi"), os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
+	if err != nil {
+		h.recordings.abort(rec)
+		web.Error(w, 500, fmt.Errorf("create recording: %w", err))
+		return
+	}
+	avi, err := bridge.NewAVIWriter(f, rec.FPS)
+	if err != nil {
+		_ = f.Close()
+		h.recordings.abort(rec)
+		web.Error(w, 500, fmt.Errorf("init recording: %w", err))
+		return
+	}
+
+	rec.sub, err = h.screencasts.subscribe(ctx, resolvedTabID, screencastOptions{
+		Quality:     req.Quality,
+		MaxWidth:    req.MaxWidth,
+		EveryNth:    1,
+		MinInterval: time.Second / time.Duration(rec.FPS),
+	})
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		h.recordings.abort(rec)
+		web.Error(w, 500, fmt.Errorf("start screencast: %w", err))
+		return
+	}
+
+	go h.runRecording(rec, f, avi, time.Duration(req.MaxSec)*time.Second)
+
+	slog.Info("recording started", "id", rec.ID, "tab", resolvedTabID, "fps", rec.FPS)
+	web.JSON(w, 200, map[string]any{"id": rec.ID, "tabId": resolvedTabID, "fps": rec.FPS, "startedAt": rec.StartedAt})
+}
+
+// StopRecordings stops every active recording and waits until each is
+// finalized, so a shutdown leaves complete files and their metadata.
+func (h *Handlers) StopRecordings() {
+	h.recordings.mu.Lock()
+	active := slices.Collect(maps.Values(h.recordings.active))
+	h.recordings.mu.Unlock()
+
+	for _, rec := range active {
+		select {
+		case rec.stop <- "shutdown":
+		case <-rec.done:
+		}
+	}
+	for _, rec := range active {
+		<-rec.done
+	}
+}
+
+func (h *Handlers) runRecording(rec *recording, f *os.File, avi *bridge.AVIWriter, maxDur time.Duration) {
+	limit := time.NewTimer(maxDur)
+	defer limit.Stop()
+
+	var first time.Time
+	reason := ""
+	for reason == "" {
+		select {
+		case frame := <-rec.sub.Frames:
+			if first.IsZero() {
+				first = frame.At
+			}
+			elapsed := frame.At.Sub(first)
+			slot := int(elapsed * time.Duration(rec.FPS) / time.Second)
+			if avi.Frames() > slot {
+				continue // this slot already has a frame
+			}
+			var err error
+			for avi.Frames() < slot && err == nil {
+				err = avi.RepeatFrame()
+			}
+			if err != nil {
+				reason = "write error: " + err.Error()
+				continue
+			}
+			off, err := avi.WriteFrame(frame.Data)
+			if err != nil {
+				slog.Warn("recording frame dropped", "id", rec.ID, "err", err)
+				continue
+			}
+			rec.mu.Lock()
+			rec.Frames = append(rec.Frames, recordedFrame{T: elapsed.Milliseconds(), Offset: off, Size: len(frame.Data)})
+			rec.mu.Unlock()
+			if avi.Size() > maxRecordingBytes {
+				reason = "max size reached"
+			}
+		case reason = <-rec.stop:
+		case <-limit.C:
+			reason = "max duration reached"
+		case <-rec.sub.Done:
+			reason = "tab closed"
+		}
+	}
+	h.screencasts.unsubscribe(rec.sub)
+
+	if err := avi.Close(); err != nil {
+		slog.Error("recording finalize", "id", rec.ID, "err", err)
+	}
+	_ = f.Close()
+
+	rec.mu.Lock()
+	stopped := time.Now().UTC()
+	rec.StoppedAt = &stopped
+	rec.StopReason = reason
+	rec.DurationMs = stopped.Sub(rec.StartedAt).Milliseconds()
+	rec.Size = avi.Size()
+	rec.FrameCount = len(rec.Frames)
+	meta, _ := json.MarshalIndent(rec, "", "  ")
+	rec.mu.Unlock()
+	if err := os.WriteFile(filepath.Join(h.recordingDir(), rec.ID+".json"), meta, 0600); err != nil {
+		slog.Error("recording metadata", "id", rec.ID, "err", err)
+	}
+
+	h.recordings.mu.Lock()
+	delete(h.recordings.active, rec.ID)
+	h.recordings.mu.Unlock()
+	close(rec.done)
+	slog.Info("recording stopped", "id", rec.ID, "reason", reason, "frames", rec.FrameCount)
+}
+
+// summary is the recording without its per-frame index.
+func (rec *recording) summary() map[string]any {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	out := map[string]any{
+		"id":         rec.ID,
+		"tabId":      rec.TabID,
+		"url":        rec.URL,
+		"fps":        rec.FPS,
+		"startedAt":  rec.StartedAt,
+		"frameCount": len(rec.Frames),
+		"active":     rec.StoppedAt == nil,
+	}
+	if rec.StoppedAt != nil {
+		out["stoppedAt"] = rec.StoppedAt
+		out["stopReason"] = rec.StopReason
+		out["durationMs"] = rec.DurationMs
+		out["size"] = rec.Size
+	}
+	return out
+}
+
+func (h *Handlers) loadRecording(id string) (*recording, error) {
+	data, err := os.ReadFile(filepath.Join(h.recordingDir(), id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("corrupt recording metadata: %w", err)
+	}
+	return &rec, nil
+}
+
+// lookupRecording validates the {id} path value and loads finished metadata.
+// It writes the error response itself and returns nil on failure.
+func (h *Handlers) lookupRecording(w http.ResponseWriter, r *http.Request) *recording {
+	id := r.PathValue("id")
+	if !recordingIDRe.MatchString(id) {
+		web.Error(w, 400, fmt.Errorf("invalid recording id"))
+		return nil
+	}
+	if h.recordings.get(id) != nil {
+		web.Error(w, 409, fmt.Errorf("recording %s is still in progress", id))
+		return nil
+	}
+	rec, err := h.loadRecording(id)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			web.Error(w, 404, fmt.Errorf("recording %s not found", id))
+		} else {
+			web.Error(w, 500, err)
+		}
+		return nil
+	}
+	return rec
+}
+
+// HandleRecordingList lists active and finished recordings, newest first.
+func (h *Handlers) HandleRecordingList(w http.ResponseWriter, r *http.Request) {
+	out := make([]map[string]any, 0)
+
+	h.recordings.mu.Lock()
+	active := make([]*recording, 0, len(h.recordings.active))
+	for _, rec := range h.recordings.active {
+		active = append(active, rec)
+	}
+	h.recordings.mu.Unlock()
+	for _, rec := range active {
+		out = append(out, rec.summary())
+	}
+
+	entries, err := os.ReadDir(h.recordingDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		web.Error(w, 500, err)
+		return
+	}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || !recordingIDRe.MatchString(id) {
+			continue
+		}
+		rec, err := h.loadRecording(id)
+		if err != nil {
+			continue
+		}
+		out = append(out, rec.summary())
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i]["startedAt"].(time.Time).After(out[j]["startedAt"].(time.Time))
+	})
+	web.JSON(w, 200, map[string]any{"recordings": out})
+}
+
+// HandleRecordingGet downloads a finished recording as MJPEG AVI, or its
+// metadata and frame index with ?format=json.
+func (h *Handlers) HandleRecordingGet(w http.ResponseWriter, r *http.Request) {
+	rec := h.lookupRecording(w, r)
+	if rec == nil {
+		return
+	}
+	if r.URL.Query().Get("format") == "json" {
+		web.JSON(w, 200, rec)
+		return
+	}
+	w.Header().Set("Content-Type", "video/x-msvideo")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", rec.ID+".avi"))
+	http.ServeFile(w, r, filepath.Join(h.recordingDir(), rec.ID+".avi"))
+}
+
+// HandleRecordingFrame returns frame n of a finished recording as JPEG.
+func (h *Handlers) HandleRecordingFrame(w http.ResponseWriter, r *http.Request) {
+	rec := h.lookupRecording(w, r)
+	if rec == nil {
+		return
+	}
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil || n < 0 || n >= len(rec.Frames) {
+		web.Error(w, 404, fmt.Errorf("frame out of range (0-%d)", len(rec.Frames)-1))
+		return
+	}
+	fr := rec.Frames[n]
+
+	f, err := os.Open(filepath.Join(h.recordingDir(), rec.ID+".avi"))
+	if err != nil {
+		web.Error(w, 500, err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	buf := make([]byte, fr.Size)
+	if _, err := f.ReadAt(buf, fr.Offset); err != nil {
+		web.Error(w, 500, fmt.Errorf("read frame: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	if _, err := w.Write(buf); err != nil {
+		slog.Error("recording frame write", "err", err)
+	}
+}
+
+// HandleRecordingDelete removes a finished recording and its metadata.
+func (h *Handlers) HandleRecordingDelete(w http.ResponseWriter, r *http.Request) {
+	rec := h.lookupRecording(w, r)
+	if rec == nil {
+		return
+	}
+	for _, ext := range []string{".avi", ".json"} {
+		if err := os.Remove(filepath.Join(h.recordingDir(), rec.ID+ext)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			web.Error(w, 500, err)
+			return
+		}
+	}
+	web.JSON(w, 200, map[string]any{"deleted": rec.ID})
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image/jpeg"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+const controlInputTimeout = 5 * time.Second
+
+// controlMessage is an input or control request sent by a screencast viewer.
+// Coordinates are in screencast frame pixels.
+type controlMessage struct {
+	Type       string  `json:"type"`  // mouse, wheel, key, takeover, release
+	Event      string  `json:"event"` // mousePressed/mouseReleased/mouseMoved, keyDown/keyUp/char
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	Button     string  `json:"button"`
+	ClickCount int64   `json:"clickCount"`
+	DeltaX     float64 `json:"deltaX"`
+	DeltaY     float64 `json:"deltaY"`
+	Key        string  `json:"key"`
+	Code       string  `json:"code"`
+	Text       string  `json:"text"`
+	KeyCode    int64   `json:"keyCode"`
+	Modifiers  int64   `json:"modifiers"`
+	Force      bool    `json:"force"` // takeover: break another owner's lock
+}
+
+// frameGeometry tracks the size of the last streamed frame and the viewport
+// it was captured from, so viewer coordinates can be mapped back.
+type frameGeometry struct {
+	mu      sync.Mutex
+	frameW  int
+	frameH  int
+	deviceW float64
+	deviceH float64
+}
+
+func (g *frameGeometry) update(frame []byte, meta *page.ScreencastFrameMetadata) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(frame))
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err == nil {
+		g.frameW, g.frameH = cfg.Width, cfg.Height
+	}
+	if meta != nil {
+		g.deviceW, g.deviceH = meta.DeviceWidth, meta.DeviceHeight
+	}
+}
+
+// toViewport scales a point from frame pixels to CSS pixels in the viewport.
+// Before the first frame it passes coordinates through unchanged.
+func (g *frameGeometry) toViewport(x, y float64) (float64, float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.frameW == 0 || g.frameH == 0 || g.deviceW == 0 || g.deviceH == 0 {
+		return x, y
+	}
+	return x * g.deviceW / float64(g.frameW), y * g.deviceH / float64(g.frameH)
+}
+
+// remoteControl lets a screencast viewer drive a tab. Input is only accepted
+// while the viewer holds the tab lock, so an agent and a human operator
+// cannot act on the same tab at once.
+type remoteControl struct {
+	bridge bridge.BridgeAPI
+	tabID  string
+	owner  string
+	geo    *frameGeometry
+
+	// acquired is set once this connection has taken the lock itself. The
+	// owner name comes from the client, so matching it alone would let any
+	// viewer drop a lock some agent holds under that name.
+	mu       sync.Mutex
+	acquired bool
+	// handoff is the handoff lock the viewer was granted control under. The
+	// lock stays with the handoff, which releases it on resume.
+	handoff string
+}
+
+// holds reports whether this viewer may send input: it took the tab lock and
+// still owns it, or it joined a handoff that is still in progress.
+func (rc *remoteControl) holds() bool {
+	rc.mu.Lock()
+	owner := ""
+	switch {
+	case rc.acquired:
+		owner = rc.owner
+	case rc.handoff != "":
+		owner = rc.handoff
+	}
+	rc.mu.Unlock()
+	if owner == "" {
+		return false
+	}
+	info := rc.bridge.TabLockInfo(rc.tabID)
+	return info != nil && info.Owner == owner
+}
+
+// handle processes one client message and returns the reply to send, if any.
+func (rc *remoteControl) handle(ctx context.Context, raw []byte) map[string]any {
+	var msg controlMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return map[string]any{"type": "error", "error": "invalid control message"}
+	}
+
+	switch msg.Type {
+	case "takeover":
+		// A lock under this viewer's name that it didn't take belongs to
+		// someone else using the same name; renewing it would adopt it.
+		info := rc.bridge.TabLockInfo(rc.tabID)
+		if info != nil && strings.HasPrefix(info.Owner, handoffLockPrefix) {
+			// The tab was handed to a human: that is who is watching.
+			rc.mu.Lock()
+			rc.handoff = info.Owner
+			rc.mu.Unlock()
+			return map[string]any{"type": "control", "granted": true, "owner": rc.owner, "handoff": true}
+		}
+		if info != nil && !rc.holds() {
+			if !msg.Force {
+				return map[string]any{"type": "control", "granted": false, "error": fmt.Sprintf("tab is locked by %s", info.Owner), "lockedBy": info.Owner}
+			}
+			slog.Warn("tab lock broken by operator", "tab", rc.tabID, "owner", info.Owner, "by", rc.owner)
+			_ = rc.bridge.Unlock(rc.tabID, info.Owner)
+		}
+		if err := rc.bridge.Lock(rc.tabID, rc.owner, bridge.DefaultLockTimeout); err != nil {
+			reply := map[string]any{"type": "control", "granted": false, "error": err.Error()}
+			if info := rc.bridge.TabLockInfo(rc.tabID); info != nil {
+				reply["lockedBy"] = info.Owner
+			}
+			return reply
+		}
+		rc.mu.Lock()
+		rc.acquired = true
+		rc.mu.Unlock()
+		return map[string]any{"type": "control", "granted": true, "owner": rc.owner}
+	case "release":
+		rc.release()
+		return map[string]any{"type": "control", "granted": false, "owner": rc.owner}
+	case "mouse", "wheel", "key":
+	default:
+		return map[string]any{"type": "error", "error": fmt.Sprintf("unknown message type %q", msg.Type)}
+	}
+
+	if !rc.holds() {
+		if info := rc.bridge.TabLockInfo(rc.tabID); info != nil {
+			return map[string]any{"type": "error", "error": fmt.Sprintf("tab is locked by %s", info.Owner)}
+		}
+		return map[string]any{"type": "error", "error": "take control before sending input"}
+	}
+	// Keep the lock alive for as long as the operator is active.
+	rc.mu.Lock()
+	acquired := rc.acquired
+	rc.mu.Unlock()
+	if acquired {
+		_ = rc.bridge.Lock(rc.tabID, rc.owner, bridge.DefaultLockTimeout)
+	}
+
+	tCtx, cancel := context.WithTimeout(ctx, controlInputTimeout)
+	defer cancel()
+	// Each message takes its turn in the tab queue like an HTTP action, so
+	// operator input never interleaves with a queued agent request.
+	release, err := rc.bridge.AcquireTab(tCtx, rc.tabID)
+	if err != nil {
+		return map[string]any{"type": "error", "error": fmt.Sprintf("waiting for tab: %v", err)}
+	}
+	defer release()
+	if err := chromedp.Run(tCtx, rc.action(&msg)); err != nil {
+		return map[string]any{"type": "error", "error": err.Error()}
+	}
+	return nil
+}
+
+func (rc *remoteControl) action(msg *controlMessage) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		switch msg.Type {
+		case "mouse":
+			x, y := rc.geo.toViewport(msg.X, msg.Y)
+			typ := input.MouseType(msg.Event)
+			switch typ {
+			case input.MousePressed, input.MouseReleased, input.MouseMoved:
+			default:
+				return fmt.Errorf("unknown mouse event %q", msg.Event)
+			}
+			button := input.MouseButton(msg.Button)
+			if button == "" {
+				button = input.None
+				if typ != input.MouseMoved {
+					button = input.Left
+				}
+			}
+			p := input.DispatchMouseEvent(typ, x, y).
+				WithButton(button).
+				WithModifiers(input.Modifier(msg.Modifiers))
+			if typ != input.MouseMoved {
+				p = p.WithClickCount(max(msg.ClickCount, 1))
+			}
+			return p.Do(ctx)
+		case "wheel":
+			x, y := rc.geo.toViewport(msg.X, msg.Y)
+			return input.DispatchMouseEvent(input.MouseWheel, x, y).
+				WithDeltaX(msg.DeltaX).
+				WithDeltaY(msg.DeltaY).
+				WithModifiers(input.Modifier(msg.Modifiers)).
+				Do(ctx)
+		default:
+			typ := input.KeyType(msg.Event)
+			switch typ {
+			case input.KeyDown:
+				// Without text Chrome would insert nothing anyway; rawKeyDown
+				// keeps non-printable keys from generating a char event.
+				if msg.Text == "" {
+					typ = input.KeyRawDown
+				}
+			case input.KeyUp, input.KeyChar:
+			default:
+				return fmt.Errorf("unknown key event %q", msg.Event)
+			}
+			return input.DispatchKeyEvent(typ).
+				WithKey(msg.Key).
+				WithCode(msg.Code).
+				WithText(msg.Text).
+				WithUnmodifiedText(msg.Text).
+				WithWindowsVirtualKeyCode(msg.KeyCode).
+				WithNativeVirtualKeyCode(msg.KeyCode).
+				WithModifiers(input.Modifier(msg.Modifiers)).
+				Do(ctx)
+		}
+	}
+}
+
+// release gives the tab lock back if this viewer took it and still holds it.
+func (rc *remoteControl) release() {
+	rc.mu.Lock()
+	acquired := rc.acquired
+	rc.handoff = ""
+	rc.mu.Unlock()
+	if acquired && rc.holds() {
+		_ = rc.bridge.Unlock(rc.tabID, rc.owner)
+	}
+	rc.mu.Lock()
+	rc.acquired = false
+	rc.mu.Unlock()
+}
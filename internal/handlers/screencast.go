@@ -1,33 +1,23 @@
 package handlers
 
 import (
-	"context"
-	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"sync"
 	"time"
 
-	"github.com/chromedp/cdproto/page"
-	"github.com/chromedp/chromedp"
 	"github.com/gobwas/ws"
 	"github.com/gobwas/ws/wsutil"
 	"github.com/pinchtab/pinchtab/internal/web"
 )
 
 // HandleScreencast upgrades to WebSocket and streams screencast frames for a tab.
-// Query params: tabId (required), quality (1-100, default 40), maxWidth (default 800), fps (1-30, default 5)
+// Query params: tabId (required), quality (1-100, default 40), maxWidth (default 800), fps (1-30, default 5),
+// control=true to accept input messages from the viewer, owner (lock owner name, default "operator").
 func (h *Handlers) HandleScreencast(w http.ResponseWriter, r *http.Request) {
-	tabID := r.URL.Query().Get("tabId")
-	if tabID == "" {
-		targets, err := h.Bridge.ListTargets()
-		if err == nil && len(targets) > 0 {
-			tabID = string(targets[0].TargetID)
-		}
-	}
-
-	ctx, _, err := h.Bridge.TabContext(tabID)
+	ctx, resolvedTabID, err := h.tabContext(r, r.URL.Query().Get("tabId"))
 	if err != nil {
 		http.Error(w, "tab not found", 404)
 		return
@@ -42,6 +32,16 @@ func (h *Handlers) HandleScreencast(w http.ResponseWriter, r *http.Request) {
 	}
 	minFrameInterval := time.Second / time.Duration(fps)
 
+	geo := &frameGeometry{}
+	var rc *remoteControl
+	if r.URL.Query().Get("control") == "true" {
+		owner := r.URL.Query().Get("owner")
+		if owner == "" {
+			owner = "operator"
+		}
+		rc = &remoteControl{bridge: h.Bridge, tabID: resolvedTabID, owner: owner, geo: geo}
+	}
+
 	conn, _, _, err := ws.UpgradeHTTP(r, w)
 	if err != nil {
 		slog.Error("ws upgrade failed", "err", err)
@@ -53,88 +53,68 @@ func (h *Handlers) HandleScreencast(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	frameCh := make(chan []byte, 3)
-	var once sync.Once
-	done := make(chan struct{})
-
-	// Listen for screencast frames with rate limiting
-	var lastFrame time.Time
-	chromedp.ListenTarget(ctx, func(ev interface{}) {
-		switch e := ev.(type) {
-		case *page.EventScreencastFrame:
-			go func() {
-				_ = chromedp.Run(ctx,
-					chromedp.ActionFunc(func(c context.Context) error {
-						return page.ScreencastFrameAck(e.SessionID).Do(c)
-					}),
-				)
-			}()
-
-			now := time.Now()
-			if now.Sub(lastFrame) < minFrameInterval {
-				return
-			}
-			lastFrame = now
-
-			data, err := base64.StdEncoding.DecodeString(e.Data)
-			if err != nil {
-				return
-			}
-
-			select {
-			case frameCh <- data:
-			default:
-			}
-		}
+	sub, err := h.screencasts.subscribe(ctx, resolvedTabID, screencastOptions{
+		Quality:     quality,
+		MaxWidth:    maxWidth,
+		EveryNth:    everyNth,
+		MinInterval: minFrameInterval,
 	})
-
-	err = chromedp.Run(ctx,
-		chromedp.ActionFunc(func(c context.Context) error {
-			return page.StartScreencast().
-				WithFormat(page.ScreencastFormatJpeg).
-				WithQuality(int64(quality)).
-				WithMaxWidth(int64(maxWidth)).
-				WithMaxHeight(int64(maxWidth * 3 / 4)).
-				WithEveryNthFrame(int64(everyNth)).
-				Do(c)
-		}),
-	)
 	if err != nil {
-		slog.Error("start screencast failed", "err", err, "tab", tabID)
+		slog.Error("start screencast failed", "err", err, "tab", resolvedTabID)
 		return
 	}
+	defer h.screencasts.unsubscribe(sub)
 
-	defer func() {
-		once.Do(func() { close(done) })
-		_ = chromedp.Run(ctx,
-			chromedp.ActionFunc(func(c context.Context) error {
-				return page.StopScreencast().Do(c)
-			}),
-		)
-	}()
+	var once sync.Once
+	done := make(chan struct{})
+	defer once.Do(func() { close(done) })
+	var writeMu sync.Mutex
+	write := func(op ws.OpCode, payload []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return wsutil.WriteServerMessage(conn, op, payload)
+	}
+
+	slog.Info("screencast started", "tab", resolvedTabID, "quality", quality, "maxWidth", maxWidth, "control", rc != nil,
+		"viewers", h.screencasts.viewers(resolvedTabID))
 
-	slog.Info("screencast started", "tab", tabID, "quality", quality, "maxWidth", maxWidth)
+	if rc != nil {
+		defer rc.release()
+	}
 
 	go func() {
 		for {
-			_, _, err := wsutil.ReadClientData(conn)
+			msg, op, err := wsutil.ReadClientData(conn)
 			if err != nil {
 				once.Do(func() { close(done) })
 				return
 			}
+			if rc == nil || op != ws.OpText {
+				continue
+			}
+			if reply := rc.handle(ctx, msg); reply != nil {
+				data, _ := json.Marshal(reply)
+				if err := write(ws.OpText, data); err != nil {
+					once.Do(func() { close(done) })
+					return
+				}
+			}
 		}
 	}()
 
 	for {
 		select {
-		case frame := <-frameCh:
-			if err := wsutil.WriteServerBinary(conn, frame); err != nil {
+		case frame := <-sub.Frames:
+			geo.update(frame.Data, frame.Meta)
+			if err := write(ws.OpBinary, frame.Data); err != nil {
 				return
 			}
+		case <-sub.Done:
+			return
 		case <-done:
 			return
 		case <-time.After(10 * time.Second):
-			if err := wsutil.WriteServerMessage(conn, ws.OpPing, nil); err != nil {
+			if err := write(ws.OpPing, nil); err != nil {
 				return
 			}
 		}
@@ -157,6 +137,9 @@ func (h *Handlers) HandleScreencastAll(w http.ResponseWriter, r *http.Request) {
 
 	tabs := make([]tabInfo, 0)
 	for _, t := range targets {
+		if !h.canSeeTab(r, string(t.TargetID)) {
+			continue
+		}
 		tabs = append(tabs, tabInfo{
 			ID:    string(t.TargetID),
 			URL:   t.URL,
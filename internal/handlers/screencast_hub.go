@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// screencastOptions are what a single subscriber asks for. The hub runs one
+// CDP screencast per tab with the most demanding options of its subscribers.
+type screencastOptions struct {
+	Quality     int
+	MaxWidth    int
+	EveryNth    int
+	MinInterval time.Duration // per-subscriber rate limit; 0 means every frame
+}
+
+type screencastFrame struct {
+	Data []byte
+	Meta *page.ScreencastFrameMetadata
+	At   time.Time
+}
+
+type screencastSub struct {
+	Frames chan screencastFrame
+	// Done is closed when the subscription ends, either by unsubscribe or
+	// because the tab went away.
+	Done chan struct{}
+
+	opts   screencastOptions
+	last   time.Time
+	stream *tabStream
+	once   sync.Once
+}
+
+func (s *screencastSub) close() {
+	s.once.Do(func() { close(s.Done) })
+}
+
+type tabStream struct {
+	tabID  string
+	ctx    context.Context
+	cancel context.CancelFunc          // removes the frame listener
+	subs   map[*screencastSub]struct{} // guarded by hub.mu
+
+	// cdpMu serialises start/stop calls for this tab. It is never held
+	// together with hub.mu across a CDP round trip: chromedp delivers events
+	// and command responses on the same goroutine, so a listener blocked on
+	// hub.mu would otherwise deadlock the call.
+	cdpMu  sync.Mutex
+	params screencastOptions // options the running screencast uses; zero when stopped
+}
+
+// screencastHub multiplexes Page.startScreencast: one CDP screencast per tab,
+// fanned out to any number of subscribers, stopped when the last one leaves.
+type screencastHub struct {
+	mu      sync.Mutex
+	streams map[string]*tabStream
+
+	// start and stop talk to the browser; tests replace them.
+	start func(ctx context.Context, opts screencastOptions) error
+	stop  func(ctx context.Context) error
+}
+
+func newScreencastHub() *screencastHub {
+	return &screencastHub{
+		streams: make(map[string]*tabStream),
+		start:   startCDPScreencast,
+		stop:    stopCDPScreencast,
+	}
+}
+
+func startCDPScreencast(ctx context.Context, opts screencastOptions) error {
+	return chromedp.Run(ctx,
+		chromedp.ActionFunc(func(c context.Context) error {
+			return page.StartScreencast().
+				WithFormat(page.ScreencastFormatJpeg).
+				WithQuality(int64(opts.Quality)).
+				WithMaxWidth(int64(opts.MaxWidth)).
+				WithMaxHeight(int64(opts.MaxWidth * 3 / 4)).
+				WithEveryNthFrame(int64(opts.EveryNth)).
+				Do(c)
+		}),
+	)
+}
+
+func stopCDPScreencast(ctx context.Context) error {
+	sctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return chromedp.Run(sctx,
+		chromedp.ActionFunc(func(c context.Context) error {
+			return page.StopScreencast().Do(c)
+		}),
+	)
+}
+
+// combined returns the options that satisfy every subscriber. Callers hold hub.mu.
+func (st *tabStream) combined() screencastOptions {
+	var out screencastOptions
+	for sub := range st.subs {
+		out.Quality = max(out.Quality, sub.opts.Quality)
+		out.MaxWidth = max(out.MaxWidth, sub.opts.MaxWidth)
+		if out.EveryNth == 0 || sub.opts.EveryNth < out.EveryNth {
+			out.EveryNth = sub.opts.EveryNth
+		}
+	}
+	return out
+}
+
+// subscribe joins (or starts) the screencast of a tab.
+func (hub *screencastHub) subscribe(ctx context.Context, tabID string, opts screencastOptions) (*screencastSub, error) {
+	if opts.EveryNth <= 0 {
+		opts.EveryNth = 1
+	}
+	sub := &screencastSub{
+		Frames: make(chan screencastFrame, 3),
+		Done:   make(chan struct{}),
+		opts:   opts,
+	}
+
+	hub.mu.Lock()
+	st := hub.streams[tabID]
+	if st != nil && st.ctx != ctx {
+		// The tab was re-created under the same ID; the old stream is dead.
+		hub.endStreamLocked(st)
+		st = nil
+	}
+	if st == nil {
+		st = &tabStream{tabID: tabID, ctx: ctx, subs: make(map[*screencastSub]struct{})}
+		hub.streams[tabID] = st
+		hub.listen(st)
+	}
+	st.subs[sub] = struct{}{}
+	sub.stream = st
+	hub.mu.Unlock()
+
+	if err := hub.sync(st); err != nil {
+		hub.unsubscribe(sub)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// subscribers counts clients across all streams.
+func (hub *screencastHub) subscribers() int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	n := 0
+	for _, st := range hub.streams {
+		n += len(st.subs)
+	}
+	return n
+}
+
+// unsubscribe leaves a stream; the last subscriber out stops the screencast.
+func (hub *screencastHub) unsubscribe(sub *screencastSub) {
+	hub.mu.Lock()
+	sub.close()
+	st := sub.stream
+	_, ok := st.subs[sub]
+	delete(st.subs, sub)
+	hub.mu.Unlock()
+
+	if ok {
+		_ = hub.sync(st)
+	}
+}
+
+// sync brings the CDP screencast of a stream in line with its subscribers:
+// started or restarted with the combined options, or stopped when empty.
+func (hub *screencastHub) sync(st *tabStream) error {
+	st.cdpMu.Lock()
+	defer st.cdpMu.Unlock()
+
+	hub.mu.Lock()
+	want := st.combined()
+	empty := len(st.subs) == 0
+	hub.mu.Unlock()
+
+	if empty {
+		if st.params != (screencastOptions{}) && st.ctx.Err() == nil {
+			_ = hub.stop(st.ctx)
+		}
+		st.params = screencastOptions{}
+		hub.mu.Lock()
+		if len(st.subs) == 0 && hub.streams[st.tabID] == st {
+			delete(hub.streams, st.tabID)
+			st.cancel()
+		}
+		hub.mu.Unlock()
+		return nil
+	}
+
+	if want == st.params {
+		return nil
+	}
+	if err := hub.start(st.ctx, want); err != nil {
+		return err
+	}
+	st.params = want
+	return nil
+}
+
+func (hub *screencastHub) endStreamLocked(st *tabStream) {
+	for sub := range st.subs {
+		sub.close()
+	}
+	st.subs = map[*screencastSub]struct{}{}
+	if hub.streams[st.tabID] == st {
+		delete(hub.streams, st.tabID)
+	}
+	st.cancel()
+}
+
+// listen installs the CDP frame listener for a stream. chromedp drops the
+// listener once its context is cancelled, which happens when the stream ends.
+func (hub *screencastHub) listen(st *tabStream) {
+	lctx, cancel := context.WithCancel(st.ctx)
+	st.cancel = cancel
+
+	chromedp.ListenTarget(lctx, func(ev interface{}) {
+		e, ok := ev.(*page.EventScreencastFrame)
+		if !ok {
+			return
+		}
+		go func() {
+			_ = chromedp.Run(st.ctx,
+				chromedp.ActionFunc(func(c context.Context) error {
+					return page.ScreencastFrameAck(e.SessionID).Do(c)
+				}),
+			)
+		}()
+		data, err := base64.StdEncoding.DecodeString(e.Data)
+		if err != nil {
+			return
+		}
+		hub.dispatch(st, screencastFrame{Data: data, Meta: e.Metadata, At: time.Now()})
+	})
+
+	// A closed tab ends the stream for everyone watching it.
+	go func() {
+		<-lctx.Done()
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		hub.endStreamLocked(st)
+	}()
+}
+
+// dispatch fans a frame out to every subscriber whose rate limit allows it.
+// Slow subscribers drop frames rather than stall the others.
+func (hub *screencastHub) dispatch(st *tabStream, f screencastFrame) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	for sub := range st.subs {
+		if sub.opts.MinInterval > 0 && f.At.Sub(sub.last) < sub.opts.MinInterval {
+			continue
+		}
+		select {
+		case sub.Frames <- f:
+			sub.last = f.At
+		default:
+		}
+	}
+}
+
+// viewers reports how many subscribers a tab's screencast has.
+func (hub *screencastHub) viewers(tabID string) int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if st := hub.streams[tabID]; st != nil {
+		return len(st.subs)
+	}
+	return 0
+}
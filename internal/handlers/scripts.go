@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/web"
+)
+
+// HandleScriptAdd registers a named init script that runs in every tab before
+// page scripts. Re-registering a name replaces the script.
+//
+// POST /scripts {"name":"cookie-banner","source":"...","match":["https://*.example.com/*"]}
+func (h *Handlers) HandleScriptAdd(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name   string   `json:"name"`
+		Source string   `json:"source"`
+		Match  []string `json:"match"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		web.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.Name == "" || req.Source == "" {
+		web.Error(w, 400, fmt.Errorf("name and source required"))
+		return
+	}
+
+	s, err := h.Bridge.AddUserScript(bridge.UserScript{Name: req.Name, Source: req.Source, Match: req.Match})
+	if err != nil {
+		if errors.Is(err, bridge.ErrInvalidScript) {
+			web.Error(w, 400, err)
+			return
+		}
+		web.Error(w, 500, err)
+		return
+	}
+	web.JSON(w, 200, s)
+}
+
+// HandleScriptList returns the registered init scripts.
+func (h *Handlers) HandleScriptList(w http.ResponseWriter, r *http.Request) {
+	web.JSON(w, 200, map[string]any{"scripts": h.Bridge.UserScripts()})
+}
+
+// HandleScriptDelete unregisters an init script.
+func (h *Handlers) HandleScriptDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := h.Bridge.RemoveUserScript(name); err != nil {
+		if errors.Is(err, bridge.ErrScriptNotFound) {
+			web.Error(w, 404, fmt.Errorf("script %s not found", name))
+			return
+		}
+		web.Error(w, 500, err)
+		return
+	}
+	web.JSON(w, 200, map[string]any{"removed": name})
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/web"
+)
+
+// sessionError maps session store errors to status codes.
+func sessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, bridge.ErrInvalidSessionName):
+		web.Error(w, 400, err)
+	case errors.Is(err, bridge.ErrSessionNotFound):
+		web.Error(w, 404, err)
+	default:
+		web.Error(w, 500, err)
+	}
+}
+
+// sessionVisible reports whether the caller may use a session saved by
+// owner. In strict mode agents only see their own sessions and unowned ones
+// such as the autosave.
+func (h *Handlers) sessionVisible(r *http.Request, owner string) bool {
+	return !h.strictOwnership() || owner == "" || owner == callerID(r, "")
+}
+
+// loadSession loads a session the caller may see; another agent's session
+// is reported as not found.
+func (h *Handlers) loadSession(r *http.Request, name string) (bridge.SessionState, error) {
+	state, err := h.Bridge.LoadSession(name)
+	if err == nil && !h.sessionVisible(r, state.Owner) {
+		return bridge.SessionState{}, fmt.Errorf("%w: %s", bridge.ErrSessionNotFound, name)
+	}
+	return state, err
+}
+
+// sessionTab is a saved tab with its web storage reduced to key names.
+type sessionTab struct {
+	bridge.TabState
+	LocalStorage   []string `json:"localStorage,omitempty"`
+	SessionStorage []string `json:"sessionStorage,omitempty"`
+}
+
+// HandleSessionSave checkpoints the caller's tabs under a name: URLs, titles
+// and scroll positions, plus cookies and web storage when asked. The body is
+// optional; tabIds limits the save to some tabs. The session records the
+// caller, and in strict mode no other agent can see or overwrite it, and its
+// cookies are only those of the saved tabs, not other agents' sites.
+//
+// POST /sessions/{name} {"tabIds":["..."],"cookies":true,"storage":true}
+func (h *Handlers) HandleSessionSave(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TabIDs []string `json:"tabIds"`
+		bridge.SessionOptions
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		web.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+
+	name := r.PathValue("name")
+	if h.strictOwnership() {
+		if prev, err := h.Bridge.LoadSession(name); err == nil && !h.sessionVisible(r, prev.Owner) {
+			web.Error(w, 409, fmt.Errorf("session %s belongs to another agent", name))
+			return
+		}
+	}
+	req.Owner = callerID(r, "")
+	req.TabCookies = h.strictOwnership()
+
+	var tabIDs []string
+	if len(req.TabIDs) > 0 {
+		for _, id := range req.TabIDs {
+			id = h.Bridge.MapTabID(id)
+			if !h.canSeeTab(r, id) {
+				web.Error(w, 404, fmt.Errorf("tab %s not found", id))
+				return
+			}
+			tabIDs = append(tabIDs, id)
+		}
+	} else if h.strictOwnership() {
+		targets, err := h.Bridge.ListTargets()
+		if err != nil {
+			web.Error(w, 500, err)
+			return
+		}
+		tabIDs = []string{}
+		for _, t := range targets {
+			if id := string(t.TargetID); h.canSeeTab(r, id) {
+				tabIDs = append(tabIDs, id)
+			}
+		}
+	}
+
+	state, err := h.Bridge.SaveSession(name, tabIDs, req.SessionOptions)
+	if err != nil {
+		sessionError(w, err)
+		return
+	}
+	web.JSON(w, 200, bridge.SessionInfo{
+		Name:    state.Name,
+		Owner:   state.Owner,
+		Tabs:    len(state.Tabs),
+		Cookies: len(state.Cookies),
+		SavedAt: state.SavedAt,
+	})
+}
+
+// HandleSessionRestore reopens a saved session in new tabs owned by the
+// caller. The first restored tab becomes the caller's current tab.
+//
+// POST /sessions/{name}/restore
+func (h *Handlers) HandleSessionRestore(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if h.strictOwnership() {
+		if _, err := h.loadSession(r, name); err != nil {
+			sessionError(w, err)
+			return
+		}
+	}
+	tabs, err := h.Bridge.RestoreSession(name)
+	for _, t := range tabs {
+		h.claimTab(r, t.TabID, "", false)
+	}
+	if len(tabs) > 0 {
+		h.makeCurrent(r, tabs[0].TabID)
+	}
+	if err != nil && len(tabs) == 0 {
+		sessionError(w, err)
+		return
+	}
+
+	resp := map[string]any{"name": name, "tabs": tabs, "restored": len(tabs)}
+	if err != nil {
+		resp["error"] = err.Error()
+	}
+	web.JSON(w, 200, resp)
+}
+
+// HandleSessionList returns the saved sessions the caller may see.
+//
+// GET /sessions
+func (h *Handlers) HandleSessionList(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.Bridge.ListSessions()
+	if err != nil {
+		web.Error(w, 500, err)
+		return
+	}
+	sessions = slices.DeleteFunc(sessions, func(s bridge.SessionInfo) bool { return !h.sessionVisible(r, s.Owner) })
+	web.JSON(w, 200, map[string]any{"sessions": sessions})
+}
+
+// HandleSessionGet returns a saved session's tabs. Cookies are only counted
+// and web storage only lists its keys: the values often hold credentials and
+// are only needed to restore the session.
+//
+// GET /sessions/{name}
+func (h *Handlers) HandleSessionGet(w http.ResponseWriter, r *http.Request) {
+	state, err := h.loadSession(r, r.PathValue("name"))
+	if err != nil {
+		sessionError(w, err)
+		return
+	}
+	tabs := make([]sessionTab, len(state.Tabs))
+	for i, t := range state.Tabs {
+		tabs[i] = sessionTab{
+			TabState:       t,
+			LocalStorage:   slices.Sorted(maps.Keys(t.LocalStorage)),
+			SessionStorage: slices.Sorted(maps.Keys(t.SessionStorage)),
+		}
+	}
+	web.JSON(w, 200, map[string]any{
+		"name":    state.Name,
+		"owner":   state.Owner,
+		"tabs":    tabs,
+		"cookies": len(state.Cookies),
+		"savedAt": state.SavedAt,
+	})
+}
+
+// HandleSessionDelete removes a saved session.
+//
+// DELETE /sessions/{name}
+func (h *Handlers) HandleSessionDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if h.strictOwnership() {
+		if _, err := h.loadSession(r, name); err != nil {
+			sessionError(w, err)
+			return
+		}
+	}
+	if err := h.Bridge.DeleteSession(name); err != nil {
+		sessionError(w, err)
+		return
+	}
+	web.JSON(w, 200, map[string]any{"removed": name})
+}
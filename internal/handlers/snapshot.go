@@ -26,6 +26,9 @@ func (h *Handlers) HandleSnapshot(w http.ResponseWriter, r *http.Request) {
 	selector := r.URL.Query().Get("selector")
 	maxTokensStr := r.URL.Query().Get("maxTokens")
 	reqNoAnim := r.URL.Query().Get("noAnimations") == "true"
+	// A read-only snapshot skips the tab queue and leaves the ref cache alone,
+	// so it never waits behind (or disturbs) an agent acting on the tab.
+	readOnly := r.URL.Query().Get("readOnly") == "true"
 	maxDepthStr := r.URL.Query().Get("depth")
 	maxDepth := -1
 	if maxDepthStr != "" {
@@ -40,11 +43,18 @@ func (h *Handlers) HandleSnapshot(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	ctx, resolvedTabID, err := h.Bridge.TabContext(tabID)
+	ctx, resolvedTabID, err := h.tabContext(r, tabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
 	}
+	if !readOnly {
+		release, ok := h.queueTab(w, r, resolvedTabID)
+		if !ok {
+			return
+		}
+		defer release()
+	}
 
 	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
 	defer tCancel()
@@ -54,22 +64,9 @@ func (h *Handlers) HandleSnapshot(w http.ResponseWriter, r *http.Request) {
 		bridge.DisableAnimationsOnce(tCtx)
 	}
 
-	var rawResult json.RawMessage
-	if err := chromedp.Run(tCtx,
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			return chromedp.FromContext(ctx).Target.Execute(ctx,
-				"Accessibility.getFullAXTree", nil, &rawResult)
-		}),
-	); err != nil {
-		web.Error(w, 500, fmt.Errorf("a11y tree: %w", err))
-		return
-	}
-
-	var treeResp struct {
-		Nodes []bridge.RawAXNode `json:"nodes"`
-	}
-	if err := json.Unmarshal(rawResult, &treeResp); err != nil {
-		web.Error(w, 500, fmt.Errorf("parse a11y tree: %w", err))
+	nodes, err := fetchAXTree(tCtx)
+	if err != nil {
+		tabFailed(w, tCtx, 500, err)
 		return
 	}
 
@@ -124,10 +121,10 @@ func (h *Handlers) HandleSnapshot(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		treeResp.Nodes = bridge.FilterSubtree(treeResp.Nodes, scopeNodeID)
+		nodes = bridge.FilterSubtree(nodes, scopeNodeID)
 	}
 
-	flat, refs := bridge.BuildSnapshot(treeResp.Nodes, filter, maxDepth)
+	flat, refs := bridge.BuildSnapshot(nodes, filter, maxDepth)
 
 	truncated := false
 	if maxTokens > 0 {
@@ -141,7 +138,9 @@ func (h *Handlers) HandleSnapshot(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	h.Bridge.SetRefCache(resolvedTabID, &bridge.RefCache{Refs: refs, Nodes: flat})
+	if !readOnly {
+		h.Bridge.SetRefCache(resolvedTabID, &bridge.RefCache{Refs: refs, Nodes: flat})
+	}
 
 	var url, title string
 	_ = chromedp.Run(tCtx,
@@ -315,3 +314,24 @@ func (h *Handlers) HandleSnapshot(w http.ResponseWriter, r *http.Request) {
 		web.JSON(w, 200, resp)
 	}
 }
+
+// fetchAXTree returns the raw accessibility tree of the tab behind ctx.
+func fetchAXTree(ctx context.Context) ([]bridge.RawAXNode, error) {
+	var rawResult json.RawMessage
+	if err := chromedp.Run(ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return chromedp.FromContext(ctx).Target.Execute(ctx,
+				"Accessibility.getFullAXTree", nil, &rawResult)
+		}),
+	); err != nil {
+		return nil, fmt.Errorf("a11y tree: %w", err)
+	}
+
+	var treeResp struct {
+		Nodes []bridge.RawAXNode `json:"nodes"`
+	}
+	if err := json.Unmarshal(rawResult, &treeResp); err != nil {
+		return nil, fmt.Errorf("parse a11y tree: %w", err)
+	}
+	return treeResp.Nodes, nil
+}
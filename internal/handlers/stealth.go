@@ -16,7 +16,7 @@ import (
 )
 
 func (h *Handlers) HandleStealthStatus(w http.ResponseWriter, r *http.Request) {
-	ctx, _, err := h.Bridge.TabContext("")
+	ctx, _, err := h.tabContext(r, "")
 	if err != nil {
 		h.sendStealthResponse(w, h.staticStealthFeatures(), "")
 		return
@@ -193,11 +193,19 @@ func (h *Handlers) HandleFingerprintRotate(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	ctx, _, err := h.Bridge.TabContext(req.TabID)
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
 	}
+	if !h.checkTabLock(w, r, resolvedTabID, "") {
+		return
+	}
+	release, ok := h.queueTab(w, r, resolvedTabID)
+	if !ok {
+		return
+	}
+	defer release()
 
 	fp := h.generateFingerprint(req)
 
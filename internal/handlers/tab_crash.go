@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/web"
+)
+
+// codeTabCrashed tells clients the request died with the tab's renderer,
+// not because of anything in the request itself.
+const codeTabCrashed = "tab_crashed"
+
+// tabCrashErr returns the crash that cancelled ctx, or nil.
+func tabCrashErr(ctx context.Context) error {
+	if cause := context.Cause(ctx); errors.Is(cause, bridge.ErrTabCrashed) {
+		return cause
+	}
+	return nil
+}
+
+// tabFailed writes the error for a request that failed inside a tab. If the
+// tab crashed or hung while the request ran, the caller gets a 503 with code
+// "tab_crashed" instead of the generic error.
+func tabFailed(w http.ResponseWriter, ctx context.Context, code int, err error) {
+	if crashErr := tabCrashErr(ctx); crashErr != nil {
+		web.JSON(w, 503, map[string]string{"error": crashErr.Error(), "code": codeTabCrashed})
+		return
+	}
+	web.Error(w, code, err)
+}
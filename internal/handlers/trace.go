@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/web"
+)
+
+// traceSession is a trace in progress on one tab. Chrome holds the events
+// until the trace is stopped.
+type traceSession struct {
+	TabID      string    `json:"tabId"`
+	URL        string    `json:"url,omitempty"`
+	Categories []string  `json:"categories"`
+	StartedAt  time.Time `json:"startedAt"`
+}
+
+type traceManager struct {
+	mu     sync.Mutex
+	active map[string]*traceSession // by tab ID
+}
+
+func newTraceManager() *traceManager {
+	return &traceManager{active: make(map[string]*traceSession)}
+}
+
+// take removes and returns the trace running on tabID, if any.
+func (m *traceManager) take(tabID string) *traceSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.active[tabID]
+	delete(m.active, tabID)
+	return s
+}
+
+// tabClosed reports whether tabID is no longer open in the browser.
+func (h *Handlers) tabClosed(tabID string) bool {
+	targets, err := h.Bridge.ListTargets()
+	if err != nil {
+		return false
+	}
+	return !slices.ContainsFunc(targets, func(t *target.Info) bool { return string(t.TargetID) == tabID })
+}
+
+// HandleTraceStart starts a Chrome performance trace on a tab. Categories
+// default to what the DevTools Performance panel records; a leading '-'
+// excludes a category.
+//
+// POST /trace/start {"tabId":"...","categories":["devtools.timeline","-*"],"screenshots":true}
+func (h *Handlers) HandleTraceStart(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TabID       string   `json:"tabId"`
+		Categories  []string `json:"categories"`
+		Screenshots bool     `json:"screenshots"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		web.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		web.Error(w, 404, err)
+		return
+	}
+	release, ok := h.queueTab(w, r, resolvedTabID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	categories := req.Categories
+	if len(categories) == 0 {
+		categories = slices.Clone(bridge.DefaultTraceCategories)
+	}
+	if req.Screenshots && !slices.Contains(categories, bridge.TraceScreenshotCategory) {
+		categories = append(categories, bridge.TraceScreenshotCategory)
+	}
+	session := &traceSession{
+		TabID:      resolvedTabID,
+		Categories: categories,
+		StartedAt:  time.Now().UTC(),
+	}
+
+	h.traces.mu.Lock()
+	if _, ok := h.traces.active[resolvedTabID]; ok {
+		h.traces.mu.Unlock()
+		web.Error(w, 409, fmt.Errorf("tab is already being traced"))
+		return
+	}
+	h.traces.active[resolvedTabID] = session
+	h.traces.mu.Unlock()
+
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
+	defer tCancel()
+	go web.CancelOnClientDone(r.Context(), tCancel)
+
+	if err := bridge.StartTrace(tCtx, categories); err != nil {
+		h.traces.take(resolvedTabID)
+		tabFailed(w, tCtx, 500, fmt.Errorf("start trace: %w", err))
+		return
+	}
+	_ = chromedp.Run(tCtx, chromedp.Location(&session.URL))
+
+	web.JSON(w, 200, session)
+}
+
+// HandleTraceStop ends the trace on a tab and streams it to a JSON file that
+// loads in the DevTools Performance panel or chrome://tracing. The file goes
+// under StateDir/traces, named after the tab and time, unless output=file
+// names a path; raw=true returns the file itself instead of its location.
+//
+// POST /trace/stop {"tabId":"...","output":"file","path":"traces/login.json","raw":false}
+func (h *Handlers) HandleTraceStop(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TabID  string `json:"tabId"`
+		Output string `json:"output"`
+		Path   string `json:"path"`
+		Raw    bool   `json:"raw"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		web.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+
+	ctx, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		// If the tab is gone, so is the trace. A tab that is only hidden
+		// from this caller keeps its trace.
+		if id := h.Bridge.MapTabID(req.TabID); id != "" && h.tabClosed(id) {
+			h.traces.take(id)
+		}
+		web.Error(w, 404, err)
+		return
+	}
+	release, ok := h.queueTab(w, r, resolvedTabID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	savePath := ""
+	if req.Output == "file" && req.Path != "" {
+		safe, err := web.SafePath(h.Config.StateDir, req.Path)
+		if err != nil {
+			web.Error(w, 400, fmt.Errorf("invalid path: %w", err))
+			return
+		}
+		savePath = safe
+	}
+
+	session := h.traces.take(resolvedTabID)
+	if session == nil {
+		web.Error(w, 404, fmt.Errorf("no active trace"))
+		return
+	}
+	stoppedAt := time.Now().UTC()
+
+	traceDir := filepath.Join(h.Config.StateDir, "traces")
+	if savePath != "" {
+		traceDir = filepath.Dir(savePath)
+	}
+	if err := os.MkdirAll(traceDir, 0750); err != nil {
+		web.Error(w, 500, fmt.Errorf("create trace dir: %w", err))
+		return
+	}
+	var f *os.File
+	if savePath == "" {
+		// Several tabs may stop in the same second; CreateTemp keeps their
+		// files apart.
+		f, err = os.CreateTemp(traceDir, fmt.Sprintf("trace-%s-%s-*.json", resolvedTabID, stoppedAt.Format("20060102-150405")))
+	} else {
+		f, err = os.OpenFile(savePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	}
+	if err != nil {
+		web.Error(w, 500, fmt.Errorf("create trace: %w", err))
+		return
+	}
+	savePath = f.Name()
+
+	// A long trace can take a while to flush and read back.
+	tCtx, tCancel := context.WithTimeout(ctx, h.Config.NavigateTimeout)
+	defer tCancel()
+	go web.CancelOnClientDone(r.Context(), tCancel)
+
+	result, err := bridge.StopTrace(tCtx, f)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("write trace: %w", cerr)
+	}
+	if err != nil {
+		_ = os.Remove(savePath)
+		tabFailed(w, tCtx, 500, fmt.Errorf("stop trace: %w", err))
+		return
+	}
+	if result.DataLoss {
+		slog.Warn("trace buffer overflowed", "tab", resolvedTabID, "path", savePath)
+	}
+
+	if req.Raw {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Trace-Path", savePath)
+		http.ServeFile(w, r, savePath)
+		return
+	}
+
+	web.JSON(w, 200, map[string]any{
+		"path":       savePath,
+		"size":       result.Size,
+		"tabId":      resolvedTabID,
+		"url":        session.URL,
+		"categories": session.Categories,
+		"durationMs": stoppedAt.Sub(session.StartedAt).Milliseconds(),
+		"dataLoss":   result.DataLoss,
+	})
+}
@@ -20,6 +20,7 @@ type uploadRequest struct {
 	Selector string   `json:"selector"`
 	Files    []string `json:"files"`
 	Paths    []string `json:"paths"`
+	Owner    string   `json:"owner"`
 }
 
 // HandleUpload sets files on an <input type="file"> element via CDP.
@@ -42,6 +43,7 @@ func (h *Handlers) HandleUpload(w http.ResponseWriter, r *http.Request) {
 		web.Error(w, 400, fmt.Errorf("invalid JSON body: %w", err))
 		return
 	}
+	r = withCaller(r, req.Owner)
 
 	if req.Selector == "" {
 		req.Selector = "input[type=file]"
@@ -87,11 +89,19 @@ func (h *Handlers) HandleUpload(w http.ResponseWriter, r *http.Request) {
 
 	allPaths := append(tempFiles, req.Paths...)
 
-	ctx, _, err := h.Bridge.TabContext(tabID)
+	ctx, resolvedTabID, err := h.tabContext(r, tabID)
 	if err != nil {
 		web.Error(w, 404, err)
 		return
 	}
+	if !h.checkTabLock(w, r, resolvedTabID, req.Owner) {
+		return
+	}
+	release, ok := h.queueTab(w, r, resolvedTabID)
+	if !ok {
+		return
+	}
+	defer release()
 
 	tCtx, tCancel := context.WithTimeout(ctx, h.Config.ActionTimeout)
 	defer tCancel()
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Aggregator merges text-format expositions from several processes into one,
+// tagging each source's samples with a label. Families keep the order they
+// were first seen in, and each family's samples stay together as the format
+// requires.
+type Aggregator struct {
+	order    []string
+	families map[string]*aggFamily
+}
+
+type aggFamily struct {
+	help, typ string
+	samples   []string
+}
+
+func NewAggregator() *Aggregator {
+	return &Aggregator{families: make(map[string]*aggFamily)}
+}
+
+// Add reads one exposition. When label is non-empty every sample gets
+// label="value" prepended to its label set.
+func (a *Aggregator) Add(r io.Reader, label, value string) error {
+	var current *aggFamily
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			fields := strings.SplitN(line, " ", 4)
+			if len(fields) < 3 || (fields[1] != "HELP" && fields[1] != "TYPE") {
+				continue
+			}
+			current = a.family(fields[2])
+			rest := ""
+			if len(fields) == 4 {
+				rest = fields[3]
+			}
+			if fields[1] == "HELP" && current.help == "" {
+				current.help = rest
+			}
+			if fields[1] == "TYPE" && current.typ == "" {
+				current.typ = rest
+			}
+			continue
+		}
+		if current == nil {
+			// An untyped sample outside any family: file it under its own
+			// metric name.
+			current = a.family(sampleName(line))
+		}
+		current.samples = append(current.samples, addLabel(line, label, value))
+	}
+	return sc.Err()
+}
+
+func (a *Aggregator) family(name string) *aggFamily {
+	f, ok := a.families[name]
+	if !ok {
+		f = &aggFamily{}
+		a.families[name] = f
+		a.order = append(a.order, name)
+	}
+	return f
+}
+
+// WriteText renders the merged exposition.
+func (a *Aggregator) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, name := range a.order {
+		f := a.families[name]
+		if f.help != "" {
+			bw.WriteString("# HELP " + name + " " + f.help + "\n")
+		}
+		if f.typ != "" {
+			bw.WriteString("# TYPE " + name + " " + f.typ + "\n")
+		}
+		for _, s := range f.samples {
+			bw.WriteString(s + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+func sampleName(line string) string {
+	if i := strings.IndexAny(line, "{ "); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// addLabel inserts label="value" at the front of a sample's label set.
+func addLabel(line, label, value string) string {
+	if label == "" {
+		return line
+	}
+	pair := label + `="` + escapeLabel(value) + `"`
+	name := sampleName(line)
+	rest := line[len(name):]
+	if strings.HasPrefix(rest, "{}") {
+		return name + "{" + pair + "}" + rest[2:]
+	}
+	if strings.HasPrefix(rest, "{") {
+		return name + "{" + pair + "," + rest[1:]
+	}
+	return name + "{" + pair + "}" + rest
+}
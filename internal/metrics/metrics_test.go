@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestCounterAndGauge(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("test_requests_total", "Requests.", "path")
+	c.Inc("/a")
+	c.Add(2, "/a")
+	c.Inc(`/b"q`)
+	g := r.Gauge("test_tabs", "Open tabs.")
+	g.Set(3)
+
+	out := render(t, r)
+	for _, want := range []string{
+		"# TYPE test_requests_total counter\n",
+		`test_requests_total{path="/a"} 3` + "\n",
+		`test_requests_total{path="/b\"q"} 1` + "\n",
+		"# HELP test_tabs Open tabs.\n",
+		"test_tabs 3\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "test_requests_total") > strings.Index(out, "test_tabs") {
+		t.Error("families should be sorted by name")
+	}
+
+	g.Reset()
+	if strings.Contains(render(t, r), "test_tabs 3") {
+		t.Error("reset gauge should drop its series")
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.Histogram("test_seconds", "Latency.", []float64{1, 0.1}, "kind")
+	h.Observe(0.05, "x")
+	h.Observe(0.5, "x")
+	h.Observe(7, "x")
+
+	out := render(t, r)
+	for _, want := range []string{
+		`test_seconds_bucket{kind="x",le="0.1"} 1`,
+		`test_seconds_bucket{kind="x",le="1"} 2`,
+		`test_seconds_bucket{kind="x",le="+Inf"} 3`,
+		`test_seconds_sum{kind="x"} 7.55`,
+		`test_seconds_count{kind="x"} 3`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	child := "# HELP up_total Ups.\n# TYPE up_total counter\nup_total{path=\"/a\"} 2\nup_total 1\n"
+	local := "# HELP up_total Ups.\n# TYPE up_total counter\nup_total{path=\"/a\"} 5\n# TYPE other gauge\nother 1\n"
+
+	a := NewAggregator()
+	if err := a.Add(strings.NewReader(local), "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Add(strings.NewReader(child), "instance", "inst-1"); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := a.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "# HELP up_total Ups.\n# TYPE up_total counter\n" +
+		"up_total{path=\"/a\"} 5\n" +
+		"up_total{instance=\"inst-1\",path=\"/a\"} 2\n" +
+		"up_total{instance=\"inst-1\"} 1\n" +
+		"# TYPE other gauge\nother 1\n"
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
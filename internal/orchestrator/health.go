@@ -1,10 +1,13 @@
 package orchestrator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -36,7 +39,7 @@ func (o *Orchestrator) monitor(inst *InstanceInternal) {
 		time.Sleep(instanceHealthPollInterval)
 
 		for _, baseURL := range instanceBaseURLs(inst.Port) {
-			resp, err := o.client.Get(baseURL + "/health")
+			resp, err := o.client.Get(baseURL + "/health/ready")
 			if err == nil {
 				_ = resp.Body.Close()
 				lastProbe = fmt.Sprintf("%s -> HTTP %d", baseURL, resp.StatusCode)
@@ -116,8 +119,10 @@ func (o *Orchestrator) fetchTabs(baseURL string) ([]remoteTab, error) {
 	return tabs, nil
 }
 
+// isInstanceHealthyStatus reports whether a /health/ready answer means the
+// instance can take work. It answers 503 until Chrome is connected.
 func isInstanceHealthyStatus(code int) bool {
-	return code > 0 && code < http.StatusInternalServerError
+	return code == http.StatusOK
 }
 
 func instanceBaseURLs(port string) []string {
@@ -127,3 +132,54 @@ func instanceBaseURLs(port string) []string {
 		fmt.Sprintf("http://localhost:%s", port),
 	}
 }
+
+// InstanceMetrics scrapes /metrics from every running instance, keyed by
+// instance ID. Instances that don't answer are left out.
+func (o *Orchestrator) InstanceMetrics(ctx context.Context) map[string][]byte {
+	// monitor rewrites instance URLs under o.mu, so take copies.
+	o.mu.RLock()
+	urls := make(map[string]string, len(o.instances))
+	for _, inst := range o.instances {
+		if inst.Status == "running" && instanceIsActive(inst) {
+			urls[inst.ID] = inst.URL
+		}
+	}
+	o.mu.RUnlock()
+
+	var mu sync.Mutex
+	out := make(map[string][]byte, len(urls))
+	var wg sync.WaitGroup
+	for id, url := range urls {
+		wg.Go(func() {
+			body, err := o.fetchMetrics(ctx, url)
+			if err != nil {
+				slog.Debug("scrape instance metrics", "id", id, "err", err)
+				return
+			}
+			mu.Lock()
+			out[id] = body
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	return out
+}
+
+func (o *Orchestrator) fetchMetrics(ctx context.Context, baseURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/metrics", nil)
+	if err != nil {
+		return nil, err
+	}
+	if o.token != "" {
+		req.Header.Set("Authorization", "Bearer "+o.token)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+}
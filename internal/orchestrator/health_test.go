@@ -1,8 +1,12 @@
 package orchestrator
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
 )
 
 func TestIsInstanceHealthyStatus(t *testing.T) {
@@ -11,8 +15,9 @@ func TestIsInstanceHealthyStatus(t *testing.T) {
 		want bool
 	}{
 		{http.StatusOK, true},
-		{http.StatusNotFound, true},
-		{http.StatusBadRequest, true},
+		{http.StatusNotFound, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusServiceUnavailable, false},
 		{http.StatusInternalServerError, false},
 		{http.StatusBadGateway, false},
 		{0, false},
@@ -45,3 +50,34 @@ func TestInstanceBaseURLs(t *testing.T) {
 		}
 	}
 }
+
+func TestInstanceMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("pinchtab_tabs_open 2\n"))
+	}))
+	defer srv.Close()
+
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{portAvail: true})
+	o.SetAuthToken("secret")
+	o.instances["up"] = &InstanceInternal{
+		Instance: bridge.Instance{ID: "up", Status: "running"},
+		URL:      srv.URL,
+	}
+	o.instances["down"] = &InstanceInternal{
+		Instance: bridge.Instance{ID: "down", Status: "running"},
+		URL:      "http://127.0.0.1:1",
+	}
+	o.instances["stopped"] = &InstanceInternal{
+		Instance: bridge.Instance{ID: "stopped", Status: "stopped"},
+		URL:      srv.URL,
+	}
+
+	got := o.InstanceMetrics(context.Background())
+	if len(got) != 1 || string(got["up"]) != "pinchtab_tabs_open 2\n" {
+		t.Errorf("expected only the running instance's metrics, got %q", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -24,6 +25,12 @@ type Orchestrator struct {
 	runner    HostRunner
 	mu        sync.RWMutex
 	client    *http.Client
+
+	// shutdownTimeout is how long an instance drains requests on /shutdown.
+	shutdownTimeout time.Duration
+	// token authenticates calls to instances, which share the config and
+	// therefore the token of the process that launched them.
+	token string
 }
 
 type InstanceInternal struct {
@@ -72,6 +79,8 @@ func NewOrchestratorWithRunner(baseDir string, runner HostRunner) *Orchestrator
 		binary:    binary,
 		runner:    runner,
 		client:    &http.Client{Timeout: 3 * time.Second},
+
+		shutdownTimeout: 10 * time.Second,
 	}
 }
 
@@ -79,6 +88,20 @@ func (o *Orchestrator) SetProfileManager(pm *profiles.ProfileManager) {
 	o.profiles = pm
 }
 
+// SetAuthToken sets the bearer token sent when scraping instances.
+func (o *Orchestrator) SetAuthToken(token string) {
+	o.token = token
+}
+
+// SetShutdownTimeout sets how long launched instances drain in-flight
+// requests before tearing down Chrome. Stop waits that long, plus time for
+// the state save, before signalling the process.
+func (o *Orchestrator) SetShutdownTimeout(d time.Duration) {
+	if d > 0 {
+		o.shutdownTimeout = d
+	}
+}
+
 func installStableBinary(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
@@ -138,6 +161,8 @@ func (o *Orchestrator) Launch(name, port string, headless bool) (*bridge.Instanc
 		"BRIDGE_HEADLESS":     headlessStr,
 		"BRIDGE_NO_RESTORE":   "true",
 		"BRIDGE_NO_DASHBOARD": "true",
+
+		"BRIDGE_SHUTDOWN_TIMEOUT": strconv.Itoa(int(o.shutdownTimeout.Seconds())),
 	})
 
 	logBuf := newRingBuffer(64 * 1024)
@@ -200,7 +225,7 @@ func (o *Orchestrator) Stop(id string) error {
 	}
 
 	if pid > 0 {
-		if waitForProcessExit(pid, 5*time.Second) {
+		if waitForProcessExit(pid, o.shutdownTimeout+5*time.Second) {
 			o.markStopped(id)
 			return nil
 		}
@@ -3,7 +3,9 @@ package orchestrator
 import (
 	"context"
 	"io"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
 )
@@ -11,6 +13,7 @@ import (
 type mockRunner struct {
 	runCalled bool
 	portAvail bool
+	env       []string
 }
 
 type mockCmd struct {
@@ -24,6 +27,7 @@ func (m *mockCmd) Cancel()     {}
 
 func (m *mockRunner) Run(ctx context.Context, binary string, env []string, stdout, stderr io.Writer) (Cmd, error) {
 	m.runCalled = true
+	m.env = env
 	return &mockCmd{pid: 1234, isAlive: true}, nil
 }
 
@@ -48,6 +52,19 @@ func TestLaunch_Mocked(t *testing.T) {
 	}
 }
 
+func TestLaunch_ShutdownTimeoutEnv(t *testing.T) {
+	runner := &mockRunner{portAvail: true}
+	o := NewOrchestratorWithRunner(t.TempDir(), runner)
+	o.SetShutdownTimeout(25 * time.Second)
+
+	if _, err := o.Launch("test-prof", "9999", true); err != nil {
+		t.Fatalf("Launch failed: %v", err)
+	}
+	if !slices.Contains(runner.env, "BRIDGE_SHUTDOWN_TIMEOUT=25") {
+		t.Error("expected BRIDGE_SHUTDOWN_TIMEOUT=25 in instance env")
+	}
+}
+
 func TestLaunch_PortConflict(t *testing.T) {
 	runner := &mockRunner{portAvail: false}
 	o := NewOrchestratorWithRunner(t.TempDir(), runner)